@@ -32,6 +32,10 @@ const (
 	SYNCHRONIZER = "synchronizer"
 	// BROADCAST is the broadcast component identifier.
 	BROADCAST = "broadcast-trusted-state"
+	// INDEXER is the token indexer component identifier, it indexes the token
+	// events of the l2 blocks stored by the sequencer or the synchronizer of
+	// the same process and backfills the ones stored before it was enabled.
+	INDEXER = "token-indexer"
 )
 
 func main() {
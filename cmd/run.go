@@ -7,7 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
-	"sort"
+	"time"
 
 	"github.com/0xPolygonHermez/zkevm-node/aggregator"
 	"github.com/0xPolygonHermez/zkevm-node/config"
@@ -18,7 +18,10 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/jsonrpc"
 	"github.com/0xPolygonHermez/zkevm-node/log"
 	"github.com/0xPolygonHermez/zkevm-node/merkletree"
+	merkletreepb "github.com/0xPolygonHermez/zkevm-node/merkletree/pb"
+	"github.com/0xPolygonHermez/zkevm-node/metrics"
 	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/pool/acl"
 	"github.com/0xPolygonHermez/zkevm-node/pool/pgpoolstorage"
 	"github.com/0xPolygonHermez/zkevm-node/pricegetter"
 	"github.com/0xPolygonHermez/zkevm-node/proverclient"
@@ -37,10 +40,14 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-// slice contains method
+// slice contains method, the slice doesn't need to be sorted
 func contains(s []string, searchTerm string) bool {
-	i := sort.SearchStrings(s, searchTerm)
-	return i < len(s) && s[i] == searchTerm
+	for _, item := range s {
+		if item == searchTerm {
+			return true
+		}
+	}
+	return false
 }
 
 func start(cliCtx *cli.Context) error {
@@ -57,7 +64,14 @@ func start(cliCtx *cli.Context) error {
 	}
 	ctx := context.Background()
 
-	st := newState(ctx, c, sqlDB)
+	// the token events are indexed as the l2 blocks are stored, so the indexer
+	// has to run along with a component storing them
+	components := cliCtx.StringSlice(config.FlagComponents)
+	if contains(components, INDEXER) && !contains(components, SEQUENCER) && !contains(components, SYNCHRONIZER) {
+		log.Fatalf("the %s component must run along with the %s or the %s component", INDEXER, SEQUENCER, SYNCHRONIZER)
+	}
+
+	st := newState(ctx, c, sqlDB, contains(components, INDEXER))
 
 	poolDb, err := pgpoolstorage.NewPostgresPoolStorage(c.Database)
 	if err != nil {
@@ -70,9 +84,9 @@ func start(cliCtx *cli.Context) error {
 		etherman        *etherman.Client
 	)
 
-	if contains(cliCtx.StringSlice(config.FlagComponents), AGGREGATOR) ||
-		contains(cliCtx.StringSlice(config.FlagComponents), SEQUENCER) ||
-		contains(cliCtx.StringSlice(config.FlagComponents), SYNCHRONIZER) {
+	if contains(components, AGGREGATOR) ||
+		contains(components, SEQUENCER) ||
+		contains(components, SYNCHRONIZER) {
 		var err error
 		etherman, err = newEtherman(*c)
 		if err != nil {
@@ -80,12 +94,20 @@ func start(cliCtx *cli.Context) error {
 		}
 	}
 
-	npool := pool.NewPool(poolDb, st, c.NetworkConfig.L2GlobalExitRootManagerAddr)
-	gpe := createGasPriceEstimator(c.GasPriceEstimator, st, npool)
+	poolACL, err := acl.New(c.ACL, poolDb)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go poolACL.Start(ctx)
+
+	// the gas price of the pool is only a minimum when derived from l1
+	checkMinGasPrice := c.GasPriceEstimator.Type == gasprice.L1GasPriceType
+	npool := pool.NewPool(poolDb, st, c.NetworkConfig.L2BridgeAddr, c.Sequencer.MaxZkCounters(), poolACL, checkMinGasPrice)
+	gpe := createGasPriceEstimator(ctx, c.GasPriceEstimator, st, npool, etherman, uint64(c.Sequencer.MaxGasUsed))
 	ch := make(chan struct{})
 	ethTxManager := ethtxmanager.New(c.EthTxManager, etherman)
 	proverClient, proverConn := newProverClient(c.Prover)
-	for _, item := range cliCtx.StringSlice(config.FlagComponents) {
+	for _, item := range components {
 		switch item {
 		case AGGREGATOR:
 			log.Info("Running aggregator")
@@ -94,6 +116,11 @@ func start(cliCtx *cli.Context) error {
 			log.Info("Running sequencer")
 			seq := createSequencer(*c, npool, st, etherman, ethTxManager, ch)
 			go seq.Start(ctx)
+			// the gas price is updated by a single component, so it is not
+			// written by every node connected to l1
+			if l1gpe, ok := gpe.(*gasprice.L1GasPrice); ok {
+				go l1gpe.Start(ctx)
+			}
 		case RPC:
 			log.Info("Running JSON-RPC server")
 			apis := map[string]bool{}
@@ -107,9 +134,16 @@ func start(cliCtx *cli.Context) error {
 		case BROADCAST:
 			log.Info("Running broadcast service")
 			go runBroadcastServer(c.BroadcastServer, st)
+		case INDEXER:
+			log.Info("Running token indexer")
+			go runTokenIndexer(ctx, st)
 		}
 	}
 
+	if c.Metrics.Enabled {
+		go startMetricsServer(c.Metrics)
+	}
+
 	grpcClientConns = append(grpcClientConns, proverConn)
 
 	waitSignal(grpcClientConns, cancelFuncs)
@@ -162,7 +196,9 @@ func runJSONRPCServer(c config.Config, pool *pool.Pool, st *state.State, gpe gas
 		log.Fatal(err)
 	}
 
-	if err := jsonrpc.NewServer(c.RPC, pool, st, gpe, storage, apis).Start(); err != nil {
+	feeHistory := gasprice.NewFeeHistory(c.GasPriceEstimator, st, pool, gpe)
+
+	if err := jsonrpc.NewServer(c.RPC, pool, st, gpe, feeHistory, storage, apis).Start(); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -213,6 +249,27 @@ func runBroadcastServer(c broadcast.ServerConfig, st *state.State) {
 	broadcastSrv.Start()
 }
 
+// runTokenIndexer backfills the token events of the l2 blocks stored while the
+// indexer was not running, the new ones are indexed as the blocks are stored.
+func runTokenIndexer(ctx context.Context, st *state.State) {
+	const retryInterval = 5 * time.Second
+	for {
+		err := st.BackfillTokenTransfers(ctx)
+		if err == nil {
+			log.Info("token events backfilled")
+			return
+		}
+		log.Errorf("failed to backfill the token events, retrying in %v, err: %v", retryInterval, err)
+		time.Sleep(retryInterval)
+	}
+}
+
+func startMetricsServer(c metrics.Config) {
+	if err := metrics.StartServer(c); err != nil {
+		log.Fatal(err)
+	}
+}
+
 // gasPriceEstimator interface for gas price estimator.
 type gasPriceEstimator interface {
 	GetAvgGasPrice(ctx context.Context) (*big.Int, error)
@@ -220,10 +277,19 @@ type gasPriceEstimator interface {
 }
 
 // createGasPriceEstimator init gas price gasPriceEstimator based on type in config.
-func createGasPriceEstimator(cfg gasprice.Config, state *state.State, pool *pool.Pool) gasPriceEstimator {
+func createGasPriceEstimator(ctx context.Context, cfg gasprice.Config, state *state.State, pool *pool.Pool,
+	etherman *etherman.Client, maxGasPerBatch uint64) gasPriceEstimator {
 	switch cfg.Type {
+	case gasprice.L1GasPriceType:
+		// the gas price is updated from l1 by the sequencer, the rest of
+		// components read it from the pool
+		return gasprice.NewL1GasPriceEstimator(cfg, etherman, pool, maxGasPerBatch)
 	case gasprice.AllBatchesType:
-		return gasprice.NewEstimatorAllBatches()
+		gpe := gasprice.NewEstimatorAllBatches(cfg, state)
+		go gpe.Start(ctx)
+		return gpe
+	case gasprice.PercentileType:
+		return gasprice.NewEstimatorPercentile(cfg, state)
 	case gasprice.LastNBatchesType:
 		return gasprice.NewEstimatorLastNL2Blocks(cfg, state)
 	case gasprice.DefaultType:
@@ -284,14 +350,41 @@ func newAuthFromKeystore(path, password string, chainID uint64) (*bind.TransactO
 	return auth, nil
 }
 
-func newState(ctx context.Context, c *config.Config, sqlDB *pgxpool.Pool) *state.State {
+func newStateDBClient(ctx context.Context, c merkletree.Config, executorCfg executor.Config, sqlDB *pgxpool.Pool) merkletreepb.StateDBServiceClient {
+	// the executor reads and writes the state in the statedb it is connected
+	// to, so the in-process merkletree would never see the executed batches
+	if c.Type != merkletree.RemoteType && executorCfg.URI != "" {
+		log.Fatalf("the %q merkletree type can't be used along with an executor, use the %q type with the statedb of the executor", c.Type, merkletree.RemoteType)
+	}
+
+	switch c.Type {
+	case merkletree.PostgresType:
+		log.Info("using in-process merkletree stored in postgres")
+		return merkletree.NewLocalStateDBClient(merkletree.NewPostgresStorage(sqlDB))
+	case merkletree.MemoryType:
+		log.Info("using in-process merkletree stored in memory")
+		return merkletree.NewLocalStateDBClient(merkletree.NewMemoryStorage())
+	case merkletree.RemoteType:
+		stateDBClient, _, _ := merkletree.NewMTDBServiceClient(ctx, c)
+		return stateDBClient
+	default:
+		log.Fatalf("invalid merkletree type %q", c.Type)
+		return nil
+	}
+}
+
+func newState(ctx context.Context, c *config.Config, sqlDB *pgxpool.Pool, indexTokenTransfers bool) *state.State {
 	stateDb := state.NewPostgresStorage(sqlDB)
+	stateDBClient := newStateDBClient(ctx, c.MTClient, c.Executor, sqlDB)
 	executorClient, _, _ := executor.NewExecutorClient(ctx, c.Executor)
-	stateDBClient, _, _ := merkletree.NewMTDBServiceClient(ctx, c.MTClient)
-	stateTree := merkletree.NewStateTree(stateDBClient)
+	stateTree, err := merkletree.NewStateTreeWithCache(stateDBClient, c.MTClient.Cache)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	stateCfg := state.Config{
 		MaxCumulativeGasUsed: c.NetworkConfig.MaxCumulativeGasUsed,
+		IndexTokenTransfers:  indexTokenTransfers,
 	}
 
 	st := state.NewState(stateCfg, stateDb, executorClient, stateTree)
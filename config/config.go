@@ -14,6 +14,8 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/jsonrpc"
 	"github.com/0xPolygonHermez/zkevm-node/log"
 	"github.com/0xPolygonHermez/zkevm-node/merkletree"
+	"github.com/0xPolygonHermez/zkevm-node/metrics"
+	"github.com/0xPolygonHermez/zkevm-node/pool/acl"
 	"github.com/0xPolygonHermez/zkevm-node/pricegetter"
 	"github.com/0xPolygonHermez/zkevm-node/proverclient"
 	"github.com/0xPolygonHermez/zkevm-node/sequencer"
@@ -64,6 +66,8 @@ type Config struct {
 	BroadcastServer   broadcast.ServerConfig
 	BroadcastClient   broadcast.ClientConfig
 	MTClient          merkletree.Config
+	Metrics           metrics.Config
+	ACL               acl.Config
 }
 
 // Load loads the configuration
@@ -11,6 +11,7 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/config"
 	"github.com/0xPolygonHermez/zkevm-node/config/types"
 	"github.com/0xPolygonHermez/zkevm-node/pricegetter"
+	"github.com/0xPolygonHermez/zkevm-node/sequencer"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
 )
@@ -24,6 +25,14 @@ func Test_Defaults(t *testing.T) {
 			path:          "Synchronizer.SyncChunkSize",
 			expectedValue: uint64(100),
 		},
+		{
+			path:          "Synchronizer.L1SyncMode",
+			expectedValue: "latest",
+		},
+		{
+			path:          "Synchronizer.L1BlockConfirmations",
+			expectedValue: uint64(0),
+		},
 		{
 			path:          "PriceGetter.Type",
 			expectedValue: pricegetter.DefaultType,
@@ -56,41 +65,65 @@ func Test_Defaults(t *testing.T) {
 			path:          "Sequencer.FrequencyToCheckTxsForDelete",
 			expectedValue: types.NewDuration(12 * time.Hour),
 		},
+		{
+			path:          "Sequencer.MaxForcedBatchesPerSequence",
+			expectedValue: uint64(10),
+		},
+		{
+			path:          "Sequencer.ForcedBatchesTimeoutMargin",
+			expectedValue: types.NewDuration(1 * time.Hour),
+		},
 		{
 			path:          "Sequencer.ProfitabilityChecker.SendBatchesEvenWhenNotProfitable",
 			expectedValue: true,
 		},
+		{
+			path:          "Sequencer.TxSelector.Strategy",
+			expectedValue: sequencer.GreedyStrategy,
+		},
+		{
+			path:          "Sequencer.TxSelector.MaxCandidates",
+			expectedValue: uint64(1000),
+		},
+		{
+			path:          "Sequencer.ClaimsLane.MaxClaimsPerBatch",
+			expectedValue: uint64(10),
+		},
+		{
+			path:          "Sequencer.ClaimsLane.MaxCandidates",
+			expectedValue: uint64(100),
+		},
 		{
 			path:          "Sequencer.MaxGasUsed",
-			expectedValue: int64(100000),
+			expectedValue: int64(30000000),
 		},
 		{
 			path:          "Sequencer.MaxKeccakHashes",
-			expectedValue: int32(100),
+			expectedValue: int32(468),
 		},
 		{
 			path:          "Sequencer.MaxPoseidonHashes",
-			expectedValue: int32(100),
+			expectedValue: int32(279620),
 		},
 		{
 			path:          "Sequencer.MaxPoseidonPaddings",
-			expectedValue: int32(100),
+			expectedValue: int32(149796),
 		},
 		{
 			path:          "Sequencer.MaxMemAligns",
-			expectedValue: int32(100),
+			expectedValue: int32(262144),
 		},
 		{
 			path:          "Sequencer.MaxArithmetics",
-			expectedValue: int32(100),
+			expectedValue: int32(262144),
 		},
 		{
 			path:          "Sequencer.MaxBinaries",
-			expectedValue: int32(100),
+			expectedValue: int32(524288),
 		},
 		{
 			path:          "Sequencer.MaxSteps",
-			expectedValue: int32(100),
+			expectedValue: int32(8388608),
 		},
 		{
 			path:          "EthTxManager.MaxSendBatchTxRetries",
@@ -112,10 +145,50 @@ func Test_Defaults(t *testing.T) {
 			path:          "GasPriceEstimator.DefaultGasPriceWei",
 			expectedValue: uint64(1000000000),
 		},
+		{
+			path:          "GasPriceEstimator.UpdatePeriod",
+			expectedValue: types.NewDuration(10 * time.Second),
+		},
+		{
+			path:          "GasPriceEstimator.L1GasPriceFactor",
+			expectedValue: 0.25,
+		},
+		{
+			path:          "GasPriceEstimator.DataAvailabilityGasPerBatch",
+			expectedValue: uint64(200000),
+		},
+		{
+			path:          "GasPriceEstimator.TimeWindow",
+			expectedValue: types.NewDuration(time.Hour),
+		},
+		{
+			path:          "GasPriceEstimator.CheckBlocks",
+			expectedValue: 20,
+		},
+		{
+			path:          "GasPriceEstimator.Percentile",
+			expectedValue: 60,
+		},
+		{
+			path:          "MTClient.Type",
+			expectedValue: "remote",
+		},
 		{
 			path:          "MTClient.URI",
 			expectedValue: "127.0.0.1:50061",
 		},
+		{
+			path:          "MTClient.Cache.Size",
+			expectedValue: 10000,
+		},
+		{
+			path:          "MTClient.Cache.ProgramSize",
+			expectedValue: 1000,
+		},
+		{
+			path:          "MTClient.Cache.Bypass",
+			expectedValue: false,
+		},
 		{
 			path:          "Database.MaxConns",
 			expectedValue: 200,
@@ -136,6 +209,10 @@ func Test_Defaults(t *testing.T) {
 			path:          "RPC.MaxRequestsPerIPAndSecond",
 			expectedValue: float64(50),
 		},
+		{
+			path:          "RPC.GasCap",
+			expectedValue: uint64(30000000),
+		},
 		{
 			path:          "Executor.URI",
 			expectedValue: "127.0.0.1:50071",
@@ -152,6 +229,18 @@ func Test_Defaults(t *testing.T) {
 			path:          "BroadcastClient.URI",
 			expectedValue: "127.0.0.1:61090",
 		},
+		{
+			path:          "ACL.Enabled",
+			expectedValue: false,
+		},
+		{
+			path:          "ACL.FilePath",
+			expectedValue: "",
+		},
+		{
+			path:          "ACL.ReloadInterval",
+			expectedValue: types.NewDuration(1 * time.Minute),
+		},
 	}
 
 	ctx := cli.NewContext(cli.NewApp(), flag.NewFlagSet("", flag.PanicOnError), nil)
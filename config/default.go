@@ -29,10 +29,13 @@ Host = "0.0.0.0"
 Port = 8123
 MaxRequestsPerIPAndSecond = 50
 SequencerNodeURI = ""
+GasCap = 30000000
 
 [Synchronizer]
 SyncInterval = "0s"
 SyncChunkSize = 100
+L1SyncMode = "latest"
+L1BlockConfirmations = 0
 
 [Sequencer]
 WaitPeriodPoolIsEmpty = "15s"
@@ -41,16 +44,30 @@ WaitBlocksToUpdateGER = 10
 LastTimeBatchMaxWaitPeriod = "15s"
 BlocksAmountForTxsToBeDeleted = 100
 FrequencyToCheckTxsForDelete = "12h"
-MaxGasUsed = 100000
-MaxKeccakHashes = 100
-MaxPoseidonHashes = 100
-MaxPoseidonPaddings = 100
-MaxMemAligns = 100
-MaxArithmetics = 100
-MaxBinaries = 100
-MaxSteps = 100
+MaxForcedBatchesPerSequence = 10
+ForcedBatchesTimeoutMargin = "1h"
+# The zk counters a batch can use are bounded by the 2^23 rows of the prover
+# polynomials: MaxSteps = 2^23, MaxBinaries = 2^19 and MaxMemAligns and
+# MaxArithmetics = 2^18. MaxPoseidonHashes = 2^23 / 30 and
+# MaxPoseidonPaddings = 2^23 / 56, the rows used per hash and per padding, and
+# MaxKeccakHashes = (2^23 / 158418) * 9, the slots of 158418 rows with 9
+# hashes each. MaxGasUsed is the gas limit of a batch
+MaxGasUsed = 30000000
+MaxKeccakHashes = 468
+MaxPoseidonHashes = 279620
+MaxPoseidonPaddings = 149796
+MaxMemAligns = 262144
+MaxArithmetics = 262144
+MaxBinaries = 524288
+MaxSteps = 8388608
 	[Sequencer.ProfitabilityChecker]
 		SendBatchesEvenWhenNotProfitable = "true"
+	[Sequencer.TxSelector]
+		Strategy = "greedy"
+		MaxCandidates = 1000
+	[Sequencer.ClaimsLane]
+		MaxClaimsPerBatch = 10
+		MaxCandidates = 100
 
 [PriceGetter]
 Type = "default"
@@ -65,6 +82,12 @@ TxProfitabilityMinReward = "1.1"
 [GasPriceEstimator]
 Type = "default"
 DefaultGasPriceWei = 1000000000
+UpdatePeriod = "10s"
+L1GasPriceFactor = 0.25
+DataAvailabilityGasPerBatch = 200000
+TimeWindow = "1h"
+CheckBlocks = 20
+Percentile = 60
 
 [Prover]
 ProverURI = "0.0.0.0:50051"
@@ -75,8 +98,14 @@ Port = 50060
 StoreBackend = "PostgreSQL"
 
 [MTClient]
+Type = "remote"
 URI = "127.0.0.1:50061"
 
+[MTClient.Cache]
+Size = 10000
+ProgramSize = 1000
+Bypass = false
+
 [Executor]
 URI = "127.0.0.1:50071"
 
@@ -86,4 +115,14 @@ Port = 61090
 
 [BroadcastClient]
 URI = "127.0.0.1:61090"
+
+[Metrics]
+Host = "0.0.0.0"
+Port = 9091
+Enabled = false
+
+[ACL]
+Enabled = false
+FilePath = ""
+ReloadInterval = "1m"
 `
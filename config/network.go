@@ -22,6 +22,7 @@ type NetworkConfig struct {
 	PoEAddr                       common.Address
 	MaticAddr                     common.Address
 	L2GlobalExitRootManagerAddr   common.Address
+	L2BridgeAddr                  common.Address
 	GlobalExitRootManagerAddr     common.Address
 	SystemSCAddr                  common.Address
 	GlobalExitRootStoragePosition uint64
@@ -120,6 +121,7 @@ var (
 		PoEAddr:                       common.HexToAddress("0x083E10Fc0De5a919Dec514CCD9130cD772D38Bfb"),
 		MaticAddr:                     common.HexToAddress("0x7431FD5ba483f826cAf06B68ae95b2aE738D666D"),
 		L2GlobalExitRootManagerAddr:   common.HexToAddress("0xae4bb80be56b819606589de61d5ec3b522eeb032"),
+		L2BridgeAddr:                  common.HexToAddress("0x9d98deabc42dd696deb9e40b4f1cab7ddbf55988"),
 		GlobalExitRootManagerAddr:     common.HexToAddress("0xae4bb80be56b819606589de61d5ec3b522eeb032"),
 		SystemSCAddr:                  common.HexToAddress("0x0000000000000000000000000000000000000000"),
 		GlobalExitRootStoragePosition: 0,
@@ -189,6 +191,7 @@ var (
 		PoEAddr:                       common.HexToAddress("0xDc64a140Aa3E981100a9becA4E685f962f0cF6C9"),
 		MaticAddr:                     common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3"),
 		L2GlobalExitRootManagerAddr:   common.HexToAddress("0xae4bb80be56b819606589de61d5ec3b522eeb032"),
+		L2BridgeAddr:                  common.HexToAddress("0x9d98deabc42dd696deb9e40b4f1cab7ddbf55988"),
 		GlobalExitRootManagerAddr:     common.HexToAddress("0x9fE46736679d2D9a65F0992F2272dE9f3c7fa6e0"),
 		SystemSCAddr:                  common.HexToAddress("0x0000000000000000000000000000000000000000"),
 		GlobalExitRootStoragePosition: 0,
@@ -356,13 +359,19 @@ func loadCustomNetworkConfig(ctx *cli.Context) (NetworkConfig, error) {
 		Nonces:         make(map[common.Address]*big.Int, len(cfgJSON.Genesis)),
 	}
 
-	const l2GlobalExitRootManagerSCName = "GlobalExitRootManagerL2"
+	const (
+		l2GlobalExitRootManagerSCName = "GlobalExitRootManagerL2"
+		l2BridgeSCName                = "Bridge"
+	)
 
 	for _, account := range cfgJSON.Genesis {
 		addr := common.HexToAddress(account.Address)
 		if account.ContractName == l2GlobalExitRootManagerSCName {
 			cfg.L2GlobalExitRootManagerAddr = common.HexToAddress(account.Address)
 		}
+		if account.ContractName == l2BridgeSCName {
+			cfg.L2BridgeAddr = common.HexToAddress(account.Address)
+		}
 
 		if account.Balance != "" && account.Balance != "0" {
 			balance, ok := big.NewInt(0).SetString(account.Balance, encoding.Base10)
@@ -79,6 +79,7 @@ func TestLoadCustomNetworkConfig(t *testing.T) {
 				MaticAddr:      common.HexToAddress("0xEa2f9aC0cd926C92923355e88Af73Ee83F2D9C67"),
 
 				L2GlobalExitRootManagerAddr:   common.HexToAddress("0xae4bb80be56b819606589de61d5ec3b522eeb032"),
+				L2BridgeAddr:                  common.HexToAddress("0x9d98deabc42dd696deb9e40b4f1cab7ddbf55988"),
 				SystemSCAddr:                  common.Address{},
 				GlobalExitRootStoragePosition: 0,
 				LocalExitRootStoragePosition:  1,
@@ -201,6 +202,7 @@ func TestLoadCustomNetworkConfig(t *testing.T) {
 			require.Equal(t, tc.expectedConfig.PoEAddr, actualConfig.PoEAddr)
 			require.Equal(t, tc.expectedConfig.MaticAddr, actualConfig.MaticAddr)
 			require.Equal(t, tc.expectedConfig.L2GlobalExitRootManagerAddr, actualConfig.L2GlobalExitRootManagerAddr)
+			require.Equal(t, tc.expectedConfig.L2BridgeAddr, actualConfig.L2BridgeAddr)
 			require.Equal(t, tc.expectedConfig.SystemSCAddr, actualConfig.SystemSCAddr)
 			require.Equal(t, tc.expectedConfig.GlobalExitRootStoragePosition, actualConfig.GlobalExitRootStoragePosition)
 			require.Equal(t, tc.expectedConfig.LocalExitRootStoragePosition, actualConfig.LocalExitRootStoragePosition)
@@ -58,14 +58,20 @@ CREATE TABLE state.l2block (
     batch_num BIGINT NOT NULL REFERENCES state.batch (batch_num) ON DELETE CASCADE
 );
 
+CREATE INDEX idx_l2block_received_at ON state.l2block (received_at);
+
 CREATE TABLE state.transaction (
     hash VARCHAR PRIMARY KEY,
     from_address VARCHAR,
+    to_address VARCHAR, -- It is null for contract creations
     encoded VARCHAR NOT NULL,
     decoded jsonb,
     l2_block_num BIGINT NOT NULL REFERENCES state.l2block (block_num) ON DELETE CASCADE
 );
 
+CREATE INDEX idx_transaction_from_address_l2_block_num ON state.transaction (from_address, l2_block_num);
+CREATE INDEX idx_transaction_to_address_l2_block_num ON state.transaction (to_address, l2_block_num);
+
 CREATE TABLE state.exit_root
 (
     block_num               BIGINT NOT NULL REFERENCES state.block (block_num) ON DELETE CASCADE,
@@ -95,21 +101,82 @@ CREATE TABLE state.receipt
     gas_used BIGINT,
     block_num BIGINT NOT NULL REFERENCES state.l2block (block_num) ON DELETE CASCADE,
     tx_index integer,
-    contract_address VARCHAR
+    contract_address VARCHAR,
+    bloom BYTEA,
+    effective_gas_price DECIMAL(78, 0)
 );
 
+CREATE INDEX idx_receipt_contract_address ON state.receipt (contract_address);
+CREATE INDEX idx_receipt_block_num ON state.receipt (block_num);
+
 CREATE TABLE state.log
 (
-    tx_hash VARCHAR NOT NULL PRIMARY KEY REFERENCES state.transaction (hash) ON DELETE CASCADE,
-    log_index integer,
-    address VARCHAR NOT NULL,
-    data VARCHAR,
-    topic0 VARCHAR NOT NULL,
-    topic1 VARCHAR,
-    topic2 VARCHAR,
-    topic3 VARCHAR
+    tx_hash VARCHAR NOT NULL REFERENCES state.transaction (hash) ON DELETE CASCADE,
+    log_index integer NOT NULL,
+    block_num BIGINT NOT NULL REFERENCES state.l2block (block_num) ON DELETE CASCADE,
+    block_hash VARCHAR NOT NULL,
+    tx_index integer NOT NULL,
+    address BYTEA NOT NULL,
+    data BYTEA,
+    topic0 BYTEA,
+    topic1 BYTEA,
+    topic2 BYTEA,
+    topic3 BYTEA,
+    PRIMARY KEY (tx_hash, log_index)
 );
 
+CREATE INDEX idx_log_block_num ON state.log (block_num);
+CREATE INDEX idx_log_block_hash ON state.log (block_hash);
+CREATE INDEX idx_log_address_block_num ON state.log (address, block_num);
+CREATE INDEX idx_log_topic0_block_num ON state.log (topic0, block_num);
+CREATE INDEX idx_log_topic1_block_num ON state.log (topic1, block_num);
+CREATE INDEX idx_log_topic2_block_num ON state.log (topic2, block_num);
+CREATE INDEX idx_log_topic3_block_num ON state.log (topic3, block_num);
+
+CREATE TABLE state.token_transfer
+(
+    tx_hash VARCHAR NOT NULL,
+    log_index INTEGER NOT NULL,
+    block_num BIGINT NOT NULL REFERENCES state.l2block (block_num) ON DELETE CASCADE,
+    token_address VARCHAR NOT NULL,
+    token_type VARCHAR(6) NOT NULL,
+    event_type VARCHAR(8) NOT NULL,
+    from_address VARCHAR NOT NULL,
+    to_address VARCHAR NOT NULL,
+    value DECIMAL(78, 0) NOT NULL, -- amount for erc20, token id for erc721
+    PRIMARY KEY (tx_hash, log_index)
+);
+
+CREATE INDEX idx_token_transfer_from_address_block_num ON state.token_transfer (from_address, block_num);
+CREATE INDEX idx_token_transfer_to_address_block_num ON state.token_transfer (to_address, block_num);
+CREATE INDEX idx_token_transfer_token_address_value ON state.token_transfer (token_address, value);
+
+CREATE TABLE state.gas_price_avg
+(
+    id INTEGER PRIMARY KEY DEFAULT 1 CHECK (id = 1), -- there is a single row
+    last_block_num BIGINT NOT NULL,
+    avg DECIMAL(78, 0) NOT NULL,
+    count BIGINT NOT NULL
+);
+
+-- Insert default values into gas_price_avg table
+INSERT INTO state.gas_price_avg (last_block_num, avg, count) VALUES (0, 0, 0);
+
+CREATE TABLE state.trusted_reorg
+(
+    id SERIAL PRIMARY KEY,
+    batch_num BIGINT NOT NULL,
+    block_num BIGINT NOT NULL,
+    divergent_fields VARCHAR[],
+    old_state_root VARCHAR,
+    new_state_root VARCHAR,
+    l2_block_nums BIGINT[],
+    tx_hashes VARCHAR[],
+    reported_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_trusted_reorg_batch_num ON state.trusted_reorg (batch_num);
+
 CREATE TABLE state.merkletree (
     hash BYTEA PRIMARY KEY,
     data BYTEA NOT NULL
@@ -122,6 +189,7 @@ CREATE TABLE pool.txs
     hash                   VARCHAR PRIMARY KEY,
     encoded                VARCHAR,
     decoded                jsonb,
+    from_address           VARCHAR NOT NULL,
     state                  varchar(15),
     gas_price              DECIMAL(78, 0),
     nonce                  DECIMAL(78, 0),
@@ -134,10 +202,12 @@ CREATE TABLE pool.txs
     used_arithmetics       INTEGER,
     used_binaries          INTEGER,
     used_steps             INTEGER,
+    is_pre_executed        BOOLEAN NOT NULL DEFAULT TRUE, -- false while the zk counters are unknown as the nonce of the tx is ahead of the state
     received_at            TIMESTAMP WITH TIME ZONE NOT NULL
 );
 
 CREATE INDEX idx_state_gas_price_nonce ON pool.txs (state, gas_price, nonce);
+CREATE INDEX idx_from_address_state_nonce ON pool.txs (from_address, state, nonce);
 
 CREATE TABLE pool.gas_price
 (
@@ -146,6 +216,13 @@ CREATE TABLE pool.gas_price
     timestamp TIMESTAMP WITH TIME ZONE NOT NULL
 );
 
+CREATE TABLE pool.acl
+(
+    address VARCHAR     NOT NULL,
+    list    VARCHAR(20) NOT NULL, -- allowed_sender, denied_sender, allowed_recipient, denied_recipient, allowed_deployer or denied_deployer
+    PRIMARY KEY (address, list)
+);
+
 CREATE SCHEMA rpc
 
 CREATE TABLE rpc.filters
@@ -23,6 +23,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -35,6 +36,8 @@ var (
 
 	// ErrNotFound is used when the object is not found
 	ErrNotFound = errors.New("Not found")
+	// ErrBlockTagNotSupported is used when the L1 client can't resolve block tags
+	ErrBlockTagNotSupported = errors.New("block tags are not supported by this client")
 )
 
 // EventOrder is the the type used to identify the events order
@@ -57,6 +60,7 @@ type ethClienter interface {
 	ethereum.ChainReader
 	ethereum.LogFilterer
 	ethereum.TransactionReader
+	ethereum.GasPricer
 }
 
 // Client is a simple implementation of EtherMan.
@@ -67,17 +71,19 @@ type Client struct {
 	Matic                 *matic.Matic
 	SCAddresses           []common.Address
 
-	auth *bind.TransactOpts
+	rpcClient *rpc.Client
+	auth      *bind.TransactOpts
 }
 
 // NewClient creates a new etherman.
 func NewClient(cfg Config, auth *bind.TransactOpts, PoEAddr common.Address, maticAddr common.Address, globalExitRootManAddr common.Address) (*Client, error) {
 	// Connect to ethereum node
-	ethClient, err := ethclient.Dial(cfg.URL)
+	rpcClient, err := rpc.Dial(cfg.URL)
 	if err != nil {
 		log.Errorf("error connecting to %s: %+v", cfg.URL, err)
 		return nil, err
 	}
+	ethClient := ethclient.NewClient(rpcClient)
 	// Create smc clients
 	poe, err := proofofefficiency.NewProofofefficiency(PoEAddr, ethClient)
 	if err != nil {
@@ -94,7 +100,7 @@ func NewClient(cfg Config, auth *bind.TransactOpts, PoEAddr common.Address, mati
 	var scAddresses []common.Address
 	scAddresses = append(scAddresses, PoEAddr, globalExitRootManAddr)
 
-	return &Client{EtherClient: ethClient, PoE: poe, Matic: matic, GlobalExitRootManager: globalExitRoot, SCAddresses: scAddresses, auth: auth}, nil
+	return &Client{EtherClient: ethClient, PoE: poe, Matic: matic, GlobalExitRootManager: globalExitRoot, SCAddresses: scAddresses, rpcClient: rpcClient, auth: auth}, nil
 }
 
 // GetRollupInfoByBlockRange function retrieves the Rollup information that are included in all this ethereum blocks
@@ -219,7 +225,7 @@ func (etherMan *Client) sequenceBatches(opts *bind.TransactOpts, sequences []eth
 			Transactions:          batchL2Data,
 			GlobalExitRoot:        seq.GlobalExitRoot,
 			Timestamp:             uint64(seq.Timestamp),
-			ForceBatchesTimestamp: nil,
+			ForceBatchesTimestamp: seq.ForceBatchesTimestamp,
 		}
 
 		batches = append(batches, batch)
@@ -541,6 +547,19 @@ func (etherMan *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*t
 	return etherMan.EtherClient.HeaderByNumber(ctx, number)
 }
 
+// HeaderByTag returns the L1 block header identified by a block tag like "safe" or "finalized".
+func (etherMan *Client) HeaderByTag(ctx context.Context, tag string) (*types.Header, error) {
+	if etherMan.rpcClient == nil {
+		return nil, ErrBlockTagNotSupported
+	}
+	var head *types.Header
+	err := etherMan.rpcClient.CallContext(ctx, &head, "eth_getBlockByNumber", tag, false)
+	if err == nil && head == nil {
+		err = ethereum.NotFound
+	}
+	return head, err
+}
+
 // EthBlockByNumber function retrieves the ethereum block information by ethereum block number.
 func (etherMan *Client) EthBlockByNumber(ctx context.Context, blockNumber uint64) (*types.Block, error) {
 	block, err := etherMan.EtherClient.BlockByNumber(ctx, new(big.Int).SetUint64(blockNumber))
@@ -558,11 +577,22 @@ func (etherMan *Client) GetLatestBatchNumber() (uint64, error) {
 	return etherMan.PoE.LastBatchSequenced(&bind.CallOpts{Pending: false})
 }
 
+// GetForceBatchTimeout gets the time, in seconds, after which anyone can
+// sequence a forced batch that hasn't been sequenced by the trusted sequencer
+func (etherMan *Client) GetForceBatchTimeout() (uint64, error) {
+	return etherMan.PoE.FORCEBATCHTIMEOUT(&bind.CallOpts{Pending: false})
+}
+
 // GetLatestVerifiedBatchNum gets latest verified batch from ethereum
 func (etherMan *Client) GetLatestVerifiedBatchNum() (uint64, error) {
 	return etherMan.PoE.LastVerifiedBatch(&bind.CallOpts{Pending: false})
 }
 
+// GetL1GasPrice gets the gas price suggested by the l1 node
+func (etherMan *Client) GetL1GasPrice(ctx context.Context) (*big.Int, error) {
+	return etherMan.EtherClient.SuggestGasPrice(ctx)
+}
+
 // GetTx function get ethereum tx
 func (etherMan *Client) GetTx(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
 	return etherMan.EtherClient.TransactionByHash(ctx, txHash)
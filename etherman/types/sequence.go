@@ -14,7 +14,10 @@ type Sequence struct {
 	GlobalExitRoot  common.Hash
 	Timestamp       int64
 	ForceBatchesNum uint64
-	Txs             []types.Transaction
+	// ForceBatchesTimestamp are the timestamps of the forced batches sequenced
+	// after this batch, one per forced batch
+	ForceBatchesTimestamp []uint64
+	Txs                   []types.Transaction
 	pool.ZkCounters
 }
 
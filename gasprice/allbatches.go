@@ -2,10 +2,18 @@ package gasprice
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"sync"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/jackc/pgx/v4"
 )
 
+// blocksPerUpdate is the max number of l2 blocks averaged on each update.
+const blocksPerUpdate = 1000
+
 // AllBatches struct for all batches avg price strategy.
 type AllBatches struct {
 	// Average gas price (rolling average)
@@ -13,13 +21,44 @@ type AllBatches struct {
 	averageGasPriceCount *big.Int // Param used in the avg. gas price calculation
 
 	agpMux sync.Mutex // Mutex for the averageGasPrice calculation
+
+	cfg   Config
+	state stateInterface
 }
 
 // NewEstimatorAllBatches init gas price estimator for all batches strategy.
-func NewEstimatorAllBatches() *AllBatches {
+func NewEstimatorAllBatches(cfg Config, state stateInterface) *AllBatches {
 	return &AllBatches{
 		averageGasPrice:      big.NewInt(0),
 		averageGasPriceCount: big.NewInt(0),
+		cfg:                  cfg,
+		state:                state,
+	}
+}
+
+// Start feeds the average with the txs of the new l2 blocks on each period
+// until the context is done. The average is stored in the state, so it
+// survives restarts and is shared by all the instances using the same db.
+func (g *AllBatches) Start(ctx context.Context) {
+	ticker := time.NewTicker(g.cfg.UpdatePeriod.Duration)
+	defer ticker.Stop()
+	for {
+		// keep updating until reaching the last l2 block
+		for {
+			caughtUp, err := g.updateFromL2Blocks(ctx)
+			if err != nil {
+				log.Errorf("failed to update the average gas price, err: %v", err)
+				break
+			}
+			if caughtUp || ctx.Err() != nil {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
 }
 
@@ -30,7 +69,7 @@ func (g *AllBatches) UpdateGasPriceAvg(newValue *big.Int) {
 	g.averageGasPriceCount.Add(g.averageGasPriceCount, big.NewInt(1))
 
 	differential := big.NewInt(0)
-	differential.Div(newValue.Sub(newValue, g.averageGasPrice), g.averageGasPriceCount)
+	differential.Div(new(big.Int).Sub(newValue, g.averageGasPrice), g.averageGasPriceCount)
 
 	g.averageGasPrice.Add(g.averageGasPrice, differential)
 
@@ -39,5 +78,78 @@ func (g *AllBatches) UpdateGasPriceAvg(newValue *big.Int) {
 
 // GetAvgGasPrice get avg gas price from all blocks.
 func (g *AllBatches) GetAvgGasPrice(ctx context.Context) (*big.Int, error) {
-	return g.averageGasPrice, nil
+	g.agpMux.Lock()
+	defer g.agpMux.Unlock()
+	return new(big.Int).Set(g.averageGasPrice), nil
+}
+
+// updateFromL2Blocks loads the stored average, feeds it with the txs of the
+// next l2 blocks, up to blocksPerUpdate blocks, and stores it again. It
+// returns true when the last l2 block has been averaged.
+func (g *AllBatches) updateFromL2Blocks(ctx context.Context) (bool, error) {
+	dbTx, err := g.state.BeginStateTransaction(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	caughtUp, err := g.updateFromL2BlocksInTx(ctx, dbTx)
+	if err != nil {
+		if rollbackErr := dbTx.Rollback(ctx); rollbackErr != nil {
+			return false, fmt.Errorf("error rolling back state: %v, err: %w", rollbackErr, err)
+		}
+		return false, err
+	}
+	return caughtUp, dbTx.Commit(ctx)
+}
+
+func (g *AllBatches) updateFromL2BlocksInTx(ctx context.Context, dbTx pgx.Tx) (bool, error) {
+	// the stored average is locked until the db tx ends, so the instances
+	// sharing the db don't average the same blocks twice
+	gasPriceAvg, err := g.state.GetGasPriceAvg(ctx, dbTx)
+	if err != nil {
+		return false, err
+	}
+
+	g.agpMux.Lock()
+	g.averageGasPrice = gasPriceAvg.Avg
+	g.averageGasPriceCount = new(big.Int).SetUint64(gasPriceAvg.Count)
+	g.agpMux.Unlock()
+
+	lastBlock, err := g.state.GetLastL2BlockNumber(ctx, dbTx)
+	if err != nil {
+		return false, err
+	}
+	if lastBlock <= gasPriceAvg.LastL2BlockNumber {
+		if lastBlock < gasPriceAvg.LastL2BlockNumber {
+			// the txs of the reorged blocks stay in the average, the new
+			// blocks with the same numbers are averaged too
+			gasPriceAvg.LastL2BlockNumber = lastBlock
+			return true, g.state.SetGasPriceAvg(ctx, gasPriceAvg, dbTx)
+		}
+		return true, nil
+	}
+
+	fromBlock := gasPriceAvg.LastL2BlockNumber + 1
+	toBlock := lastBlock
+	if fromBlock+blocksPerUpdate-1 < toBlock {
+		toBlock = fromBlock + blocksPerUpdate - 1
+	}
+
+	gasPrices, err := g.state.GetEffectiveGasPrices(ctx, fromBlock, toBlock, dbTx)
+	if err != nil {
+		return false, err
+	}
+	for _, gasPrice := range gasPrices {
+		g.UpdateGasPriceAvg(gasPrice)
+	}
+
+	g.agpMux.Lock()
+	gasPriceAvg.LastL2BlockNumber = toBlock
+	gasPriceAvg.Avg = new(big.Int).Set(g.averageGasPrice)
+	gasPriceAvg.Count = g.averageGasPriceCount.Uint64()
+	g.agpMux.Unlock()
+
+	log.Debugf("average gas price updated to %d wei from l2 blocks %d to %d", gasPriceAvg.Avg, fromBlock, toBlock)
+
+	return toBlock == lastBlock, g.state.SetGasPriceAvg(ctx, gasPriceAvg, dbTx)
 }
@@ -0,0 +1,115 @@
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllBatchesUpdateFromL2Blocks(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name              string
+		storedAvg         *state.GasPriceAvg
+		lastBlock         uint64
+		fromBlock         uint64
+		toBlock           uint64
+		gasPrices         []*big.Int
+		expectedAvg       *state.GasPriceAvg
+		expectedCaughtUp  bool
+		getGasPricesErr   error
+		expectedErr       bool
+		expectedStoredAvg bool
+	}{
+		{
+			name:      "average the txs of the new blocks",
+			storedAvg: &state.GasPriceAvg{LastL2BlockNumber: 10, Avg: big.NewInt(100), Count: 1},
+			lastBlock: 12,
+			fromBlock: 11,
+			toBlock:   12,
+			gasPrices: []*big.Int{big.NewInt(200), big.NewInt(300)},
+			// (100 + 200 + 300) / 3
+			expectedAvg:       &state.GasPriceAvg{LastL2BlockNumber: 12, Avg: big.NewInt(200), Count: 3},
+			expectedCaughtUp:  true,
+			expectedStoredAvg: true,
+		},
+		{
+			name:      "average up to blocksPerUpdate blocks",
+			storedAvg: &state.GasPriceAvg{LastL2BlockNumber: 0, Avg: big.NewInt(0), Count: 0},
+			lastBlock: 2 * blocksPerUpdate,
+			fromBlock: 1,
+			toBlock:   blocksPerUpdate,
+			gasPrices: []*big.Int{big.NewInt(100)},
+			expectedAvg: &state.GasPriceAvg{
+				LastL2BlockNumber: blocksPerUpdate, Avg: big.NewInt(100), Count: 1,
+			},
+			expectedCaughtUp:  false,
+			expectedStoredAvg: true,
+		},
+		{
+			name:             "caught up",
+			storedAvg:        &state.GasPriceAvg{LastL2BlockNumber: 10, Avg: big.NewInt(100), Count: 1},
+			lastBlock:        10,
+			expectedAvg:      &state.GasPriceAvg{LastL2BlockNumber: 10, Avg: big.NewInt(100), Count: 1},
+			expectedCaughtUp: true,
+		},
+		{
+			name:              "reorg",
+			storedAvg:         &state.GasPriceAvg{LastL2BlockNumber: 10, Avg: big.NewInt(100), Count: 1},
+			lastBlock:         8,
+			expectedAvg:       &state.GasPriceAvg{LastL2BlockNumber: 8, Avg: big.NewInt(100), Count: 1},
+			expectedCaughtUp:  true,
+			expectedStoredAvg: true,
+		},
+		{
+			name:            "rollback on error",
+			storedAvg:       &state.GasPriceAvg{LastL2BlockNumber: 10, Avg: big.NewInt(100), Count: 1},
+			lastBlock:       12,
+			fromBlock:       11,
+			toBlock:         12,
+			getGasPricesErr: errors.New("state error"),
+			expectedErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := newStateMock(t)
+			dbTx := newDbTxMock(t)
+			gpe := NewEstimatorAllBatches(Config{}, st)
+
+			st.On("BeginStateTransaction", ctx).Return(dbTx, nil).Once()
+			st.On("GetGasPriceAvg", ctx, dbTx).Return(tc.storedAvg, nil).Once()
+			st.On("GetLastL2BlockNumber", ctx, dbTx).Return(tc.lastBlock, nil).Once()
+			if tc.lastBlock > tc.storedAvg.LastL2BlockNumber {
+				st.On("GetEffectiveGasPrices", ctx, tc.fromBlock, tc.toBlock, dbTx).Return(tc.gasPrices, tc.getGasPricesErr).Once()
+			}
+			if tc.expectedStoredAvg {
+				st.On("SetGasPriceAvg", ctx, tc.expectedAvg, dbTx).Return(nil).Once()
+			}
+			if tc.expectedErr {
+				dbTx.On("Rollback", ctx).Return(nil).Once()
+			} else {
+				dbTx.On("Commit", ctx).Return(nil).Once()
+			}
+
+			caughtUp, err := gpe.updateFromL2Blocks(ctx)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCaughtUp, caughtUp)
+
+			gasPrice, err := gpe.GetAvgGasPrice(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAvg.Avg, gasPrice)
+		})
+	}
+}
@@ -2,6 +2,8 @@ package gasprice
 
 import (
 	"math/big"
+
+	"github.com/0xPolygonHermez/zkevm-node/config/types"
 )
 
 // EstimatorType different gas estimator types.
@@ -14,6 +16,11 @@ const (
 	AllBatchesType EstimatorType = "allbatches"
 	// LastNBatchesType calculate average gas tip from last n batches.
 	LastNBatchesType EstimatorType = "lastnbatches"
+	// L1GasPriceType gas price derived from the l1 gas price.
+	L1GasPriceType EstimatorType = "l1gasprice"
+	// PercentileType calculate a percentile of the gas price of the l2
+	// blocks of a time window.
+	PercentileType EstimatorType = "percentile"
 )
 
 // Config for gas price estimator.
@@ -25,4 +32,17 @@ type Config struct {
 	IgnorePrice        *big.Int `mapstructure:"IgnorePrice"`
 	CheckBlocks        int      `mapstructure:"CheckBlocks"`
 	Percentile         int      `mapstructure:"Percentile"`
+
+	// UpdatePeriod is the interval at which the l2 gas price is updated from
+	// the l1 gas price, and at which the average of all batches is updated.
+	UpdatePeriod types.Duration `mapstructure:"UpdatePeriod"`
+	// TimeWindow is the time window of the l2 blocks taken into account by
+	// the percentile estimator.
+	TimeWindow types.Duration `mapstructure:"TimeWindow"`
+	// L1GasPriceFactor is the factor applied to the l1 gas price to get the
+	// l2 gas price.
+	L1GasPriceFactor float64 `mapstructure:"L1GasPriceFactor"`
+	// DataAvailabilityGasPerBatch is the l1 gas used to make the data of a
+	// batch available on l1, its cost is spread over the gas of the batch.
+	DataAvailabilityGasPerBatch uint64 `mapstructure:"DataAvailabilityGasPerBatch"`
 }
@@ -0,0 +1,242 @@
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	// maxFeeHistoryBlocks is the max number of l2 blocks of a fee history.
+	maxFeeHistoryBlocks = 1024
+	// maxRewardPercentiles is the max number of reward percentiles of a fee
+	// history.
+	maxRewardPercentiles = 100
+)
+
+var (
+	// ErrInvalidPercentile is returned when the reward percentiles are not
+	// between 0 and 100 or are not sorted.
+	ErrInvalidPercentile = errors.New("invalid reward percentile")
+	// ErrTooManyPercentiles is returned when more than maxRewardPercentiles
+	// reward percentiles are requested.
+	ErrTooManyPercentiles = errors.New("too many reward percentiles")
+)
+
+// FeeHistoryResult is the fee history of a range of l2 blocks.
+type FeeHistoryResult struct {
+	OldestBlock uint64
+	// Reward is the tip paid over the base fee at each of the requested
+	// percentiles, for each block.
+	Reward [][]*big.Int
+	// BaseFee is the base fee of each block plus the one of the next block.
+	// The blocks without base fee get the current l2 gas price.
+	BaseFee      []*big.Int
+	GasUsedRatio []float64
+}
+
+// FeeHistory computes the fee history of the l2 blocks and suggests the tip
+// of new txs. The l2 blocks have no base fee, so the current l2 gas price is
+// used as a flat base fee for all of them, and the tips are the part of the
+// gas price paid over it. The current price is the one set in the pool, or
+// the one suggested by the gas price estimator when the pool has none, so the
+// base fee of a past block is the current price and not the one in force
+// when the block was produced.
+type FeeHistory struct {
+	cfg   Config
+	state stateInterface
+	pool  pool
+	gpe   gasPriceEstimator
+}
+
+// NewFeeHistory init the fee history oracle.
+func NewFeeHistory(cfg Config, state stateInterface, pool pool, gpe gasPriceEstimator) *FeeHistory {
+	return &FeeHistory{
+		cfg:   cfg,
+		state: state,
+		pool:  pool,
+		gpe:   gpe,
+	}
+}
+
+// blockFees are the fees of an l2 block.
+type blockFees struct {
+	baseFee      *big.Int
+	gasUsedRatio float64
+	reward       []*big.Int
+	txs          int
+}
+
+// FeeHistory returns the fee history of the blockCount l2 blocks up to the
+// newest block, with the tips at the given percentiles of each block. Each
+// tx counts the same towards the percentiles, as each l2 block holds a
+// single tx.
+func (f *FeeHistory) FeeHistory(ctx context.Context, blockCount, newestBlock uint64, rewardPercentiles []float64, dbTx pgx.Tx) (*FeeHistoryResult, error) {
+	if len(rewardPercentiles) > maxRewardPercentiles {
+		return nil, ErrTooManyPercentiles
+	}
+	for i, p := range rewardPercentiles {
+		if p < 0 || p > 100 || (i > 0 && p < rewardPercentiles[i-1]) { //nolint:gomnd
+			return nil, fmt.Errorf("%w: %f", ErrInvalidPercentile, p)
+		}
+	}
+	if blockCount > maxFeeHistoryBlocks {
+		blockCount = maxFeeHistoryBlocks
+	}
+	if blockCount > newestBlock+1 {
+		blockCount = newestBlock + 1
+	}
+
+	baseFee, err := f.getFlatBaseFee(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FeeHistoryResult{
+		OldestBlock:  newestBlock + 1 - blockCount,
+		Reward:       make([][]*big.Int, blockCount),
+		BaseFee:      make([]*big.Int, blockCount+1),
+		GasUsedRatio: make([]float64, blockCount),
+	}
+	if blockCount == 0 {
+		result.BaseFee[0] = baseFee
+		return result, nil
+	}
+
+	fees, err := f.getBlocksFees(ctx, result.OldestBlock, newestBlock, baseFee, rewardPercentiles, dbTx)
+	if err != nil {
+		return nil, err
+	}
+	for i, fee := range fees {
+		result.BaseFee[i] = fee.baseFee
+		result.GasUsedRatio[i] = fee.gasUsedRatio
+		result.Reward[i] = fee.reward
+	}
+	result.BaseFee[blockCount] = baseFee
+	if len(rewardPercentiles) == 0 {
+		result.Reward = nil
+	}
+
+	return result, nil
+}
+
+// SuggestTipCap suggests the tip of new txs, it is the configured percentile
+// of the median tips of the last checked l2 blocks.
+func (f *FeeHistory) SuggestTipCap(ctx context.Context) (*big.Int, error) {
+	lastBlock, err := f.state.GetLastL2BlockNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last l2 block number, err: %v", err)
+	}
+	if f.cfg.CheckBlocks <= 0 || lastBlock == 0 {
+		return big.NewInt(0), nil
+	}
+
+	fromBlock := uint64(1)
+	if lastBlock > uint64(f.cfg.CheckBlocks) {
+		fromBlock = lastBlock - uint64(f.cfg.CheckBlocks) + 1
+	}
+
+	baseFee, err := f.getFlatBaseFee(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fees, err := f.getBlocksFees(ctx, fromBlock, lastBlock, baseFee, []float64{50}, nil) //nolint:gomnd
+	if err != nil {
+		return nil, err
+	}
+
+	tips := make([]*big.Int, 0, len(fees))
+	for _, fee := range fees {
+		if fee.txs > 0 {
+			tips = append(tips, fee.reward[0])
+		}
+	}
+	if len(tips) == 0 {
+		return big.NewInt(0), nil
+	}
+	sort.Sort(bigIntArray(tips))
+	tip := tips[(len(tips)-1)*f.cfg.Percentile/100]
+	if f.cfg.MaxPrice != nil && tip.Cmp(f.cfg.MaxPrice) > 0 {
+		tip = f.cfg.MaxPrice
+	}
+
+	return tip, nil
+}
+
+// getFlatBaseFee returns the current l2 gas price, it is used as the base fee
+// of all the blocks, past ones included, as the previous l2 gas prices are not
+// kept. The pool only has a gas price when it is derived from the l1 gas
+// price, otherwise the one suggested by the gas price estimator is used.
+func (f *FeeHistory) getFlatBaseFee(ctx context.Context) (*big.Int, error) {
+	gasPrice, err := f.pool.GetGasPrice(ctx)
+	if err != nil && !errors.Is(err, state.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get the l2 gas price, err: %v", err)
+	}
+	if err == nil && gasPrice > 0 {
+		return new(big.Int).SetUint64(gasPrice), nil
+	}
+
+	baseFee, err := f.gpe.GetAvgGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the suggested l2 gas price, err: %v", err)
+	}
+	return baseFee, nil
+}
+
+// getBlocksFees calculates the fees of the given range of l2 blocks, that are
+// loaded at once.
+func (f *FeeHistory) getBlocksFees(ctx context.Context, fromBlock, toBlock uint64, baseFee *big.Int, rewardPercentiles []float64, dbTx pgx.Tx) ([]blockFees, error) {
+	blocks, err := f.state.GetL2BlocksByNumberRange(ctx, fromBlock, toBlock, dbTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get l2 blocks %d to %d, err: %v", fromBlock, toBlock, err)
+	}
+	if uint64(len(blocks)) != toBlock-fromBlock+1 {
+		return nil, fmt.Errorf("got %d of the l2 blocks %d to %d", len(blocks), fromBlock, toBlock)
+	}
+
+	fees := make([]blockFees, 0, len(blocks))
+	for _, block := range blocks {
+		fees = append(fees, getBlockFees(block.Header(), block.Transactions(), baseFee, rewardPercentiles))
+	}
+	return fees, nil
+}
+
+// getBlockFees calculates the fees of an l2 block. The base fee of the block
+// is used when it has one, otherwise the given flat base fee.
+func getBlockFees(header *types.Header, txs []*types.Transaction, baseFee *big.Int, rewardPercentiles []float64) blockFees {
+	var fee blockFees
+
+	fee.baseFee = baseFee
+	if header.BaseFee != nil {
+		fee.baseFee = header.BaseFee
+	}
+	if header.GasLimit > 0 {
+		fee.gasUsedRatio = float64(header.GasUsed) / float64(header.GasLimit)
+	}
+	fee.txs = len(txs)
+
+	fee.reward = make([]*big.Int, len(rewardPercentiles))
+	if len(txs) == 0 {
+		for i := range fee.reward {
+			fee.reward[i] = big.NewInt(0)
+		}
+		return fee
+	}
+
+	sorter := newSorter(txs, fee.baseFee)
+	sort.Sort(sorter)
+	for i, p := range rewardPercentiles {
+		tip := sorter.txs[int(float64(len(txs)-1)*p/100)].EffectiveGasTipValue(fee.baseFee) //nolint:gomnd
+		if tip.Sign() < 0 {
+			tip = big.NewInt(0)
+		}
+		fee.reward[i] = tip
+	}
+	return fee
+}
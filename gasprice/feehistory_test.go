@@ -0,0 +1,197 @@
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newL2BlockTxs returns a tx paying each of the given gas prices.
+func newL2BlockTxs(gasPrices ...int64) []*types.Transaction {
+	txs := make([]*types.Transaction, 0, len(gasPrices))
+	for i, gasPrice := range gasPrices {
+		txs = append(txs, types.NewTx(&types.LegacyTx{Nonce: uint64(i), GasPrice: big.NewInt(gasPrice)}))
+	}
+	return txs
+}
+
+// newL2Block returns an l2 block with the given header and txs.
+func newL2Block(header *types.Header, txs []*types.Transaction) *types.Block {
+	return types.NewBlockWithHeader(header).WithBody(txs, nil)
+}
+
+func TestFeeHistory(t *testing.T) {
+	ctx := context.Background()
+	st := newStateMock(t)
+	pool := newPoolMock(t)
+	dbTx := newDbTxMock(t)
+	f := NewFeeHistory(Config{}, st, pool, newGasPriceEstimatorMock(t))
+
+	pool.On("GetGasPrice", ctx).Return(uint64(100), nil).Once()
+	st.On("GetL2BlocksByNumberRange", ctx, uint64(2), uint64(4), dbTx).Return([]*types.Block{
+		newL2Block(&types.Header{Number: big.NewInt(2), GasLimit: 1000, GasUsed: 500}, newL2BlockTxs(120, 180)),
+		newL2Block(&types.Header{Number: big.NewInt(3), GasLimit: 1000}, nil),
+		// the base fee of the block is used when it has one
+		newL2Block(&types.Header{Number: big.NewInt(4), GasLimit: 1000, GasUsed: 1000, BaseFee: big.NewInt(150)}, newL2BlockTxs(160)),
+	}, nil).Once()
+
+	result, err := f.FeeHistory(ctx, 3, 4, []float64{0, 100}, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, &FeeHistoryResult{
+		OldestBlock:  2,
+		Reward:       [][]*big.Int{{big.NewInt(20), big.NewInt(80)}, {big.NewInt(0), big.NewInt(0)}, {big.NewInt(10), big.NewInt(10)}},
+		BaseFee:      []*big.Int{big.NewInt(100), big.NewInt(100), big.NewInt(150), big.NewInt(100)},
+		GasUsedRatio: []float64{0.5, 0, 1},
+	}, result)
+}
+
+func TestFeeHistoryBlockCountCappedToGenesis(t *testing.T) {
+	ctx := context.Background()
+	st := newStateMock(t)
+	pool := newPoolMock(t)
+	gpe := newGasPriceEstimatorMock(t)
+	f := NewFeeHistory(Config{}, st, pool, gpe)
+
+	// no l2 gas price set in the pool, the suggested one is used
+	pool.On("GetGasPrice", ctx).Return(uint64(0), state.ErrNotFound).Once()
+	gpe.On("GetAvgGasPrice", ctx).Return(big.NewInt(20), nil).Once()
+	st.On("GetL2BlocksByNumberRange", ctx, uint64(0), uint64(1), pgx.Tx(nil)).Return([]*types.Block{
+		newL2Block(&types.Header{Number: big.NewInt(0)}, nil),
+		newL2Block(&types.Header{Number: big.NewInt(1), GasLimit: 1000, GasUsed: 250}, newL2BlockTxs(30)),
+	}, nil).Once()
+
+	result, err := f.FeeHistory(ctx, 10, 1, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &FeeHistoryResult{
+		OldestBlock:  0,
+		BaseFee:      []*big.Int{big.NewInt(20), big.NewInt(20), big.NewInt(20)},
+		GasUsedRatio: []float64{0, 0.25},
+	}, result)
+}
+
+func TestFeeHistoryZeroPoolGasPrice(t *testing.T) {
+	ctx := context.Background()
+	st := newStateMock(t)
+	pool := newPoolMock(t)
+	gpe := newGasPriceEstimatorMock(t)
+	f := NewFeeHistory(Config{}, st, pool, gpe)
+
+	// the pool price is only set when it is derived from the l1 gas price, so
+	// the full gas price is not reported as the tip when it is zero
+	pool.On("GetGasPrice", ctx).Return(uint64(0), nil).Once()
+	gpe.On("GetAvgGasPrice", ctx).Return(big.NewInt(100), nil).Once()
+	st.On("GetL2BlocksByNumberRange", ctx, uint64(1), uint64(1), pgx.Tx(nil)).Return([]*types.Block{
+		newL2Block(&types.Header{Number: big.NewInt(1)}, newL2BlockTxs(120)),
+	}, nil).Once()
+
+	result, err := f.FeeHistory(ctx, 1, 1, []float64{50}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, [][]*big.Int{{big.NewInt(20)}}, result.Reward)
+	assert.Equal(t, []*big.Int{big.NewInt(100), big.NewInt(100)}, result.BaseFee)
+}
+
+func TestFeeHistoryErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unsorted percentiles", func(t *testing.T) {
+		f := NewFeeHistory(Config{}, newStateMock(t), newPoolMock(t), newGasPriceEstimatorMock(t))
+		_, err := f.FeeHistory(ctx, 1, 1, []float64{75, 25}, nil)
+		assert.ErrorIs(t, err, ErrInvalidPercentile)
+	})
+
+	t.Run("percentile out of range", func(t *testing.T) {
+		f := NewFeeHistory(Config{}, newStateMock(t), newPoolMock(t), newGasPriceEstimatorMock(t))
+		_, err := f.FeeHistory(ctx, 1, 1, []float64{101}, nil)
+		assert.ErrorIs(t, err, ErrInvalidPercentile)
+	})
+
+	t.Run("too many percentiles", func(t *testing.T) {
+		f := NewFeeHistory(Config{}, newStateMock(t), newPoolMock(t), newGasPriceEstimatorMock(t))
+		_, err := f.FeeHistory(ctx, 1, 1, make([]float64, maxRewardPercentiles+1), nil)
+		assert.ErrorIs(t, err, ErrTooManyPercentiles)
+	})
+
+	t.Run("state error", func(t *testing.T) {
+		st := newStateMock(t)
+		pool := newPoolMock(t)
+		f := NewFeeHistory(Config{}, st, pool, newGasPriceEstimatorMock(t))
+
+		pool.On("GetGasPrice", ctx).Return(uint64(100), nil).Once()
+		st.On("GetL2BlocksByNumberRange", ctx, uint64(1), uint64(1), pgx.Tx(nil)).Return(nil, errors.New("state error")).Once()
+		_, err := f.FeeHistory(ctx, 1, 1, []float64{50}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("missing l2 blocks", func(t *testing.T) {
+		st := newStateMock(t)
+		pool := newPoolMock(t)
+		f := NewFeeHistory(Config{}, st, pool, newGasPriceEstimatorMock(t))
+
+		pool.On("GetGasPrice", ctx).Return(uint64(100), nil).Once()
+		st.On("GetL2BlocksByNumberRange", ctx, uint64(0), uint64(1), pgx.Tx(nil)).Return([]*types.Block{
+			newL2Block(&types.Header{Number: big.NewInt(0)}, nil),
+		}, nil).Once()
+		_, err := f.FeeHistory(ctx, 2, 1, []float64{50}, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestSuggestTipCap(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name        string
+		maxPrice    *big.Int
+		expectedTip *big.Int
+	}{
+		{
+			name:        "percentile of the median tips",
+			expectedTip: big.NewInt(50),
+		},
+		{
+			name:        "capped to the max price",
+			maxPrice:    big.NewInt(20),
+			expectedTip: big.NewInt(20),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := newStateMock(t)
+			pool := newPoolMock(t)
+			f := NewFeeHistory(Config{CheckBlocks: 4, Percentile: 50, MaxPrice: tc.maxPrice}, st, pool, newGasPriceEstimatorMock(t))
+
+			st.On("GetLastL2BlockNumber", ctx, pgx.Tx(nil)).Return(uint64(5), nil).Once()
+			pool.On("GetGasPrice", ctx).Return(uint64(100), nil).Once()
+			st.On("GetL2BlocksByNumberRange", ctx, uint64(2), uint64(5), pgx.Tx(nil)).Return([]*types.Block{
+				newL2Block(&types.Header{Number: big.NewInt(2)}, newL2BlockTxs(110)),
+				newL2Block(&types.Header{Number: big.NewInt(3)}, newL2BlockTxs(150, 130, 190)),
+				// the blocks without txs are not taken into account
+				newL2Block(&types.Header{Number: big.NewInt(4)}, nil),
+				newL2Block(&types.Header{Number: big.NewInt(5)}, newL2BlockTxs(170)),
+			}, nil).Once()
+
+			tip, err := f.SuggestTipCap(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTip, tip)
+		})
+	}
+}
+
+func TestSuggestTipCapNoL2Blocks(t *testing.T) {
+	ctx := context.Background()
+	st := newStateMock(t)
+	f := NewFeeHistory(Config{CheckBlocks: 4, Percentile: 50}, st, newPoolMock(t), newGasPriceEstimatorMock(t))
+
+	st.On("GetLastL2BlockNumber", ctx, pgx.Tx(nil)).Return(uint64(0), nil).Once()
+	tip, err := f.SuggestTipCap(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(0), tip)
+}
@@ -2,7 +2,10 @@ package gasprice
 
 import (
 	"context"
+	"math/big"
+	"time"
 
+	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/jackc/pgx/v4"
 )
@@ -17,6 +20,24 @@ type pool interface {
 
 // stateInterface gathers the methods required to interact with the state.
 type stateInterface interface {
+	BeginStateTransaction(ctx context.Context) (pgx.Tx, error)
 	GetLastL2BlockNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error)
 	GetTxsByBlockNumber(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) ([]*types.Transaction, error)
+	GetL2BlockHeaderByNumber(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (*types.Header, error)
+	GetL2BlocksByNumberRange(ctx context.Context, fromBlock, toBlock uint64, dbTx pgx.Tx) ([]*types.Block, error)
+	GetGasPriceAvg(ctx context.Context, dbTx pgx.Tx) (*state.GasPriceAvg, error)
+	SetGasPriceAvg(ctx context.Context, gasPriceAvg *state.GasPriceAvg, dbTx pgx.Tx) error
+	GetEffectiveGasPrices(ctx context.Context, fromBlock, toBlock uint64, dbTx pgx.Tx) ([]*big.Int, error)
+	GetGasPricePercentile(ctx context.Context, percentile float64, since time.Time, dbTx pgx.Tx) (*big.Int, error)
+}
+
+// gasPriceEstimator contains the methods required to get the l2 gas price
+// suggested to the users.
+type gasPriceEstimator interface {
+	GetAvgGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// etherman contains the methods required to interact with ethereum.
+type etherman interface {
+	GetL1GasPrice(ctx context.Context) (*big.Int, error)
 }
@@ -0,0 +1,87 @@
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+)
+
+// L1GasPrice derives the l2 gas price from the l1 gas price, it is the
+// minimum gas price accepted by the pool.
+type L1GasPrice struct {
+	cfg            Config
+	etherman       etherman
+	pool           pool
+	maxGasPerBatch uint64
+}
+
+// NewL1GasPriceEstimator init gas price estimator based on the l1 gas price,
+// maxGasPerBatch is the gas a batch can use.
+func NewL1GasPriceEstimator(cfg Config, etherman etherman, pool pool, maxGasPerBatch uint64) *L1GasPrice {
+	return &L1GasPrice{
+		cfg:            cfg,
+		etherman:       etherman,
+		pool:           pool,
+		maxGasPerBatch: maxGasPerBatch,
+	}
+}
+
+// Start updates the gas price of the pool on each period until the context
+// is done.
+func (g *L1GasPrice) Start(ctx context.Context) {
+	ticker := time.NewTicker(g.cfg.UpdatePeriod.Duration)
+	defer ticker.Stop()
+	for {
+		if err := g.updateGasPrice(ctx); err != nil {
+			log.Errorf("failed to update the gas price from l1, err: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetAvgGasPrice get the gas price derived from the l1 gas price from the pool.
+func (g *L1GasPrice) GetAvgGasPrice(ctx context.Context) (*big.Int, error) {
+	gasPrice, err := g.pool.GetGasPrice(ctx)
+	if errors.Is(err, state.ErrNotFound) {
+		return big.NewInt(0), nil
+	} else if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetUint64(gasPrice), nil
+}
+
+// UpdateGasPriceAvg not needed for l1 gas price strategy.
+func (g *L1GasPrice) UpdateGasPriceAvg(newValue *big.Int) {}
+
+func (g *L1GasPrice) updateGasPrice(ctx context.Context) error {
+	l1GasPrice, err := g.etherman.GetL1GasPrice(ctx)
+	if err != nil {
+		return err
+	}
+	gasPrice := g.calculateGasPrice(l1GasPrice)
+	log.Debugf("updating l2 gas price to %d wei from l1 gas price %d wei", gasPrice, l1GasPrice)
+	return g.pool.SetGasPrice(ctx, gasPrice)
+}
+
+// calculateGasPrice applies the factor to the l1 gas price and adds the
+// cost of making the batch data available on l1 per gas of the batch.
+func (g *L1GasPrice) calculateGasPrice(l1GasPrice *big.Int) uint64 {
+	factor := new(big.Float).SetFloat64(g.cfg.L1GasPriceFactor)
+	if g.maxGasPerBatch > 0 {
+		daFactor := new(big.Float).Quo(
+			new(big.Float).SetUint64(g.cfg.DataAvailabilityGasPerBatch),
+			new(big.Float).SetUint64(g.maxGasPerBatch),
+		)
+		factor.Add(factor, daFactor)
+	}
+	gasPrice, _ := new(big.Float).Mul(new(big.Float).SetInt(l1GasPrice), factor).Uint64()
+	return gasPrice
+}
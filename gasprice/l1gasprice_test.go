@@ -0,0 +1,43 @@
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestL1GasPriceUpdateGasPrice(t *testing.T) {
+	ctx := context.Background()
+	cfg := Config{
+		L1GasPriceFactor:            0.25,
+		DataAvailabilityGasPerBatch: 300000,
+	}
+	etherman := newEthermanMock(t)
+	pool := newPoolMock(t)
+	gpe := NewL1GasPriceEstimator(cfg, etherman, pool, 1200000)
+
+	// 0.25 of the l1 gas price plus 300000 / 1200000 of it for the data
+	etherman.On("GetL1GasPrice", ctx).Return(big.NewInt(1000), nil).Once()
+	pool.On("SetGasPrice", ctx, uint64(500)).Return(nil).Once()
+	require.NoError(t, gpe.updateGasPrice(ctx))
+
+	pool.On("GetGasPrice", ctx).Return(uint64(500), nil).Once()
+	gasPrice, err := gpe.GetAvgGasPrice(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(500), gasPrice)
+}
+
+func TestL1GasPriceGetAvgGasPriceNotSet(t *testing.T) {
+	ctx := context.Background()
+	pool := newPoolMock(t)
+	gpe := NewL1GasPriceEstimator(Config{}, nil, pool, 0)
+
+	pool.On("GetGasPrice", ctx).Return(uint64(0), state.ErrNotFound).Once()
+	gasPrice, err := gpe.GetAvgGasPrice(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(0), gasPrice)
+}
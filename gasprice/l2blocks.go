@@ -0,0 +1,45 @@
+package gasprice
+
+// maxBlockFetchers is the max number of l2 blocks fetched at once.
+const maxBlockFetchers = 4
+
+// fetchL2Blocks calls fetch for each l2 block from fromBlock to toBlock,
+// running up to maxBlockFetchers calls at once, and returns the first error.
+// The calls still running when an error is returned are not waited for, so
+// fetch must not block and its results must be discarded on error.
+func fetchL2Blocks(fromBlock, toBlock uint64, fetch func(l2BlockNumber uint64) error) error {
+	if toBlock < fromBlock {
+		return nil
+	}
+
+	var (
+		count   = toBlock - fromBlock + 1
+		next    = fromBlock
+		sent    uint64
+		errs    = make(chan error, count)
+		pending = count
+	)
+	run := func(l2BlockNumber uint64) {
+		errs <- fetch(l2BlockNumber)
+	}
+
+	for ; sent < count && sent < maxBlockFetchers; sent++ {
+		go run(next)
+		next++
+	}
+
+	for pending > 0 {
+		if err := <-errs; err != nil {
+			return err
+		}
+		pending--
+
+		if sent < count {
+			go run(next)
+			next++
+			sent++
+		}
+	}
+
+	return nil
+}
@@ -50,33 +50,33 @@ func (g *LastNL2Blocks) GetAvgGasPrice(ctx context.Context) (*big.Int, error) {
 	g.fetchLock.Lock()
 	defer g.fetchLock.Unlock()
 
-	var (
-		sent, exp int
-		number    = lastL2BlockNumber
-		result    = make(chan results, g.cfg.CheckBlocks)
-		quit      = make(chan struct{})
-		results   []*big.Int
-	)
-
-	for sent < g.cfg.CheckBlocks && number > 0 {
-		go g.getL2BlockTxsTips(ctx, number, sampleNumber, g.cfg.IgnorePrice, result, quit)
-		sent++
-		exp++
-		number--
+	if lastPrice == nil {
+		lastPrice = new(big.Int).SetUint64(g.cfg.DefaultGasPriceWei)
 	}
 
-	for exp > 0 {
-		res := <-result
-		if res.err != nil {
-			close(quit)
-			return lastPrice, res.err
+	var fromBlock uint64 = 1
+	if l2BlockNumber > uint64(g.cfg.CheckBlocks) {
+		fromBlock = l2BlockNumber - uint64(g.cfg.CheckBlocks) + 1
+	}
+	var blocksTips [][]*big.Int
+	if g.cfg.CheckBlocks > 0 && l2BlockNumber > 0 {
+		blocksTips = make([][]*big.Int, l2BlockNumber-fromBlock+1)
+		err = fetchL2Blocks(fromBlock, l2BlockNumber, func(number uint64) error {
+			tips, err := g.getL2BlockTxsTips(ctx, number, sampleNumber, g.cfg.IgnorePrice)
+			blocksTips[number-fromBlock] = tips
+			return err
+		})
+		if err != nil {
+			return lastPrice, err
 		}
-		exp--
+	}
 
-		if len(res.values) == 0 {
-			res.values = []*big.Int{lastPrice}
+	var results []*big.Int
+	for _, tips := range blocksTips {
+		if len(tips) == 0 {
+			tips = []*big.Int{lastPrice}
 		}
-		results = append(results, res.values...)
+		results = append(results, tips...)
 	}
 
 	price := lastPrice
@@ -84,7 +84,7 @@ func (g *LastNL2Blocks) GetAvgGasPrice(ctx context.Context) (*big.Int, error) {
 		sort.Sort(bigIntArray(results))
 		price = results[(len(results)-1)*g.cfg.Percentile/100]
 	}
-	if price.Cmp(g.cfg.MaxPrice) > 0 {
+	if g.cfg.MaxPrice != nil && price.Cmp(g.cfg.MaxPrice) > 0 {
 		price = g.cfg.MaxPrice
 	}
 
@@ -96,17 +96,14 @@ func (g *LastNL2Blocks) GetAvgGasPrice(ctx context.Context) (*big.Int, error) {
 	return price, nil
 }
 
-// getL2BlockTxsTips calculates l2 block transaction gas fees.
-func (g *LastNL2Blocks) getL2BlockTxsTips(ctx context.Context, l2BlockNumber uint64, limit int, ignorePrice *big.Int, result chan results, quit chan struct{}) {
+// getL2BlockTxsTips returns the lowest tips, up to limit, of the txs of an
+// l2 block, ignoring the ones below ignorePrice.
+func (g *LastNL2Blocks) getL2BlockTxsTips(ctx context.Context, l2BlockNumber uint64, limit int, ignorePrice *big.Int) ([]*big.Int, error) {
 	txs, err := g.state.GetTxsByBlockNumber(ctx, l2BlockNumber, nil)
-	if txs == nil {
-		select {
-		case result <- results{nil, err}:
-		case <-quit:
-		}
-		return
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the txs of l2 block %d, err: %v", l2BlockNumber, err)
 	}
-	sorter := newSorter(txs)
+	sorter := newSorter(txs, nil)
 	sort.Sort(sorter)
 
 	var prices []*big.Int
@@ -120,13 +117,5 @@ func (g *LastNL2Blocks) getL2BlockTxsTips(ctx context.Context, l2BlockNumber uin
 			break
 		}
 	}
-	select {
-	case result <- results{prices, nil}:
-	case <-quit:
-	}
-}
-
-type results struct {
-	values []*big.Int
-	err    error
+	return prices, nil
 }
@@ -0,0 +1,32 @@
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastNL2BlocksGetAvgGasPrice(t *testing.T) {
+	ctx := context.Background()
+	st := newStateMock(t)
+	gpe := NewEstimatorLastNL2Blocks(Config{CheckBlocks: 2, Percentile: 50, DefaultGasPriceWei: 7}, st)
+
+	st.On("GetLastL2BlockNumber", ctx, pgx.Tx(nil)).Return(uint64(3), nil).Twice()
+	// the lowest sampleNumber tips of each block are taken into account and
+	// the blocks without txs count as the last price
+	st.On("GetTxsByBlockNumber", ctx, uint64(2), pgx.Tx(nil)).Return(newL2BlockTxs(40, 10, 30, 20), nil).Once()
+	st.On("GetTxsByBlockNumber", ctx, uint64(3), pgx.Tx(nil)).Return(nil, nil).Once()
+
+	gasPrice, err := gpe.GetAvgGasPrice(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(10), gasPrice)
+
+	// the price is cached until a new l2 block arrives
+	gasPrice, err = gpe.GetAvgGasPrice(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(10), gasPrice)
+}
@@ -0,0 +1,48 @@
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/state"
+)
+
+// Percentile struct for the gas price percentile of a time window strategy.
+// The percentile is calculated by the db, so all the instances sharing it
+// return the same gas price.
+type Percentile struct {
+	cfg   Config
+	state stateInterface
+}
+
+// NewEstimatorPercentile init gas price estimator for the percentile of a
+// time window strategy.
+func NewEstimatorPercentile(cfg Config, state stateInterface) *Percentile {
+	return &Percentile{
+		cfg:   cfg,
+		state: state,
+	}
+}
+
+// GetAvgGasPrice calculate the percentile of the gas price paid by the txs of
+// the l2 blocks of the time window, the default gas price is returned when
+// there are no txs.
+func (g *Percentile) GetAvgGasPrice(ctx context.Context) (*big.Int, error) {
+	since := time.Now().Add(-g.cfg.TimeWindow.Duration)
+	price, err := g.state.GetGasPricePercentile(ctx, float64(g.cfg.Percentile)/100, since, nil) //nolint:gomnd
+	if errors.Is(err, state.ErrNotFound) {
+		price = new(big.Int).SetUint64(g.cfg.DefaultGasPriceWei)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get the gas price percentile, err: %v", err)
+	}
+	if g.cfg.MaxPrice != nil && price.Cmp(g.cfg.MaxPrice) > 0 {
+		price = g.cfg.MaxPrice
+	}
+	return price, nil
+}
+
+// UpdateGasPriceAvg not needed for percentile strategy.
+func (g *Percentile) UpdateGasPriceAvg(newValue *big.Int) {}
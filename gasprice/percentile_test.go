@@ -0,0 +1,76 @@
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/config/types"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentileGetAvgGasPrice(t *testing.T) {
+	ctx := context.Background()
+	cfg := Config{
+		DefaultGasPriceWei: 1000,
+		MaxPrice:           big.NewInt(5000),
+		Percentile:         60,
+		TimeWindow:         types.NewDuration(time.Hour),
+	}
+
+	testCases := []struct {
+		name             string
+		percentile       *big.Int
+		err              error
+		expectedGasPrice *big.Int
+		expectedErr      bool
+	}{
+		{
+			name:             "percentile of the time window",
+			percentile:       big.NewInt(2000),
+			expectedGasPrice: big.NewInt(2000),
+		},
+		{
+			name:             "default gas price when there are no txs",
+			err:              state.ErrNotFound,
+			expectedGasPrice: big.NewInt(1000),
+		},
+		{
+			name:             "capped to the max price",
+			percentile:       big.NewInt(8000),
+			expectedGasPrice: big.NewInt(5000),
+		},
+		{
+			name:        "state error",
+			err:         errors.New("state error"),
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := newStateMock(t)
+			gpe := NewEstimatorPercentile(cfg, st)
+
+			before := time.Now()
+			st.On("GetGasPricePercentile", ctx, 0.6, mock.MatchedBy(func(since time.Time) bool {
+				// the time window ends when the price is requested
+				return !since.Before(before.Add(-time.Hour)) && !since.After(time.Now().Add(-time.Hour))
+			}), pgx.Tx(nil)).Return(tc.percentile, tc.err).Once()
+
+			gasPrice, err := gpe.GetAvgGasPrice(ctx)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedGasPrice, gasPrice)
+		})
+	}
+}
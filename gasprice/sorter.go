@@ -6,13 +6,17 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
+// txSorter sorts the txs by the tip they pay over the given base fee, when
+// the base fee is nil the tip is the gas tip cap.
 type txSorter struct {
-	txs []*types.Transaction
+	txs     []*types.Transaction
+	baseFee *big.Int
 }
 
-func newSorter(txs []*types.Transaction) *txSorter {
+func newSorter(txs []*types.Transaction, baseFee *big.Int) *txSorter {
 	return &txSorter{
-		txs: txs,
+		txs:     txs,
+		baseFee: baseFee,
 	}
 }
 
@@ -21,8 +25,8 @@ func (s *txSorter) Swap(i, j int) {
 	s.txs[i], s.txs[j] = s.txs[j], s.txs[i]
 }
 func (s *txSorter) Less(i, j int) bool {
-	tip1 := s.txs[i].GasTipCap()
-	tip2 := s.txs[j].GasTipCap()
+	tip1 := s.txs[i].EffectiveGasTipValue(s.baseFee)
+	tip2 := s.txs[j].EffectiveGasTipValue(s.baseFee)
 	return tip1.Cmp(tip2) < 0
 }
 
@@ -3,13 +3,20 @@ package jsonrpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"strings"
 
 	"github.com/0xPolygonHermez/zkevm-node/encoding"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/jackc/pgx/v4"
 )
 
 const (
+	// FinalizedBlockNumber represents the last block whose batch is verified on L1
+	FinalizedBlockNumber = BlockNumber(-5)
+	// SafeBlockNumber represents the last block whose batch is virtualized on L1
+	SafeBlockNumber = BlockNumber(-4)
 	// PendingBlockNumber represents the pending block number
 	PendingBlockNumber = BlockNumber(-3)
 	// LatestBlockNumber represents the latest block number
@@ -23,6 +30,10 @@ const (
 	Latest = "latest"
 	// Pending contains the string to represent pending blocks.
 	Pending = "pending"
+	// Safe contains the string to represent the last virtualized block.
+	Safe = "safe"
+	// Finalized contains the string to represent the last verified block.
+	Finalized = "finalized"
 )
 
 // Request is a jsonrpc request
@@ -121,6 +132,25 @@ func (b *BlockNumber) getNumericBlockNumber(ctx context.Context, s stateInterfac
 
 		return lastBlockNumber, nil
 
+	case SafeBlockNumber:
+		lastVirtualBatchNumber, err := s.GetLastVirtualBatchNum(ctx, dbTx)
+		if err != nil {
+			return 0, newRPCError(defaultErrorCode, "failed to get the last virtual batch number from state")
+		}
+
+		return getLastL2BlockNumberUntilBatch(ctx, s, lastVirtualBatchNumber, dbTx)
+
+	case FinalizedBlockNumber:
+		var lastVerifiedBatchNumber uint64
+		lastVerifiedBatch, err := s.GetLastVerifiedBatch(ctx, dbTx)
+		if err != nil && !errors.Is(err, state.ErrNotFound) {
+			return 0, newRPCError(defaultErrorCode, "failed to get the last verified batch number from state")
+		} else if err == nil {
+			lastVerifiedBatchNumber = lastVerifiedBatch.BatchNumber
+		}
+
+		return getLastL2BlockNumberUntilBatch(ctx, s, lastVerifiedBatchNumber, dbTx)
+
 	case EarliestBlockNumber:
 		return 0, nil
 
@@ -132,6 +162,15 @@ func (b *BlockNumber) getNumericBlockNumber(ctx context.Context, s stateInterfac
 	}
 }
 
+func getLastL2BlockNumberUntilBatch(ctx context.Context, s stateInterface, batchNumber uint64, dbTx pgx.Tx) (uint64, rpcError) {
+	blockNumber, err := s.GetLastL2BlockNumberUntilBatch(ctx, batchNumber, dbTx)
+	if err != nil {
+		return 0, newRPCError(defaultErrorCode, "failed to get the last block number of batch %v from state", batchNumber)
+	}
+
+	return blockNumber, nil
+}
+
 func stringToBlockNumber(str string) (BlockNumber, error) {
 	str = strings.Trim(str, "\"")
 	switch str {
@@ -139,6 +178,10 @@ func stringToBlockNumber(str string) (BlockNumber, error) {
 		return EarliestBlockNumber, nil
 	case Pending:
 		return PendingBlockNumber, nil
+	case Safe:
+		return SafeBlockNumber, nil
+	case Finalized:
+		return FinalizedBlockNumber, nil
 	case Latest, "":
 		return LatestBlockNumber, nil
 	}
@@ -150,6 +193,131 @@ func stringToBlockNumber(str string) (BlockNumber, error) {
 	return BlockNumber(n), nil
 }
 
+// BlockNumberOrHash is a block parameter that can be given either as a block
+// number or as an object containing the block number or the block hash, as
+// defined by EIP-1898. Only the l2 blocks of the current trusted state are
+// stored, the ones discarded when the trusted state is reset are removed, so
+// a block given by hash is either canonical or not found. requireCanonical
+// is accepted and ignored.
+type BlockNumberOrHash struct {
+	number *BlockNumber
+	hash   *common.Hash
+}
+
+// UnmarshalJSON automatically decodes the user input for the block number or hash, when a JSON RPC method is called
+func (b *BlockNumberOrHash) UnmarshalJSON(buffer []byte) error {
+	var obj struct {
+		BlockNumber *BlockNumber `json:"blockNumber"`
+		BlockHash   *common.Hash `json:"blockHash"`
+	}
+	if err := json.Unmarshal(buffer, &obj); err == nil {
+		if obj.BlockNumber != nil && obj.BlockHash != nil {
+			return errors.New("cannot specify both BlockHash and BlockNumber, choose one or the other")
+		}
+		if obj.BlockNumber == nil && obj.BlockHash == nil {
+			return errors.New("either BlockHash or BlockNumber must be specified")
+		}
+		b.number = obj.BlockNumber
+		b.hash = obj.BlockHash
+		return nil
+	}
+
+	// a plain value can be a block hash or a block number
+	str := strings.Trim(string(buffer), "\"")
+	if len(str) == 2+2*common.HashLength && strings.HasPrefix(str, "0x") {
+		hash := common.HexToHash(str)
+		b.hash = &hash
+		return nil
+	}
+
+	number, err := stringToBlockNumber(str)
+	if err != nil {
+		return err
+	}
+	b.number = &number
+	return nil
+}
+
+// getNumericBlockNumber resolves the block number, looking for the block by
+// its hash when the hash is given
+func (b *BlockNumberOrHash) getNumericBlockNumber(ctx context.Context, s stateInterface, dbTx pgx.Tx) (uint64, rpcError) {
+	if b == nil {
+		var number *BlockNumber
+		return number.getNumericBlockNumber(ctx, s, dbTx)
+	}
+
+	if b.hash == nil {
+		return b.number.getNumericBlockNumber(ctx, s, dbTx)
+	}
+
+	block, err := s.GetL2BlockByHash(ctx, *b.hash, dbTx)
+	if errors.Is(err, state.ErrNotFound) {
+		return 0, newRPCError(defaultErrorCode, "header for hash not found")
+	} else if err != nil {
+		return 0, newRPCError(defaultErrorCode, "failed to get block by hash from state")
+	}
+
+	return block.NumberU64(), nil
+}
+
+// BatchNumber is the number of a batch, it accepts the same tags as the
+// block number
+type BatchNumber int64
+
+// UnmarshalJSON automatically decodes the user input for the batch number, when a JSON RPC method is called
+func (b *BatchNumber) UnmarshalJSON(buffer []byte) error {
+	num, err := stringToBlockNumber(string(buffer))
+	if err != nil {
+		return err
+	}
+	*b = BatchNumber(num)
+	return nil
+}
+
+func (b *BatchNumber) getNumericBatchNumber(ctx context.Context, s stateInterface, dbTx pgx.Tx) (uint64, rpcError) {
+	bValue := LatestBlockNumber
+	if b != nil {
+		bValue = BlockNumber(*b)
+	}
+
+	switch bValue {
+	case LatestBlockNumber, PendingBlockNumber:
+		lastBatchNumber, err := s.GetLastBatchNumber(ctx, dbTx)
+		if err != nil {
+			return 0, newRPCError(defaultErrorCode, "failed to get the last batch number from state")
+		}
+
+		return lastBatchNumber, nil
+
+	case SafeBlockNumber:
+		lastVirtualBatchNumber, err := s.GetLastVirtualBatchNum(ctx, dbTx)
+		if err != nil {
+			return 0, newRPCError(defaultErrorCode, "failed to get the last virtual batch number from state")
+		}
+
+		return lastVirtualBatchNumber, nil
+
+	case FinalizedBlockNumber:
+		lastVerifiedBatch, err := s.GetLastVerifiedBatch(ctx, dbTx)
+		if errors.Is(err, state.ErrNotFound) {
+			return 0, nil
+		} else if err != nil {
+			return 0, newRPCError(defaultErrorCode, "failed to get the last verified batch number from state")
+		}
+
+		return lastVerifiedBatch.BatchNumber, nil
+
+	case EarliestBlockNumber:
+		return 0, nil
+
+	default:
+		if bValue < 0 {
+			return 0, newRPCError(invalidParamsErrorCode, "invalid batch number: %v", bValue)
+		}
+		return uint64(bValue), nil
+	}
+}
+
 // Index of a item
 type Index int64
 
@@ -3,9 +3,14 @@ package jsonrpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"math/big"
 	"strconv"
 	"testing"
 
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -19,6 +24,8 @@ func TestBlockNumberMarshalJSON(t *testing.T) {
 		{"latest", int64(LatestBlockNumber), nil},
 		{"pending", int64(PendingBlockNumber), nil},
 		{"earliest", int64(EarliestBlockNumber), nil},
+		{"safe", int64(SafeBlockNumber), nil},
+		{"finalized", int64(FinalizedBlockNumber), nil},
 		{"", int64(LatestBlockNumber), nil},
 		{"0", int64(0), nil},
 		{"10", int64(10), nil},
@@ -97,10 +104,73 @@ func TestGetNumericBlockNumber(t *testing.T) {
 			setupMocks:          func(s *stateMock, d *dbTxMock, t *testCase) {},
 		},
 		{
-			name:                "BlockNumber Negative Number <= -4",
-			bn:                  bnPtr(BlockNumber(int64(-4))),
+			name:                "BlockNumber SafeBlockNumber",
+			bn:                  bnPtr(SafeBlockNumber),
+			expectedBlockNumber: 20,
+			expectedError:       nil,
+			setupMocks: func(s *stateMock, d *dbTxMock, t *testCase) {
+				s.
+					On("GetLastVirtualBatchNum", context.Background(), d).
+					Return(uint64(5), nil).
+					Once()
+
+				s.
+					On("GetLastL2BlockNumberUntilBatch", context.Background(), uint64(5), d).
+					Return(uint64(20), nil).
+					Once()
+			},
+		},
+		{
+			name:                "BlockNumber FinalizedBlockNumber",
+			bn:                  bnPtr(FinalizedBlockNumber),
+			expectedBlockNumber: 10,
+			expectedError:       nil,
+			setupMocks: func(s *stateMock, d *dbTxMock, t *testCase) {
+				s.
+					On("GetLastVerifiedBatch", context.Background(), d).
+					Return(&state.VerifiedBatch{BatchNumber: 3}, nil).
+					Once()
+
+				s.
+					On("GetLastL2BlockNumberUntilBatch", context.Background(), uint64(3), d).
+					Return(uint64(10), nil).
+					Once()
+			},
+		},
+		{
+			name:                "BlockNumber FinalizedBlockNumber without verified batches",
+			bn:                  bnPtr(FinalizedBlockNumber),
 			expectedBlockNumber: 0,
-			expectedError:       newRPCError(invalidParamsErrorCode, "invalid block number: -4"),
+			expectedError:       nil,
+			setupMocks: func(s *stateMock, d *dbTxMock, t *testCase) {
+				s.
+					On("GetLastVerifiedBatch", context.Background(), d).
+					Return(nil, state.ErrNotFound).
+					Once()
+
+				s.
+					On("GetLastL2BlockNumberUntilBatch", context.Background(), uint64(0), d).
+					Return(uint64(0), nil).
+					Once()
+			},
+		},
+		{
+			name:                "BlockNumber SafeBlockNumber failed to get last virtual batch",
+			bn:                  bnPtr(SafeBlockNumber),
+			expectedBlockNumber: 0,
+			expectedError:       newRPCError(defaultErrorCode, "failed to get the last virtual batch number from state"),
+			setupMocks: func(s *stateMock, d *dbTxMock, t *testCase) {
+				s.
+					On("GetLastVirtualBatchNum", context.Background(), d).
+					Return(uint64(0), errors.New("failed to get last virtual batch number")).
+					Once()
+			},
+		},
+		{
+			name:                "BlockNumber Negative Number <= -6",
+			bn:                  bnPtr(BlockNumber(int64(-6))),
+			expectedBlockNumber: 0,
+			expectedError:       newRPCError(invalidParamsErrorCode, "invalid block number: -6"),
 			setupMocks:          func(s *stateMock, d *dbTxMock, t *testCase) {},
 		},
 	}
@@ -210,6 +280,70 @@ func TestIndexUnmarshalJSON(t *testing.T) {
 	}
 }
 
+func TestBlockNumberOrHashUnmarshalJSON(t *testing.T) {
+	hash := common.HexToHash("0x29e885edaf8e4b51e1d2e05f9da28161d2fb4f6b1d53827d9b80a23cf2d7d9f1")
+
+	testCases := []struct {
+		input          string
+		expectedNumber *BlockNumber
+		expectedHash   *common.Hash
+		expectedError  bool
+	}{
+		{input: `"latest"`, expectedNumber: bnPtr(LatestBlockNumber)},
+		{input: `"0xa"`, expectedNumber: bnPtr(BlockNumber(10))},
+		{input: `"` + hash.String() + `"`, expectedHash: &hash},
+		{input: `{"blockNumber":"0xa"}`, expectedNumber: bnPtr(BlockNumber(10))},
+		{input: `{"blockNumber":"safe"}`, expectedNumber: bnPtr(SafeBlockNumber)},
+		{input: `{"blockHash":"` + hash.String() + `"}`, expectedHash: &hash},
+		{input: `{"blockHash":"` + hash.String() + `","requireCanonical":true}`, expectedHash: &hash},
+		{input: `{"blockNumber":"0xa","blockHash":"` + hash.String() + `"}`, expectedError: true},
+		{input: `{}`, expectedError: true},
+		{input: `"abc"`, expectedError: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			var b BlockNumberOrHash
+			err := json.Unmarshal([]byte(testCase.input), &b)
+			if testCase.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedNumber, b.number)
+			assert.Equal(t, testCase.expectedHash, b.hash)
+		})
+	}
+}
+
+func TestBlockNumberOrHashGetNumericBlockNumber(t *testing.T) {
+	s := newStateMock(t)
+	dbTx := newDbTxMock(t)
+	hash := common.HexToHash("0x1")
+
+	s.
+		On("GetL2BlockByHash", context.Background(), hash, dbTx).
+		Return(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(7)}), nil).
+		Once()
+
+	blockNumber, rpcErr := (&BlockNumberOrHash{hash: &hash}).getNumericBlockNumber(context.Background(), s, dbTx)
+	require.Nil(t, rpcErr)
+	assert.Equal(t, uint64(7), blockNumber)
+
+	s.
+		On("GetL2BlockByHash", context.Background(), hash, dbTx).
+		Return(nil, state.ErrNotFound).
+		Once()
+
+	_, rpcErr = (&BlockNumberOrHash{hash: &hash}).getNumericBlockNumber(context.Background(), s, dbTx)
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, "header for hash not found", rpcErr.Error())
+
+	blockNumber, rpcErr = (&BlockNumberOrHash{number: bnPtr(BlockNumber(3))}).getNumericBlockNumber(context.Background(), s, dbTx)
+	require.Nil(t, rpcErr)
+	assert.Equal(t, uint64(3), blockNumber)
+}
+
 func bnPtr(bn BlockNumber) *BlockNumber {
 	return &bn
 }
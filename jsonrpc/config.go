@@ -10,4 +10,8 @@ type Config struct {
 	// SequencerNodeURI is used allow Non-Sequencer nodes
 	// to relay transactions to the Sequencer node
 	SequencerNodeURI string `mapstructure:"URI"`
+
+	// GasCap is the highest gas limit used to estimate the gas of a transaction,
+	// 0 means the estimation is only limited by the max cumulative gas used
+	GasCap uint64 `mapstructure:"GasCap"`
 }
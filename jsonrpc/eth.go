@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/0xPolygonHermez/zkevm-node/gasprice"
 	"github.com/0xPolygonHermez/zkevm-node/hex"
 	"github.com/0xPolygonHermez/zkevm-node/log"
 	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/jackc/pgx/v4"
 )
 
@@ -21,6 +24,7 @@ type Eth struct {
 	pool    jsonRPCTxPool
 	state   stateInterface
 	gpe     gasPriceEstimator
+	fho     feeHistoryOracle
 	storage storageInterface
 	txMan   dbTxManager
 }
@@ -41,11 +45,13 @@ func (e *Eth) BlockNumber() (interface{}, rpcError) {
 // executed contract and potential error.
 // Note, this function doesn't make any changes in the state/blockchain and is
 // useful to execute view/pure methods and retrieve values.
-func (e *Eth) Call(arg *txnArgs, number *BlockNumber) (interface{}, rpcError) {
+// The optional state override allows to replace the balance, nonce, code and
+// storage of accounts before executing the call.
+func (e *Eth) Call(arg *txnArgs, number *BlockNumberOrHash, overrides *stateOverride) (interface{}, rpcError) {
 	return e.txMan.NewDbTxScope(e.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
 		// If the caller didn't supply the gas limit in the message, then we set it to maximum possible => block gas limit
 		if arg.Gas == nil || *arg.Gas == argUint64(0) {
-			header, err := e.getBlockHeader(ctx, *number, dbTx)
+			header, err := e.getBlockHeader(ctx, number, dbTx)
 			if err != nil {
 				return rpcErrorResponse(defaultErrorCode, "failed to get block header", err)
 			}
@@ -61,7 +67,7 @@ func (e *Eth) Call(arg *txnArgs, number *BlockNumber) (interface{}, rpcError) {
 			return nil, rpcErr
 		}
 
-		result := e.state.ProcessUnsignedTransaction(ctx, tx, arg.From, blockNumber, dbTx)
+		result := e.state.ProcessUnsignedTransaction(ctx, tx, arg.From, blockNumber, overrides.toStateOverride(), dbTx)
 		if result.Failed() {
 			return rpcErrorResponse(defaultErrorCode, "failed to execute call", result.Err)
 		}
@@ -81,14 +87,30 @@ func (e *Eth) ChainId() (interface{}, rpcError) { //nolint:revive
 // Note that the estimate may be significantly more than the amount of gas actually
 // used by the transaction, for a variety of reasons including EVM mechanics and
 // node performance.
-func (e *Eth) EstimateGas(arg *txnArgs, rawNum *BlockNumber) (interface{}, rpcError) {
-	tx := arg.ToTransaction()
+// The optional state override is applied the same way as in eth_call.
+func (e *Eth) EstimateGas(arg *txnArgs, rawNum *BlockNumber, overrides *stateOverride) (interface{}, rpcError) {
+	return e.txMan.NewDbTxScope(e.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		tx := arg.ToTransaction()
 
-	gasEstimation, err := e.state.EstimateGas(tx, arg.From)
-	if err != nil {
-		return rpcErrorResponse(defaultErrorCode, "failed to estimate gas", err)
-	}
-	return hex.EncodeUint64(gasEstimation), nil
+		// latest and pending are estimated against the last l2 block
+		var blockNumber *uint64
+		if rawNum != nil && *rawNum != LatestBlockNumber && *rawNum != PendingBlockNumber {
+			number, rpcErr := rawNum.getNumericBlockNumber(ctx, e.state, dbTx)
+			if rpcErr != nil {
+				return nil, rpcErr
+			}
+			blockNumber = &number
+		}
+
+		gasEstimation, err := e.state.EstimateGas(tx, arg.From, blockNumber, overrides.toStateOverride(), e.cfg.GasCap, dbTx)
+		if errors.Is(err, runtime.ErrExecutionReverted) || errors.Is(err, state.ErrGasRequiredExceedsAllowance) {
+			// revert reasons and gas allowance errors are meaningful to the caller
+			return nil, newRPCError(defaultErrorCode, err.Error())
+		} else if err != nil {
+			return rpcErrorResponse(defaultErrorCode, "failed to estimate gas", err)
+		}
+		return hex.EncodeUint64(gasEstimation), nil
+	})
 }
 
 // GasPrice returns the average gas price based on the last x blocks
@@ -104,8 +126,37 @@ func (e *Eth) GasPrice() (interface{}, rpcError) {
 	return hex.EncodeUint64(0), nil
 }
 
+// FeeHistory returns the base fee, the gas used ratio and the tips paid at
+// the given percentiles of the blockCount l2 blocks up to the newest block
+func (e *Eth) FeeHistory(blockCount argUint64, newestBlock BlockNumber, rewardPercentiles []float64) (interface{}, rpcError) {
+	return e.txMan.NewDbTxScope(e.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		blockNumber, rpcErr := newestBlock.getNumericBlockNumber(ctx, e.state, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		if newestBlock >= 0 {
+			lastBlockNumber, err := e.state.GetLastL2BlockNumber(ctx, dbTx)
+			if err != nil {
+				return rpcErrorResponse(defaultErrorCode, "failed to get the last block number from state", err)
+			}
+			if blockNumber > lastBlockNumber {
+				return nil, newRPCError(invalidParamsErrorCode, "request beyond head block")
+			}
+		}
+
+		feeHistory, err := e.fho.FeeHistory(ctx, uint64(blockCount), blockNumber, rewardPercentiles, dbTx)
+		if errors.Is(err, gasprice.ErrInvalidPercentile) || errors.Is(err, gasprice.ErrTooManyPercentiles) {
+			return nil, newRPCError(invalidParamsErrorCode, err.Error())
+		} else if err != nil {
+			return rpcErrorResponse(defaultErrorCode, "failed to get fee history", err)
+		}
+
+		return feeHistoryToRPCFeeHistory(feeHistory), nil
+	})
+}
+
 // GetBalance returns the account's balance at the referenced block
-func (e *Eth) GetBalance(address common.Address, number *BlockNumber) (interface{}, rpcError) {
+func (e *Eth) GetBalance(address common.Address, number *BlockNumberOrHash) (interface{}, rpcError) {
 	return e.txMan.NewDbTxScope(e.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
 		blockNumber, rpcErr := number.getNumericBlockNumber(ctx, e.state, dbTx)
 		if rpcErr != nil {
@@ -143,16 +194,10 @@ func (e *Eth) GetBlockByHash(hash common.Hash, fullTx bool) (interface{}, rpcErr
 func (e *Eth) GetBlockByNumber(number BlockNumber, fullTx bool) (interface{}, rpcError) {
 	return e.txMan.NewDbTxScope(e.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
 		if number == PendingBlockNumber {
-			lastBlock, err := e.state.GetLastL2Block(ctx, dbTx)
-			if err != nil {
-				return rpcErrorResponse(defaultErrorCode, "couldn't load last block from state to compute the pending block", err)
+			block, rpcErr := e.getPendingBlock(ctx, dbTx)
+			if rpcErr != nil {
+				return nil, rpcErr
 			}
-			header := types.CopyHeader(lastBlock.Header())
-			header.ParentHash = lastBlock.Hash()
-			header.Number = big.NewInt(0).SetUint64(lastBlock.Number().Uint64() + 1)
-			header.TxHash = types.EmptyRootHash
-			header.UncleHash = types.EmptyUncleHash
-			block := types.NewBlockWithHeader(header)
 			rpcBlock := l2BlockToRPCBlock(block, fullTx)
 
 			return rpcBlock, nil
@@ -177,7 +222,7 @@ func (e *Eth) GetBlockByNumber(number BlockNumber, fullTx bool) (interface{}, rp
 }
 
 // GetCode returns account code at given block number
-func (e *Eth) GetCode(address common.Address, number *BlockNumber) (interface{}, rpcError) {
+func (e *Eth) GetCode(address common.Address, number *BlockNumberOrHash) (interface{}, rpcError) {
 	return e.txMan.NewDbTxScope(e.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
 		var err error
 		blockNumber, rpcErr := number.getNumericBlockNumber(ctx, e.state, dbTx)
@@ -332,8 +377,28 @@ func (e *Eth) internalGetLogs(ctx context.Context, dbTx pgx.Tx, filter *LogFilte
 	return result, nil
 }
 
+// GetProof returns the account values and the given storage positions of an
+// address, along with the merkletree proofs of each of them
+func (e *Eth) GetProof(address common.Address, storageKeys []common.Hash, number *BlockNumberOrHash) (interface{}, rpcError) {
+	return e.txMan.NewDbTxScope(e.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		blockNumber, rpcErr := number.getNumericBlockNumber(ctx, e.state, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+
+		proof, err := e.state.GetProof(ctx, address, storageKeys, blockNumber, dbTx)
+		if errors.Is(err, state.ErrNotFound) {
+			return nil, nil
+		} else if err != nil {
+			return rpcErrorResponse(defaultErrorCode, "failed to get proof from state", err)
+		}
+
+		return accountProofToRPCAccountProof(*proof), nil
+	})
+}
+
 // GetStorageAt gets the value stored for an specific address and position
-func (e *Eth) GetStorageAt(address common.Address, position common.Hash, number *BlockNumber) (interface{}, rpcError) {
+func (e *Eth) GetStorageAt(address common.Address, position common.Hash, number *BlockNumberOrHash) (interface{}, rpcError) {
 	return e.txMan.NewDbTxScope(e.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
 		var err error
 		blockNumber, rpcErr := number.getNumericBlockNumber(ctx, e.state, dbTx)
@@ -424,7 +489,7 @@ func (e *Eth) GetTransactionByHash(hash common.Hash) (interface{}, rpcError) {
 }
 
 // GetTransactionCount returns account nonce
-func (e *Eth) GetTransactionCount(address common.Address, number *BlockNumber) (interface{}, rpcError) {
+func (e *Eth) GetTransactionCount(address common.Address, number *BlockNumberOrHash) (interface{}, rpcError) {
 	return e.txMan.NewDbTxScope(e.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
 		var err error
 		blockNumber, rpcErr := number.getNumericBlockNumber(ctx, e.state, dbTx)
@@ -434,11 +499,19 @@ func (e *Eth) GetTransactionCount(address common.Address, number *BlockNumber) (
 
 		nonce, err := e.state.GetNonce(ctx, address, blockNumber, dbTx)
 		if errors.Is(err, state.ErrNotFound) {
-			return hex.EncodeUint64(0), nil
+			nonce = 0
 		} else if err != nil {
 			return rpcErrorResponse(defaultErrorCode, "failed to count transactions", err)
 		}
 
+		// the pending nonce includes the txs of the address waiting in the pool
+		if number != nil && number.number != nil && *number.number == PendingBlockNumber {
+			nonce, err = e.pool.GetPendingNonce(ctx, address, nonce)
+			if err != nil {
+				return rpcErrorResponse(defaultErrorCode, "failed to count pending transactions", err)
+			}
+		}
+
 		return hex.EncodeUint64(nonce), nil
 	})
 }
@@ -501,6 +574,16 @@ func (e *Eth) GetTransactionReceipt(hash common.Hash) (interface{}, rpcError) {
 	})
 }
 
+// MaxPriorityFeePerGas returns the suggested tip of new txs, based on the
+// tips paid in the last blocks
+func (e *Eth) MaxPriorityFeePerGas() (interface{}, rpcError) {
+	tip, err := e.fho.SuggestTipCap(context.Background())
+	if err != nil {
+		return rpcErrorResponse(defaultErrorCode, "failed to suggest the tip", err)
+	}
+	return hex.EncodeBig(tip), nil
+}
+
 // NewBlockFilter creates a filter in the node, to notify when
 // a new block arrives. To check if the state has changed,
 // call eth_getFilterChanges.
@@ -660,8 +743,65 @@ func hexToTx(str string) (*types.Transaction, error) {
 	return tx, nil
 }
 
-func (e *Eth) getBlockHeader(ctx context.Context, number BlockNumber, dbTx pgx.Tx) (*types.Header, error) {
-	switch number {
+// getPendingBlock builds the block following the last l2 block. On the trusted
+// sequencer node it contains the txs already processed in the batch that is
+// still open, which are shown as pending until the batch is closed.
+func (e *Eth) getPendingBlock(ctx context.Context, dbTx pgx.Tx) (*types.Block, rpcError) {
+	lastBlock, err := e.state.GetLastL2Block(ctx, dbTx)
+	if err != nil {
+		return nil, newRPCError(defaultErrorCode, "couldn't load last block from state to compute the pending block")
+	}
+	header := types.CopyHeader(lastBlock.Header())
+	header.ParentHash = lastBlock.Hash()
+	header.Number = big.NewInt(0).SetUint64(lastBlock.Number().Uint64() + 1)
+	header.TxHash = types.EmptyRootHash
+	header.UncleHash = types.EmptyUncleHash
+	header.ReceiptHash = types.EmptyRootHash
+	header.Bloom = types.Bloom{}
+	header.GasUsed = 0
+
+	if e.cfg.SequencerNodeURI != "" {
+		return types.NewBlockWithHeader(header), nil
+	}
+
+	batchNumber, err := e.state.GetLastBatchNumber(ctx, dbTx)
+	if err != nil {
+		return nil, newRPCError(defaultErrorCode, "couldn't load last batch number from state to compute the pending block")
+	}
+	isClosed, err := e.state.IsBatchClosed(ctx, batchNumber, dbTx)
+	if err != nil {
+		return nil, newRPCError(defaultErrorCode, "couldn't check if the last batch is closed to compute the pending block")
+	}
+	if isClosed {
+		return types.NewBlockWithHeader(header), nil
+	}
+
+	batch, err := e.state.GetBatchByNumber(ctx, batchNumber, dbTx)
+	if err != nil {
+		return nil, newRPCError(defaultErrorCode, "couldn't load the open batch from state to compute the pending block")
+	}
+	txs, err := e.state.GetTxsByBatchNumber(ctx, batchNumber, dbTx)
+	if err != nil {
+		return nil, newRPCError(defaultErrorCode, "couldn't load the txs of the open batch from state to compute the pending block")
+	}
+	header.Coinbase = batch.Coinbase
+	header.Time = uint64(batch.Timestamp.Unix())
+
+	return types.NewBlock(header, txs, nil, nil, trie.NewStackTrie(nil)), nil
+}
+
+func (e *Eth) getBlockHeader(ctx context.Context, number *BlockNumberOrHash, dbTx pgx.Tx) (*types.Header, error) {
+	if number == nil || number.number == nil {
+		// blocks given by hash or missing are resolved the same way as
+		// numeric block numbers
+		blockNumber, rpcErr := number.getNumericBlockNumber(ctx, e.state, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		return e.state.GetL2BlockHeaderByNumber(ctx, blockNumber, dbTx)
+	}
+
+	switch *number.number {
 	case LatestBlockNumber:
 		block, err := e.state.GetLastL2Block(ctx, dbTx)
 		if err != nil {
@@ -692,8 +832,15 @@ func (e *Eth) getBlockHeader(ctx context.Context, number BlockNumber, dbTx pgx.T
 		}
 		return header, nil
 
+	case SafeBlockNumber, FinalizedBlockNumber:
+		blockNumber, rpcErr := number.number.getNumericBlockNumber(ctx, e.state, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		return e.state.GetL2BlockHeaderByNumber(ctx, blockNumber, dbTx)
+
 	default:
-		return e.state.GetL2BlockHeaderByNumber(ctx, uint64(number), dbTx)
+		return e.state.GetL2BlockHeaderByNumber(ctx, uint64(*number.number), dbTx)
 	}
 }
 
@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/0xPolygonHermez/zkevm-node/encoding"
+	"github.com/0xPolygonHermez/zkevm-node/gasprice"
 	"github.com/0xPolygonHermez/zkevm-node/hex"
+	"github.com/0xPolygonHermez/zkevm-node/merkletree"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime"
 	"github.com/ethereum/go-ethereum"
@@ -143,7 +146,7 @@ func TestCall(t *testing.T) {
 						tx.Value().Uint64() == testCase.value.Uint64() &&
 						hex.EncodeToHex(tx.Data()) == hex.EncodeToHex(testCase.data)
 				})
-				m.State.On("ProcessUnsignedTransaction", context.Background(), txMatchBy, testCase.from, blockNumber, m.DbTx).Return(&runtime.ExecutionResult{ReturnValue: testCase.expectedResult}).Once()
+				m.State.On("ProcessUnsignedTransaction", context.Background(), txMatchBy, testCase.from, blockNumber, state.StateOverride(nil), m.DbTx).Return(&runtime.ExecutionResult{ReturnValue: testCase.expectedResult}).Once()
 			},
 		},
 		{
@@ -169,7 +172,7 @@ func TestCall(t *testing.T) {
 						tx.Value().Uint64() == testCase.value.Uint64() &&
 						hex.EncodeToHex(tx.Data()) == hex.EncodeToHex(testCase.data)
 				})
-				m.State.On("ProcessUnsignedTransaction", context.Background(), txMatchBy, testCase.from, blockNumber, m.DbTx).Return(&runtime.ExecutionResult{ReturnValue: testCase.expectedResult}).Once()
+				m.State.On("ProcessUnsignedTransaction", context.Background(), txMatchBy, testCase.from, blockNumber, state.StateOverride(nil), m.DbTx).Return(&runtime.ExecutionResult{ReturnValue: testCase.expectedResult}).Once()
 			},
 		},
 		{
@@ -197,7 +200,7 @@ func TestCall(t *testing.T) {
 					dataMatch := hex.EncodeToHex(tx.Data()) == hex.EncodeToHex(testCase.data)
 					return hasTx && gasMatch && toMatch && gasPriceMatch && valueMatch && dataMatch
 				})
-				m.State.On("ProcessUnsignedTransaction", context.Background(), txMatchBy, testCase.from, blockNumber, m.DbTx).Return(&runtime.ExecutionResult{ReturnValue: testCase.expectedResult}).Once()
+				m.State.On("ProcessUnsignedTransaction", context.Background(), txMatchBy, testCase.from, blockNumber, state.StateOverride(nil), m.DbTx).Return(&runtime.ExecutionResult{ReturnValue: testCase.expectedResult}).Once()
 			},
 		},
 		{
@@ -268,7 +271,7 @@ func TestCall(t *testing.T) {
 						tx.Value().Uint64() == testCase.value.Uint64() &&
 						hex.EncodeToHex(tx.Data()) == hex.EncodeToHex(testCase.data)
 				})
-				m.State.On("ProcessUnsignedTransaction", context.Background(), txMatchBy, testCase.from, blockNumber, m.DbTx).Return(&runtime.ExecutionResult{Err: errors.New("failed to process unsigned transaction")}).Once()
+				m.State.On("ProcessUnsignedTransaction", context.Background(), txMatchBy, testCase.from, blockNumber, state.StateOverride(nil), m.DbTx).Return(&runtime.ExecutionResult{Err: errors.New("failed to process unsigned transaction")}).Once()
 			},
 		},
 	}
@@ -294,6 +297,64 @@ func TestCall(t *testing.T) {
 	}
 }
 
+func TestCallWithStateOverride(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	to := common.HexToAddress("0x2")
+	overridden := common.HexToAddress("0x3")
+	slot := common.HexToHash("0x1")
+	slotValue := common.HexToHash("0x2a")
+	code := []byte{0x60, 0x00}
+	nonce := uint64(7)
+
+	params := []interface{}{
+		map[string]interface{}{"to": to.String(), "gas": "0x5208"},
+		"0x1",
+		map[string]interface{}{
+			overridden.String(): map[string]interface{}{
+				"nonce":     "0x7",
+				"balance":   "0x64",
+				"code":      hex.EncodeToHex(code),
+				"stateDiff": map[string]interface{}{slot.String(): slotValue.String()},
+			},
+		},
+	}
+
+	expectedStateOverride := state.StateOverride{
+		overridden: state.OverrideAccount{
+			Nonce:     &nonce,
+			Code:      &code,
+			Balance:   big.NewInt(100),
+			StateDiff: map[common.Hash]common.Hash{slot: slotValue},
+		},
+	}
+
+	m.DbTx.
+		On("Commit", context.Background()).
+		Return(nil).
+		Once()
+
+	m.State.
+		On("BeginStateTransaction", context.Background()).
+		Return(m.DbTx, nil).
+		Once()
+
+	m.State.
+		On("ProcessUnsignedTransaction", context.Background(), mock.IsType(&types.Transaction{}), common.Address{}, uint64(1), expectedStateOverride, m.DbTx).
+		Return(&runtime.ExecutionResult{ReturnValue: []byte("hello world")}).
+		Once()
+
+	res, err := s.JSONRPCCall("eth_call", params...)
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+
+	var result argBytes
+	err = json.Unmarshal(res.Result, &result)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello world"), []byte(result))
+}
+
 func TestChainID(t *testing.T) {
 	s, _, c := newSequencerMockedServer(t)
 	defer s.Stop()
@@ -354,8 +415,18 @@ func TestEstimateGas(t *testing.T) {
 					hex.EncodeToHex(tx.Data()) == hex.EncodeToHex(testCase.data)
 			})
 
+			m.DbTx.
+				On("Commit", context.Background()).
+				Return(nil).
+				Once()
+
 			m.State.
-				On("EstimateGas", txMatchBy, testCase.from).
+				On("BeginStateTransaction", context.Background()).
+				Return(m.DbTx, nil).
+				Once()
+
+			m.State.
+				On("EstimateGas", txMatchBy, testCase.from, (*uint64)(nil), state.StateOverride(nil), uint64(0), m.DbTx).
 				Return(testCase.expectedResult, nil).
 				Once()
 
@@ -367,6 +438,113 @@ func TestEstimateGas(t *testing.T) {
 	}
 }
 
+func TestEstimateGasByBlockNumber(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	type testCase struct {
+		Name           string
+		Params         []interface{}
+		ExpectedResult *uint64
+		ExpectedError  rpcError
+		SetupMocks     func(m *mocks, tc testCase)
+	}
+
+	to := common.HexToAddress("0x2")
+	revertErr := fmt.Errorf("%w: %s", runtime.ErrExecutionReverted, "not allowed")
+
+	testCases := []testCase{
+		{
+			Name:           "estimate gas for a specific block",
+			Params:         []interface{}{map[string]interface{}{"to": to.String()}, "0x5"},
+			ExpectedResult: ptrUint64(21000),
+			ExpectedError:  nil,
+			SetupMocks: func(m *mocks, tc testCase) {
+				m.DbTx.
+					On("Commit", context.Background()).
+					Return(nil).
+					Once()
+
+				m.State.
+					On("BeginStateTransaction", context.Background()).
+					Return(m.DbTx, nil).
+					Once()
+
+				m.State.
+					On("EstimateGas", mock.IsType(&types.Transaction{}), common.Address{}, ptrUint64(5), state.StateOverride(nil), uint64(0), m.DbTx).
+					Return(*tc.ExpectedResult, nil).
+					Once()
+			},
+		},
+		{
+			Name:           "estimate gas for the latest block",
+			Params:         []interface{}{map[string]interface{}{"to": to.String()}, "latest"},
+			ExpectedResult: ptrUint64(21000),
+			ExpectedError:  nil,
+			SetupMocks: func(m *mocks, tc testCase) {
+				m.DbTx.
+					On("Commit", context.Background()).
+					Return(nil).
+					Once()
+
+				m.State.
+					On("BeginStateTransaction", context.Background()).
+					Return(m.DbTx, nil).
+					Once()
+
+				m.State.
+					On("EstimateGas", mock.IsType(&types.Transaction{}), common.Address{}, (*uint64)(nil), state.StateOverride(nil), uint64(0), m.DbTx).
+					Return(*tc.ExpectedResult, nil).
+					Once()
+			},
+		},
+		{
+			Name:           "transaction reverted",
+			Params:         []interface{}{map[string]interface{}{"to": to.String()}, "0x5"},
+			ExpectedResult: nil,
+			ExpectedError:  newRPCError(defaultErrorCode, revertErr.Error()),
+			SetupMocks: func(m *mocks, tc testCase) {
+				m.DbTx.
+					On("Rollback", context.Background()).
+					Return(nil).
+					Once()
+
+				m.State.
+					On("BeginStateTransaction", context.Background()).
+					Return(m.DbTx, nil).
+					Once()
+
+				m.State.
+					On("EstimateGas", mock.IsType(&types.Transaction{}), common.Address{}, ptrUint64(5), state.StateOverride(nil), uint64(0), m.DbTx).
+					Return(uint64(0), revertErr).
+					Once()
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tc := testCase
+			tc.SetupMocks(m, tc)
+
+			res, err := s.JSONRPCCall("eth_estimateGas", tc.Params...)
+			require.NoError(t, err)
+
+			if res.Result != nil {
+				var result argUint64
+				err = json.Unmarshal(res.Result, &result)
+				require.NoError(t, err)
+				assert.Equal(t, *tc.ExpectedResult, uint64(result))
+			}
+
+			if res.Error != nil || tc.ExpectedError != nil {
+				assert.Equal(t, tc.ExpectedError.ErrorCode(), res.Error.Code)
+				assert.Equal(t, tc.ExpectedError.Error(), res.Error.Message)
+			}
+		})
+	}
+}
+
 func TestGasPrice(t *testing.T) {
 	s, m, c := newSequencerMockedServer(t)
 	defer s.Stop()
@@ -396,6 +574,87 @@ func TestGasPrice(t *testing.T) {
 	}
 }
 
+func TestFeeHistory(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	t.Run("fee history up to the latest block", func(t *testing.T) {
+		m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+		m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+		m.State.On("GetLastL2BlockNumber", context.Background(), m.DbTx).Return(uint64(10), nil).Once()
+		m.FeeHistoryOracle.
+			On("FeeHistory", context.Background(), uint64(2), uint64(10), []float64{25, 75}, m.DbTx).
+			Return(&gasprice.FeeHistoryResult{
+				OldestBlock:  9,
+				Reward:       [][]*big.Int{{big.NewInt(1), big.NewInt(2)}, {big.NewInt(0), big.NewInt(3)}},
+				BaseFee:      []*big.Int{big.NewInt(10), big.NewInt(10), big.NewInt(10)},
+				GasUsedRatio: []float64{0.5, 0.25},
+			}, nil).
+			Once()
+
+		res, err := s.JSONRPCCall("eth_feeHistory", "0x2", "latest", []float64{25, 75})
+		require.NoError(t, err)
+		require.Nil(t, res.Error)
+
+		var result rpcFeeHistory
+		require.NoError(t, json.Unmarshal(res.Result, &result))
+		assert.Equal(t, argUint64(9), result.OldestBlock)
+		require.Equal(t, 2, len(result.Reward))
+		assert.Equal(t, big.NewInt(3), (*big.Int)(&result.Reward[1][1]))
+		require.Equal(t, 3, len(result.BaseFee))
+		assert.Equal(t, big.NewInt(10), (*big.Int)(&result.BaseFee[2]))
+		assert.Equal(t, []float64{0.5, 0.25}, result.GasUsedRatio)
+	})
+
+	t.Run("request beyond head block", func(t *testing.T) {
+		m.DbTx.On("Rollback", context.Background()).Return(nil).Once()
+		m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+		m.State.On("GetLastL2BlockNumber", context.Background(), m.DbTx).Return(uint64(10), nil).Once()
+
+		res, err := s.JSONRPCCall("eth_feeHistory", "0x2", "0xb", []float64{})
+		require.NoError(t, err)
+		require.NotNil(t, res.Error)
+		assert.Equal(t, invalidParamsErrorCode, res.Error.Code)
+	})
+
+	t.Run("invalid reward percentiles", func(t *testing.T) {
+		m.DbTx.On("Rollback", context.Background()).Return(nil).Once()
+		m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+		m.State.On("GetLastL2BlockNumber", context.Background(), m.DbTx).Return(uint64(10), nil).Once()
+		m.FeeHistoryOracle.
+			On("FeeHistory", context.Background(), uint64(2), uint64(10), []float64{75, 25}, m.DbTx).
+			Return(nil, gasprice.ErrInvalidPercentile).
+			Once()
+
+		res, err := s.JSONRPCCall("eth_feeHistory", "0x2", "latest", []float64{75, 25})
+		require.NoError(t, err)
+		require.NotNil(t, res.Error)
+		assert.Equal(t, invalidParamsErrorCode, res.Error.Code)
+	})
+}
+
+func TestMaxPriorityFeePerGas(t *testing.T) {
+	s, m, c := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	m.FeeHistoryOracle.
+		On("SuggestTipCap", context.Background()).
+		Return(big.NewInt(5), nil).
+		Once()
+
+	tip, err := c.SuggestGasTipCap(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), tip)
+
+	m.FeeHistoryOracle.
+		On("SuggestTipCap", context.Background()).
+		Return(nil, errors.New("failed to get last l2 block number")).
+		Once()
+
+	_, err = c.SuggestGasTipCap(context.Background())
+	require.Error(t, err)
+}
+
 func TestGetBalance(t *testing.T) {
 	s, m, c := newSequencerMockedServer(t)
 	defer s.Stop()
@@ -542,6 +801,43 @@ func TestGetBalance(t *testing.T) {
 	}
 }
 
+func TestGetBalanceByBlockHash(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	addr := common.HexToAddress("0x123")
+	blockHash := common.HexToHash("0x29e885edaf8e4b51e1d2e05f9da28161d2fb4f6b1d53827d9b80a23cf2d7d9f1")
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(5)})
+
+	m.DbTx.
+		On("Commit", context.Background()).
+		Return(nil).
+		Once()
+
+	m.State.
+		On("BeginStateTransaction", context.Background()).
+		Return(m.DbTx, nil).
+		Once()
+
+	m.State.
+		On("GetL2BlockByHash", context.Background(), blockHash, m.DbTx).
+		Return(block, nil).
+		Once()
+
+	m.State.
+		On("GetBalance", context.Background(), addr, uint64(5), m.DbTx).
+		Return(big.NewInt(1000), nil).
+		Once()
+
+	res, err := s.JSONRPCCall("eth_getBalance", addr.String(), map[string]interface{}{"blockHash": blockHash.String(), "requireCanonical": true})
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+
+	var balance argBig
+	require.NoError(t, json.Unmarshal(res.Result, &balance))
+	assert.Equal(t, big.NewInt(1000), (*big.Int)(&balance))
+}
+
 func TestGetL2BlockByHash(t *testing.T) {
 	type testCase struct {
 		Name           string
@@ -828,6 +1124,82 @@ func TestGetL2BlockByNumber(t *testing.T) {
 					On("GetLastL2Block", context.Background(), m.DbTx).
 					Return(lastBlock, nil).
 					Once()
+
+				m.State.
+					On("GetLastBatchNumber", context.Background(), m.DbTx).
+					Return(uint64(1), nil).
+					Once()
+
+				m.State.
+					On("IsBatchClosed", context.Background(), uint64(1), m.DbTx).
+					Return(true, nil).
+					Once()
+			},
+		},
+		{
+			Name:           "get pending block with the txs of the open batch successfully",
+			Number:         big.NewInt(-1),
+			ExpectedResult: nil,
+			ExpectedError:  nil,
+			SetupMocks: func(m *mocks, tc *testCase) {
+				lastBlock := types.NewBlock(&types.Header{Number: big.NewInt(1)}, nil, nil, nil, &trie.StackTrie{})
+
+				privateKey, err := crypto.HexToECDSA(strings.TrimPrefix("0x28b2b0318721be8c8339199172cd7cc8f5e273800a35616ec893083a4b32c02e", "0x"))
+				require.NoError(t, err)
+				auth, err := bind.NewKeyedTransactorWithChainID(privateKey, big.NewInt(1))
+				require.NoError(t, err)
+				signedTx, err := auth.Signer(auth.From, types.NewTransaction(1, common.Address{}, big.NewInt(1), 1, big.NewInt(1), []byte{}))
+				require.NoError(t, err)
+				txs := []*types.Transaction{signedTx}
+
+				batch := &state.Batch{
+					BatchNumber: 2,
+					Coinbase:    common.HexToAddress("0x1"),
+					Timestamp:   time.Unix(1000, 0),
+				}
+
+				expectedResultHeader := &types.Header{
+					ParentHash: lastBlock.Hash(),
+					Number:     big.NewInt(2),
+					Coinbase:   batch.Coinbase,
+					Time:       uint64(batch.Timestamp.Unix()),
+				}
+				tc.ExpectedResult = types.NewBlock(expectedResultHeader, txs, nil, nil, &trie.StackTrie{})
+
+				m.DbTx.
+					On("Commit", context.Background()).
+					Return(nil).
+					Once()
+
+				m.State.
+					On("BeginStateTransaction", context.Background()).
+					Return(m.DbTx, nil).
+					Once()
+
+				m.State.
+					On("GetLastL2Block", context.Background(), m.DbTx).
+					Return(lastBlock, nil).
+					Once()
+
+				m.State.
+					On("GetLastBatchNumber", context.Background(), m.DbTx).
+					Return(batch.BatchNumber, nil).
+					Once()
+
+				m.State.
+					On("IsBatchClosed", context.Background(), batch.BatchNumber, m.DbTx).
+					Return(false, nil).
+					Once()
+
+				m.State.
+					On("GetBatchByNumber", context.Background(), batch.BatchNumber, m.DbTx).
+					Return(batch, nil).
+					Once()
+
+				m.State.
+					On("GetTxsByBatchNumber", context.Background(), batch.BatchNumber, m.DbTx).
+					Return(txs, nil).
+					Once()
 			},
 		},
 		{
@@ -1095,6 +1467,69 @@ func TestGetCode(t *testing.T) {
 	}
 }
 
+func TestGetProof(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	address := common.HexToAddress("0x1")
+	storageKey := common.HexToHash("0x2")
+	stateRoot := common.HexToHash("0x3")
+	siblings := [][]uint64{{1, 2, 3, 4, 5, 6, 7, 8}}
+
+	accountProof := &state.AccountProof{
+		Address:       address,
+		StateRoot:     stateRoot,
+		Balance:       big.NewInt(100),
+		BalanceProof:  &merkletree.Proof{Siblings: siblings},
+		Nonce:         big.NewInt(2),
+		NonceProof:    &merkletree.Proof{},
+		CodeHash:      common.HexToHash("0x4"),
+		CodeHashProof: &merkletree.Proof{},
+		StorageProof: []state.StorageProof{
+			{Key: storageKey, Value: big.NewInt(5), Proof: &merkletree.Proof{Siblings: siblings}},
+		},
+	}
+
+	expectedNode := argBytes(append(
+		common.FromHex("0x0000000000000001000000000000000200000000000000030000000000000004"),
+		common.FromHex("0x0000000000000005000000000000000600000000000000070000000000000008")...,
+	))
+
+	m.DbTx.
+		On("Commit", context.Background()).
+		Return(nil).
+		Once()
+
+	m.State.
+		On("BeginStateTransaction", context.Background()).
+		Return(m.DbTx, nil).
+		Once()
+
+	m.State.
+		On("GetProof", context.Background(), address, []common.Hash{storageKey}, uint64(1), m.DbTx).
+		Return(accountProof, nil).
+		Once()
+
+	res, err := s.JSONRPCCall("eth_getProof", address.String(), []string{storageKey.String()}, "0x1")
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+
+	var result rpcAccountProof
+	err = json.Unmarshal(res.Result, &result)
+	require.NoError(t, err)
+
+	assert.Equal(t, address, result.Address)
+	assert.Equal(t, []argBytes{expectedNode}, result.AccountProof)
+	assert.Equal(t, uint64(100), (*big.Int)(&result.Balance).Uint64())
+	assert.Equal(t, argUint64(2), result.Nonce)
+	assert.Equal(t, common.HexToHash("0x4"), result.CodeHash)
+	assert.Equal(t, stateRoot, result.StorageHash)
+	require.Len(t, result.StorageProof, 1)
+	assert.Equal(t, storageKey, result.StorageProof[0].Key)
+	assert.Equal(t, uint64(5), (*big.Int)(&result.StorageProof[0].Value).Uint64())
+	assert.Equal(t, []argBytes{expectedNode}, result.StorageProof[0].Proof)
+}
+
 func TestGetStorageAt(t *testing.T) {
 	s, m, c := newSequencerMockedServer(t)
 	defer s.Stop()
@@ -2195,6 +2630,76 @@ func TestGetTransactionCount(t *testing.T) {
 					Once()
 			},
 		},
+		{
+			Name:           "Count pending txs successfully",
+			Address:        common.HexToAddress("0x123").Hex(),
+			BlockNumber:    "pending",
+			ExpectedResult: uint(12),
+			ExpectedError:  nil,
+			SetupMocks: func(m *mocks, tc testCase) {
+				blockNumber := uint64(10)
+				address := common.HexToAddress(tc.Address)
+				m.DbTx.
+					On("Commit", context.Background()).
+					Return(nil).
+					Once()
+
+				m.State.
+					On("BeginStateTransaction", context.Background()).
+					Return(m.DbTx, nil).
+					Once()
+
+				m.State.
+					On("GetLastL2BlockNumber", context.Background(), m.DbTx).
+					Return(blockNumber, nil).
+					Once()
+
+				m.State.
+					On("GetNonce", context.Background(), address, blockNumber, m.DbTx).
+					Return(uint64(10), nil).
+					Once()
+
+				m.Pool.
+					On("GetPendingNonce", context.Background(), address, uint64(10)).
+					Return(uint64(12), nil).
+					Once()
+			},
+		},
+		{
+			Name:           "failed to count pending txs",
+			Address:        common.HexToAddress("0x123").Hex(),
+			BlockNumber:    "pending",
+			ExpectedResult: 0,
+			ExpectedError:  newRPCError(defaultErrorCode, "failed to count pending transactions"),
+			SetupMocks: func(m *mocks, tc testCase) {
+				blockNumber := uint64(10)
+				address := common.HexToAddress(tc.Address)
+				m.DbTx.
+					On("Rollback", context.Background()).
+					Return(nil).
+					Once()
+
+				m.State.
+					On("BeginStateTransaction", context.Background()).
+					Return(m.DbTx, nil).
+					Once()
+
+				m.State.
+					On("GetLastL2BlockNumber", context.Background(), m.DbTx).
+					Return(blockNumber, nil).
+					Once()
+
+				m.State.
+					On("GetNonce", context.Background(), address, blockNumber, m.DbTx).
+					Return(uint64(0), state.ErrNotFound).
+					Once()
+
+				m.Pool.
+					On("GetPendingNonce", context.Background(), address, uint64(0)).
+					Return(uint64(0), errors.New("failed to get pending nonce")).
+					Once()
+			},
+		},
 		{
 			Name:           "failed to get last block number",
 			Address:        common.HexToAddress("0x123").Hex(),
@@ -2,14 +2,26 @@ package jsonrpc
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/0xPolygonHermez/zkevm-node/hex"
 	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/jackc/pgx/v4"
 )
 
+const (
+	defaultTrustedReorgsLimit = 100
+	txsByAddressPageSize      = 100
+	tokenTransfersPageSize    = 100
+)
+
 // Hez contains implementations for the "hez" RPC endpoints
 type Hez struct {
+	pool  jsonRPCTxPool
 	state stateInterface
 	txMan dbTxManager
 }
@@ -27,3 +39,327 @@ func (h *Hez) ConsolidatedBlockNumber() (interface{}, rpcError) {
 		return hex.EncodeUint64(lastBlockNumber), nil
 	})
 }
+
+// VirtualBlockNumber returns current block number for virtual blocks, the
+// blocks whose batch has been sequenced on L1
+func (h *Hez) VirtualBlockNumber() (interface{}, rpcError) {
+	return h.txMan.NewDbTxScope(h.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		lastBlockNumber, err := h.state.GetLastVirtualL2BlockNumber(ctx, dbTx)
+		if err != nil {
+			return rpcErrorResponse(defaultErrorCode, "failed to get last virtual block number from state", err)
+		}
+
+		return hex.EncodeUint64(lastBlockNumber), nil
+	})
+}
+
+// BatchNumber returns the number of the last batch known by the node
+func (h *Hez) BatchNumber() (interface{}, rpcError) {
+	return h.txMan.NewDbTxScope(h.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		lastBatchNumber, err := h.state.GetLastBatchNumber(ctx, dbTx)
+		if err != nil {
+			return rpcErrorResponse(defaultErrorCode, "failed to get the last batch number from state", err)
+		}
+
+		return hex.EncodeUint64(lastBatchNumber), nil
+	})
+}
+
+// VirtualBatchNumber returns the number of the last batch sequenced on L1
+func (h *Hez) VirtualBatchNumber() (interface{}, rpcError) {
+	return h.txMan.NewDbTxScope(h.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		lastBatchNumber, err := h.state.GetLastVirtualBatchNum(ctx, dbTx)
+		if err != nil {
+			return rpcErrorResponse(defaultErrorCode, "failed to get the last virtual batch number from state", err)
+		}
+
+		return hex.EncodeUint64(lastBatchNumber), nil
+	})
+}
+
+// VerifiedBatchNumber returns the number of the last batch verified on L1
+func (h *Hez) VerifiedBatchNumber() (interface{}, rpcError) {
+	return h.txMan.NewDbTxScope(h.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		lastVerifiedBatch, err := h.state.GetLastVerifiedBatch(ctx, dbTx)
+		if errors.Is(err, state.ErrNotFound) {
+			return hex.EncodeUint64(0), nil
+		} else if err != nil {
+			return rpcErrorResponse(defaultErrorCode, "failed to get the last verified batch number from state", err)
+		}
+
+		return hex.EncodeUint64(lastVerifiedBatch.BatchNumber), nil
+	})
+}
+
+// GetBatchByNumber returns the batch with the given number, along with the
+// hashes of the L1 transactions that sequenced and verified it
+func (h *Hez) GetBatchByNumber(number BatchNumber, fullTx bool) (interface{}, rpcError) {
+	return h.txMan.NewDbTxScope(h.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		batchNumber, rpcErr := number.getNumericBatchNumber(ctx, h.state, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+
+		batch, err := h.state.GetBatchByNumber(ctx, batchNumber, dbTx)
+		if errors.Is(err, state.ErrNotFound) || errors.Is(err, state.ErrStateNotSynchronized) {
+			return nil, nil
+		} else if err != nil {
+			return rpcErrorResponse(defaultErrorCode, fmt.Sprintf("couldn't load batch from state by number %v", batchNumber), err)
+		}
+
+		virtualBatch, err := h.state.GetVirtualBatch(ctx, batchNumber, dbTx)
+		if err != nil && !errors.Is(err, state.ErrNotFound) {
+			return rpcErrorResponse(defaultErrorCode, fmt.Sprintf("couldn't load virtual batch from state by number %v", batchNumber), err)
+		}
+
+		verifiedBatch, err := h.state.GetVerifiedBatch(ctx, batchNumber, dbTx)
+		if err != nil && !errors.Is(err, state.ErrNotFound) {
+			return rpcErrorResponse(defaultErrorCode, fmt.Sprintf("couldn't load verified batch from state by number %v", batchNumber), err)
+		}
+
+		txHashes, err := h.state.GetTxsHashesByBatchNumber(ctx, batchNumber, dbTx)
+		if err != nil {
+			return rpcErrorResponse(defaultErrorCode, fmt.Sprintf("couldn't load batch txs from state by number %v", batchNumber), err)
+		}
+
+		txs := make([]rpcTransactionOrHash, 0, len(txHashes))
+		for _, txHash := range txHashes {
+			if !fullTx {
+				txs = append(txs, transactionHash(txHash))
+				continue
+			}
+
+			tx, err := h.state.GetTransactionByHash(ctx, txHash, dbTx)
+			if err != nil {
+				return rpcErrorResponse(defaultErrorCode, fmt.Sprintf("couldn't load tx %v from state", txHash.String()), err)
+			}
+			receipt, err := h.state.GetTransactionReceipt(ctx, txHash, dbTx)
+			if err != nil {
+				return rpcErrorResponse(defaultErrorCode, fmt.Sprintf("couldn't load receipt for tx %v from state", txHash.String()), err)
+			}
+			txs = append(txs, toRPCTransaction(tx, receipt.BlockNumber, receipt.BlockHash, uint64(receipt.TransactionIndex)))
+		}
+
+		return batchToRPCBatch(*batch, virtualBatch, verifiedBatch, txs), nil
+	})
+}
+
+// BatchNumberByBlockNumber returns the number of the batch containing the
+// given l2 block
+func (h *Hez) BatchNumberByBlockNumber(number BlockNumber) (interface{}, rpcError) {
+	return h.txMan.NewDbTxScope(h.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		blockNumber, rpcErr := number.getNumericBlockNumber(ctx, h.state, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+
+		batchNumber, err := h.state.GetBatchNumberOfL2Block(ctx, blockNumber, dbTx)
+		if errors.Is(err, state.ErrNotFound) {
+			return nil, nil
+		} else if err != nil {
+			return rpcErrorResponse(defaultErrorCode, fmt.Sprintf("failed to get batch number from block number %v", blockNumber), err)
+		}
+
+		return hex.EncodeUint64(batchNumber), nil
+	})
+}
+
+// IsBlockVirtualized returns true if the batch of the given l2 block has
+// been sequenced on L1
+func (h *Hez) IsBlockVirtualized(number BlockNumber) (interface{}, rpcError) {
+	return h.txMan.NewDbTxScope(h.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		blockNumber, rpcErr := number.getNumericBlockNumber(ctx, h.state, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+
+		isVirtualized, err := h.state.IsL2BlockVirtualized(ctx, blockNumber, dbTx)
+		if err != nil {
+			return rpcErrorResponse(defaultErrorCode, fmt.Sprintf("failed to check if the block %v is virtualized", blockNumber), err)
+		}
+
+		return isVirtualized, nil
+	})
+}
+
+// IsBlockConsolidated returns true if the batch of the given l2 block has
+// been verified on L1
+func (h *Hez) IsBlockConsolidated(number BlockNumber) (interface{}, rpcError) {
+	return h.txMan.NewDbTxScope(h.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		blockNumber, rpcErr := number.getNumericBlockNumber(ctx, h.state, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+
+		isConsolidated, err := h.state.IsL2BlockConsolidated(ctx, blockNumber, dbTx)
+		if err != nil {
+			return rpcErrorResponse(defaultErrorCode, fmt.Sprintf("failed to check if the block %v is consolidated", blockNumber), err)
+		}
+
+		return isConsolidated, nil
+	})
+}
+
+// GetTransactionStatus returns how far the tx with the given hash has gone on
+// its way to finality: its state in the pool, the trusted l2 block containing
+// it and the L1 txs that sequenced and verified its batch
+func (h *Hez) GetTransactionStatus(hash common.Hash) (interface{}, rpcError) {
+	return h.txMan.NewDbTxScope(h.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		var poolState *pool.TxState
+		txState, err := h.pool.GetTxState(ctx, hash)
+		if err == nil {
+			poolState = &txState
+		} else if !errors.Is(err, pool.ErrNotFound) {
+			return rpcErrorResponse(defaultErrorCode, "failed to get tx state from the pool", err)
+		}
+
+		status, err := h.state.GetTxStatus(ctx, hash, dbTx)
+		if errors.Is(err, state.ErrNotFound) {
+			status = nil
+		} else if err != nil {
+			return rpcErrorResponse(defaultErrorCode, "failed to get tx status from state", err)
+		}
+
+		if poolState == nil && status == nil {
+			return nil, nil
+		}
+
+		return txStatusToRPCTxStatus(hash, poolState, status), nil
+	})
+}
+
+// GetTransactionsByAddress returns a page of the txs sent by the given
+// address, sent to it or creating it as a contract, between the given l2
+// blocks. The range defaults to all the blocks. When there are more txs, the
+// returned cursor must be sent to get the next page
+func (h *Hez) GetTransactionsByAddress(address common.Address, fromBlock, toBlock *BlockNumber, cursor *argBytes) (interface{}, rpcError) {
+	return h.txMan.NewDbTxScope(h.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		from, to, after, rpcErr := h.getPageParams(ctx, fromBlock, toBlock, cursor, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+
+		var afterTx *state.TxPosition
+		if after != nil {
+			afterTx = &state.TxPosition{BlockNumber: after.BlockNumber, Index: after.Index}
+		}
+
+		txs, err := h.state.GetTxsByAddress(ctx, address, from, to, afterTx, txsByAddressPageSize, dbTx)
+		if err != nil {
+			return rpcErrorResponse(defaultErrorCode, fmt.Sprintf("failed to get txs of address %v from state", address.String()), err)
+		}
+
+		return txsInL2BlocksToRPCTxsPage(txs, txsByAddressPageSize), nil
+	})
+}
+
+// GetTokenTransfers returns a page of the Transfer and Approval events of
+// ERC-20 and ERC-721 tokens sent from or to the given address between the
+// given l2 blocks, as indexed by the token indexer. The range defaults to all
+// the blocks. When there are more events, the returned cursor must be sent to
+// get the next page
+func (h *Hez) GetTokenTransfers(address common.Address, fromBlock, toBlock *BlockNumber, cursor *argBytes) (interface{}, rpcError) {
+	return h.txMan.NewDbTxScope(h.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		from, to, after, rpcErr := h.getPageParams(ctx, fromBlock, toBlock, cursor, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+
+		var afterLog *state.LogPosition
+		if after != nil {
+			afterLog = &state.LogPosition{BlockNumber: after.BlockNumber, LogIndex: after.Index}
+		}
+
+		transfers, err := h.state.GetTokenTransfers(ctx, address, from, to, afterLog, tokenTransfersPageSize, dbTx)
+		if err != nil {
+			return rpcErrorResponse(defaultErrorCode, fmt.Sprintf("failed to get token transfers of address %v from state", address.String()), err)
+		}
+
+		return tokenTransfersToRPCTokenTransfersPage(transfers, tokenTransfersPageSize), nil
+	})
+}
+
+// getPageParams resolves the range of l2 blocks and the cursor of a paginated
+// request. The range defaults to all the blocks and a missing cursor requests
+// the first page
+func (h *Hez) getPageParams(ctx context.Context, fromBlock, toBlock *BlockNumber, cursor *argBytes, dbTx pgx.Tx) (uint64, uint64, *pagePosition, rpcError) {
+	from, rpcErr := fromBlock.getNumericBlockNumber(ctx, h.state, dbTx)
+	if rpcErr != nil {
+		return 0, 0, nil, rpcErr
+	}
+
+	if toBlock == nil {
+		latest := LatestBlockNumber
+		toBlock = &latest
+	}
+	to, rpcErr := toBlock.getNumericBlockNumber(ctx, h.state, dbTx)
+	if rpcErr != nil {
+		return 0, 0, nil, rpcErr
+	}
+
+	if cursor == nil {
+		return from, to, nil, nil
+	}
+	position, err := cursorToPagePosition(*cursor)
+	if err != nil {
+		return 0, 0, nil, newRPCError(invalidParamsErrorCode, err.Error())
+	}
+	return from, to, &position, nil
+}
+
+// GetTokenBalances returns the ERC-20 balances and the ERC-721 tokens of the
+// given address, computed from the token events indexed by the token indexer
+func (h *Hez) GetTokenBalances(address common.Address) (interface{}, rpcError) {
+	return h.txMan.NewDbTxScope(h.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		balances, ownerships, err := h.state.GetTokenBalances(ctx, address, dbTx)
+		if err != nil {
+			return rpcErrorResponse(defaultErrorCode, fmt.Sprintf("failed to get token balances of address %v from state", address.String()), err)
+		}
+
+		return tokenBalancesToRPCTokenBalances(balances, ownerships), nil
+	})
+}
+
+// GetTrustedReorgs returns the reports of the divergences found between the
+// trusted state and the virtual state, starting at the given batch number
+func (h *Hez) GetTrustedReorgs(fromBatchNumber argUint64, limit *argUint64) (interface{}, rpcError) {
+	return h.txMan.NewDbTxScope(h.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		l := uint64(defaultTrustedReorgsLimit)
+		if limit != nil && uint64(*limit) < l {
+			l = uint64(*limit)
+		}
+
+		reorgs, err := h.state.GetTrustedReorgs(ctx, uint64(fromBatchNumber), l, dbTx)
+		if err != nil {
+			return rpcErrorResponse(defaultErrorCode, "failed to get trusted reorgs from state", err)
+		}
+
+		result := make([]rpcTrustedReorg, 0, len(reorgs))
+		for _, reorg := range reorgs {
+			result = append(result, trustedReorgToRPCTrustedReorg(reorg))
+		}
+
+		return result, nil
+	})
+}
+
+// GetProof returns the account values and the given storage positions of an
+// address, along with the sparse merkle tree proof of each of them, including
+// the siblings of the path from the state root to the leaf
+func (h *Hez) GetProof(address common.Address, storageKeys []common.Hash, number *BlockNumber) (interface{}, rpcError) {
+	return h.txMan.NewDbTxScope(h.state, func(ctx context.Context, dbTx pgx.Tx) (interface{}, rpcError) {
+		blockNumber, rpcErr := number.getNumericBlockNumber(ctx, h.state, dbTx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+
+		proof, err := h.state.GetProof(ctx, address, storageKeys, blockNumber, dbTx)
+		if errors.Is(err, state.ErrNotFound) {
+			return nil, nil
+		} else if err != nil {
+			return rpcErrorResponse(defaultErrorCode, "failed to get proof from state", err)
+		}
+
+		return accountProofToRPCZKAccountProof(*proof), nil
+	})
+}
@@ -4,8 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"math/big"
 	"testing"
+	"time"
 
+	"github.com/0xPolygonHermez/zkevm-node/hex"
+	"github.com/0xPolygonHermez/zkevm-node/merkletree"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -88,6 +96,882 @@ func TestConsolidatedBlockNumber(t *testing.T) {
 	}
 }
 
+func TestVirtualBlockNumber(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	type testCase struct {
+		Name           string
+		ExpectedResult *uint64
+		ExpectedError  rpcError
+		SetupMocks     func(m *mocks)
+	}
+
+	testCases := []testCase{
+		{
+			Name:           "Get virtual block number successfully",
+			ExpectedResult: ptrUint64(10),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.
+					On("Commit", context.Background()).
+					Return(nil).
+					Once()
+
+				m.State.
+					On("BeginStateTransaction", context.Background()).
+					Return(m.DbTx, nil).
+					Once()
+
+				m.State.
+					On("GetLastVirtualL2BlockNumber", context.Background(), m.DbTx).
+					Return(uint64(10), nil).
+					Once()
+			},
+		},
+		{
+			Name:           "failed to get virtual block number",
+			ExpectedResult: nil,
+			ExpectedError:  newRPCError(defaultErrorCode, "failed to get last virtual block number from state"),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.
+					On("Rollback", context.Background()).
+					Return(nil).
+					Once()
+
+				m.State.
+					On("BeginStateTransaction", context.Background()).
+					Return(m.DbTx, nil).
+					Once()
+
+				m.State.
+					On("GetLastVirtualL2BlockNumber", context.Background(), m.DbTx).
+					Return(uint64(0), errors.New("failed to get last virtual block number")).
+					Once()
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tc := testCase
+			tc.SetupMocks(m)
+
+			res, err := s.JSONRPCCall("hez_virtualBlockNumber")
+			require.NoError(t, err)
+
+			if res.Result != nil {
+				var result argUint64
+				err = json.Unmarshal(res.Result, &result)
+				require.NoError(t, err)
+				assert.Equal(t, *tc.ExpectedResult, uint64(result))
+			}
+
+			if res.Error != nil || tc.ExpectedError != nil {
+				assert.Equal(t, tc.ExpectedError.ErrorCode(), res.Error.Code)
+				assert.Equal(t, tc.ExpectedError.Error(), res.Error.Message)
+			}
+		})
+	}
+}
+
+func TestGetTrustedReorgs(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	type testCase struct {
+		Name           string
+		Params         []interface{}
+		ExpectedResult []rpcTrustedReorg
+		ExpectedError  rpcError
+		SetupMocks     func(m *mocks)
+	}
+
+	reportedAt := time.Unix(1660000000, 0)
+	reorg := state.TrustedReorg{
+		ID:              1,
+		BatchNumber:     10,
+		BlockNumber:     100,
+		DivergentFields: []string{state.TrustedReorgFieldGlobalExitRoot, state.TrustedReorgFieldCoinbase},
+		OldStateRoot:    common.HexToHash("0x1"),
+		NewStateRoot:    common.HexToHash("0x2"),
+		L2BlockNumbers:  []uint64{20, 21},
+		TxHashes:        []common.Hash{common.HexToHash("0x3"), common.HexToHash("0x4")},
+		ReportedAt:      reportedAt,
+	}
+
+	testCases := []testCase{
+		{
+			Name:           "Get trusted reorgs successfully",
+			Params:         []interface{}{"0xa"},
+			ExpectedResult: []rpcTrustedReorg{trustedReorgToRPCTrustedReorg(reorg)},
+			SetupMocks: func(m *mocks) {
+				m.DbTx.
+					On("Commit", context.Background()).
+					Return(nil).
+					Once()
+
+				m.State.
+					On("BeginStateTransaction", context.Background()).
+					Return(m.DbTx, nil).
+					Once()
+
+				m.State.
+					On("GetTrustedReorgs", context.Background(), uint64(10), uint64(defaultTrustedReorgsLimit), m.DbTx).
+					Return([]state.TrustedReorg{reorg}, nil).
+					Once()
+			},
+		},
+		{
+			Name:           "Get trusted reorgs with limit",
+			Params:         []interface{}{"0xa", "0x5"},
+			ExpectedResult: []rpcTrustedReorg{},
+			SetupMocks: func(m *mocks) {
+				m.DbTx.
+					On("Commit", context.Background()).
+					Return(nil).
+					Once()
+
+				m.State.
+					On("BeginStateTransaction", context.Background()).
+					Return(m.DbTx, nil).
+					Once()
+
+				m.State.
+					On("GetTrustedReorgs", context.Background(), uint64(10), uint64(5), m.DbTx).
+					Return([]state.TrustedReorg{}, nil).
+					Once()
+			},
+		},
+		{
+			Name:           "failed to get trusted reorgs",
+			Params:         []interface{}{"0xa"},
+			ExpectedResult: nil,
+			ExpectedError:  newRPCError(defaultErrorCode, "failed to get trusted reorgs from state"),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.
+					On("Rollback", context.Background()).
+					Return(nil).
+					Once()
+
+				m.State.
+					On("BeginStateTransaction", context.Background()).
+					Return(m.DbTx, nil).
+					Once()
+
+				m.State.
+					On("GetTrustedReorgs", context.Background(), uint64(10), uint64(defaultTrustedReorgsLimit), m.DbTx).
+					Return(nil, errors.New("failed to get trusted reorgs")).
+					Once()
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tc := testCase
+			tc.SetupMocks(m)
+
+			res, err := s.JSONRPCCall("hez_getTrustedReorgs", tc.Params...)
+			require.NoError(t, err)
+
+			if res.Result != nil {
+				var result []rpcTrustedReorg
+				err = json.Unmarshal(res.Result, &result)
+				require.NoError(t, err)
+				assert.Equal(t, tc.ExpectedResult, result)
+			}
+
+			if res.Error != nil || tc.ExpectedError != nil {
+				assert.Equal(t, tc.ExpectedError.ErrorCode(), res.Error.Code)
+				assert.Equal(t, tc.ExpectedError.Error(), res.Error.Message)
+			}
+		})
+	}
+}
+
 func ptrUint64(n uint64) *uint64 {
 	return &n
 }
+
+func TestGetZKProof(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	address := common.HexToAddress("0x1")
+	storageKey := common.HexToHash("0x2")
+	stateRoot := common.HexToHash("0x3")
+	insKey := []uint64{4, 0, 0, 0}
+
+	accountProof := &state.AccountProof{
+		Address:   address,
+		StateRoot: stateRoot,
+		Balance:   big.NewInt(0),
+		BalanceProof: &merkletree.Proof{
+			Key:      []uint64{1, 0, 0, 0},
+			Siblings: [][]uint64{{1, 2, 3, 4, 5, 6, 7, 8}},
+			InsKey:   insKey,
+			InsValue: []uint64{9, 0, 0, 0, 0, 0, 0, 0},
+		},
+		Nonce:         big.NewInt(0),
+		NonceProof:    &merkletree.Proof{Key: []uint64{2, 0, 0, 0}, IsOld0: true},
+		CodeHash:      common.Hash{},
+		CodeHashProof: &merkletree.Proof{Key: []uint64{3, 0, 0, 0}, IsOld0: true},
+		StorageProof: []state.StorageProof{
+			{Key: storageKey, Value: big.NewInt(5), Proof: &merkletree.Proof{Key: []uint64{5, 0, 0, 0}, Value: []uint64{5, 0, 0, 0, 0, 0, 0, 0}}},
+		},
+	}
+
+	m.DbTx.
+		On("Commit", context.Background()).
+		Return(nil).
+		Once()
+
+	m.State.
+		On("BeginStateTransaction", context.Background()).
+		Return(m.DbTx, nil).
+		Once()
+
+	m.State.
+		On("GetLastL2BlockNumber", context.Background(), m.DbTx).
+		Return(uint64(10), nil).
+		Once()
+
+	m.State.
+		On("GetProof", context.Background(), address, []common.Hash{storageKey}, uint64(10), m.DbTx).
+		Return(accountProof, nil).
+		Once()
+
+	res, err := s.JSONRPCCall("hez_getProof", address.String(), []string{storageKey.String()}, "latest")
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+
+	var result rpcZKAccountProof
+	err = json.Unmarshal(res.Result, &result)
+	require.NoError(t, err)
+
+	assert.Equal(t, address, result.Address)
+	assert.Equal(t, stateRoot, result.StateRoot)
+	assert.Equal(t, common.HexToHash("0x1"), result.Balance.Key)
+	assert.Equal(t, [][]argUint64{{1, 2, 3, 4, 5, 6, 7, 8}}, result.Balance.Siblings)
+	require.NotNil(t, result.Balance.InsKey)
+	assert.Equal(t, common.HexToHash("0x4"), *result.Balance.InsKey)
+	require.NotNil(t, result.Balance.InsValue)
+	assert.Equal(t, uint64(9), (*big.Int)(result.Balance.InsValue).Uint64())
+	assert.False(t, result.Balance.IsOld0)
+	assert.True(t, result.Nonce.IsOld0)
+	assert.Nil(t, result.Nonce.InsKey)
+	require.Len(t, result.Storage, 1)
+	assert.Equal(t, storageKey, result.Storage[0].Key)
+	assert.Equal(t, common.HexToHash("0x5"), result.Storage[0].rpcSMTProof.Key)
+	assert.Equal(t, uint64(5), (*big.Int)(&result.Storage[0].Value).Uint64())
+}
+
+func TestBatchNumbers(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	type testCase struct {
+		Name           string
+		Method         string
+		ExpectedResult *uint64
+		ExpectedError  rpcError
+		SetupMocks     func(m *mocks)
+	}
+
+	testCases := []testCase{
+		{
+			Name:           "Get batch number successfully",
+			Method:         "hez_batchNumber",
+			ExpectedResult: ptrUint64(10),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetLastBatchNumber", context.Background(), m.DbTx).Return(uint64(10), nil).Once()
+			},
+		},
+		{
+			Name:          "failed to get batch number",
+			Method:        "hez_batchNumber",
+			ExpectedError: newRPCError(defaultErrorCode, "failed to get the last batch number from state"),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Rollback", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetLastBatchNumber", context.Background(), m.DbTx).Return(uint64(0), errors.New("failed to get last batch number")).Once()
+			},
+		},
+		{
+			Name:           "Get virtual batch number successfully",
+			Method:         "hez_virtualBatchNumber",
+			ExpectedResult: ptrUint64(9),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetLastVirtualBatchNum", context.Background(), m.DbTx).Return(uint64(9), nil).Once()
+			},
+		},
+		{
+			Name:           "Get verified batch number successfully",
+			Method:         "hez_verifiedBatchNumber",
+			ExpectedResult: ptrUint64(8),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetLastVerifiedBatch", context.Background(), m.DbTx).Return(&state.VerifiedBatch{BatchNumber: 8}, nil).Once()
+			},
+		},
+		{
+			Name:           "Get verified batch number without verified batches",
+			Method:         "hez_verifiedBatchNumber",
+			ExpectedResult: ptrUint64(0),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetLastVerifiedBatch", context.Background(), m.DbTx).Return(nil, state.ErrNotFound).Once()
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tc := testCase
+			tc.SetupMocks(m)
+
+			res, err := s.JSONRPCCall(tc.Method)
+			require.NoError(t, err)
+
+			if res.Result != nil {
+				var result argUint64
+				err = json.Unmarshal(res.Result, &result)
+				require.NoError(t, err)
+				assert.Equal(t, *tc.ExpectedResult, uint64(result))
+			}
+
+			if res.Error != nil || tc.ExpectedError != nil {
+				assert.Equal(t, tc.ExpectedError.ErrorCode(), res.Error.Code)
+				assert.Equal(t, tc.ExpectedError.Error(), res.Error.Message)
+			}
+		})
+	}
+}
+
+func TestGetBatchByNumber(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	tx := types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(2), 21000, big.NewInt(3), nil)
+	blockHash := common.HexToHash("0x4")
+	batch := &state.Batch{
+		BatchNumber:    10,
+		Coinbase:       common.HexToAddress("0x5"),
+		StateRoot:      common.HexToHash("0x6"),
+		GlobalExitRoot: common.HexToHash("0x7"),
+		LocalExitRoot:  common.HexToHash("0x8"),
+		Timestamp:      time.Unix(1660000000, 0),
+	}
+	virtualBatch := &state.VirtualBatch{BatchNumber: 10, TxHash: common.HexToHash("0x9")}
+
+	type testCase struct {
+		Name           string
+		Params         []interface{}
+		ExpectedResult *rpcBatch
+		ExpectedError  rpcError
+		SetupMocks     func(m *mocks)
+	}
+
+	testCases := []testCase{
+		{
+			Name:           "Get virtual batch with tx hashes",
+			Params:         []interface{}{"0xa", false},
+			ExpectedResult: batchToRPCBatch(*batch, virtualBatch, nil, nil),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetBatchByNumber", context.Background(), uint64(10), m.DbTx).Return(batch, nil).Once()
+				m.State.On("GetVirtualBatch", context.Background(), uint64(10), m.DbTx).Return(virtualBatch, nil).Once()
+				m.State.On("GetVerifiedBatch", context.Background(), uint64(10), m.DbTx).Return(nil, state.ErrNotFound).Once()
+				m.State.On("GetTxsHashesByBatchNumber", context.Background(), uint64(10), m.DbTx).Return([]common.Hash{tx.Hash()}, nil).Once()
+			},
+		},
+		{
+			Name:           "Get latest batch with full txs",
+			Params:         []interface{}{"latest", true},
+			ExpectedResult: batchToRPCBatch(*batch, nil, nil, nil),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetLastBatchNumber", context.Background(), m.DbTx).Return(uint64(10), nil).Once()
+				m.State.On("GetBatchByNumber", context.Background(), uint64(10), m.DbTx).Return(batch, nil).Once()
+				m.State.On("GetVirtualBatch", context.Background(), uint64(10), m.DbTx).Return(nil, state.ErrNotFound).Once()
+				m.State.On("GetVerifiedBatch", context.Background(), uint64(10), m.DbTx).Return(nil, state.ErrNotFound).Once()
+				m.State.On("GetTxsHashesByBatchNumber", context.Background(), uint64(10), m.DbTx).Return([]common.Hash{tx.Hash()}, nil).Once()
+				m.State.On("GetTransactionByHash", context.Background(), tx.Hash(), m.DbTx).Return(tx, nil).Once()
+				m.State.
+					On("GetTransactionReceipt", context.Background(), tx.Hash(), m.DbTx).
+					Return(&types.Receipt{BlockNumber: big.NewInt(20), BlockHash: blockHash, TransactionIndex: 0}, nil).
+					Once()
+			},
+		},
+		{
+			Name:           "Batch not found",
+			Params:         []interface{}{"0xb", false},
+			ExpectedResult: nil,
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetBatchByNumber", context.Background(), uint64(11), m.DbTx).Return(nil, state.ErrStateNotSynchronized).Once()
+			},
+		},
+		{
+			Name:          "failed to get batch",
+			Params:        []interface{}{"0xa", false},
+			ExpectedError: newRPCError(defaultErrorCode, "couldn't load batch from state by number 10"),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Rollback", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetBatchByNumber", context.Background(), uint64(10), m.DbTx).Return(nil, errors.New("failed to get batch")).Once()
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tc := testCase
+			tc.SetupMocks(m)
+
+			res, err := s.JSONRPCCall("hez_getBatchByNumber", tc.Params...)
+			require.NoError(t, err)
+
+			if res.Error != nil || tc.ExpectedError != nil {
+				assert.Equal(t, tc.ExpectedError.ErrorCode(), res.Error.Code)
+				assert.Equal(t, tc.ExpectedError.Error(), res.Error.Message)
+				return
+			}
+
+			if tc.ExpectedResult == nil {
+				assert.Equal(t, "null", string(res.Result))
+				return
+			}
+
+			var result map[string]interface{}
+			err = json.Unmarshal(res.Result, &result)
+			require.NoError(t, err)
+
+			assert.Equal(t, "0xa", result["number"])
+			assert.Equal(t, tc.ExpectedResult.Coinbase.String(), common.HexToAddress(result["coinbase"].(string)).String())
+			assert.Equal(t, tc.ExpectedResult.StateRoot.String(), result["stateRoot"])
+			assert.Equal(t, tc.ExpectedResult.GlobalExitRoot.String(), result["globalExitRoot"])
+			assert.Equal(t, tc.ExpectedResult.LocalExitRoot.String(), result["localExitRoot"])
+			if tc.ExpectedResult.SendSequencesTxHash != nil {
+				assert.Equal(t, tc.ExpectedResult.SendSequencesTxHash.String(), result["sendSequencesTxHash"])
+			} else {
+				assert.Nil(t, result["sendSequencesTxHash"])
+			}
+			assert.Nil(t, result["verifyBatchTxHash"])
+
+			txs := result["transactions"].([]interface{})
+			require.Len(t, txs, 1)
+			if fullTx := tc.Params[1].(bool); fullTx {
+				rpcTx := txs[0].(map[string]interface{})
+				assert.Equal(t, tx.Hash().String(), rpcTx["hash"])
+				assert.Equal(t, blockHash.String(), rpcTx["blockHash"])
+				assert.Equal(t, "0x14", rpcTx["blockNumber"])
+			} else {
+				assert.Equal(t, tx.Hash().String(), txs[0])
+			}
+		})
+	}
+}
+
+func TestBatchNumberByBlockNumber(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	m.DbTx.On("Commit", context.Background()).Return(nil).Twice()
+	m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Twice()
+	m.State.On("GetBatchNumberOfL2Block", context.Background(), uint64(20), m.DbTx).Return(uint64(10), nil).Once()
+	m.State.On("GetBatchNumberOfL2Block", context.Background(), uint64(21), m.DbTx).Return(uint64(0), state.ErrNotFound).Once()
+
+	res, err := s.JSONRPCCall("hez_batchNumberByBlockNumber", "0x14")
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	var result argUint64
+	require.NoError(t, json.Unmarshal(res.Result, &result))
+	assert.Equal(t, uint64(10), uint64(result))
+
+	res, err = s.JSONRPCCall("hez_batchNumberByBlockNumber", "0x15")
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	assert.Equal(t, "null", string(res.Result))
+}
+
+func TestIsBlockVirtualizedAndConsolidated(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	type testCase struct {
+		Name           string
+		Method         string
+		ExpectedResult bool
+		ExpectedError  rpcError
+		SetupMocks     func(m *mocks)
+	}
+
+	testCases := []testCase{
+		{
+			Name:           "Block virtualized",
+			Method:         "hez_isBlockVirtualized",
+			ExpectedResult: true,
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("IsL2BlockVirtualized", context.Background(), uint64(20), m.DbTx).Return(true, nil).Once()
+			},
+		},
+		{
+			Name:           "Block not consolidated",
+			Method:         "hez_isBlockConsolidated",
+			ExpectedResult: false,
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("IsL2BlockConsolidated", context.Background(), uint64(20), m.DbTx).Return(false, nil).Once()
+			},
+		},
+		{
+			Name:          "failed to check if the block is consolidated",
+			Method:        "hez_isBlockConsolidated",
+			ExpectedError: newRPCError(defaultErrorCode, "failed to check if the block 20 is consolidated"),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Rollback", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("IsL2BlockConsolidated", context.Background(), uint64(20), m.DbTx).Return(false, errors.New("failed to check")).Once()
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tc := testCase
+			tc.SetupMocks(m)
+
+			res, err := s.JSONRPCCall(tc.Method, "0x14")
+			require.NoError(t, err)
+
+			if res.Error != nil || tc.ExpectedError != nil {
+				assert.Equal(t, tc.ExpectedError.ErrorCode(), res.Error.Code)
+				assert.Equal(t, tc.ExpectedError.Error(), res.Error.Message)
+				return
+			}
+
+			var result bool
+			require.NoError(t, json.Unmarshal(res.Result, &result))
+			assert.Equal(t, tc.ExpectedResult, result)
+		})
+	}
+}
+
+func TestGetTransactionStatus(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	hash := common.HexToHash("0x123")
+	selected := pool.TxStateSelected
+
+	type testCase struct {
+		Name           string
+		ExpectedResult *rpcTxStatus
+		ExpectedError  rpcError
+		SetupMocks     func(m *mocks)
+	}
+
+	testCases := []testCase{
+		{
+			Name: "Tx verified on L1",
+			ExpectedResult: &rpcTxStatus{
+				Hash:          hash,
+				PoolState:     (*string)(&selected),
+				BlockNumber:   ptrArgUint64(10),
+				BlockHash:     &common.Hash{0x1},
+				BatchNumber:   ptrArgUint64(5),
+				VirtualBatch:  &rpcL1BatchAction{BatchNumber: 5, L1TxHash: common.Hash{0x2}, L1BlockNumber: 100},
+				VerifiedBatch: &rpcL1BatchAction{BatchNumber: 5, L1TxHash: common.Hash{0x3}, L1BlockNumber: 110},
+			},
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.Pool.On("GetTxState", context.Background(), hash).Return(selected, nil).Once()
+				m.State.On("GetTxStatus", context.Background(), hash, m.DbTx).Return(&state.TxStatus{
+					L2BlockNumber: 10,
+					L2BlockHash:   common.Hash{0x1},
+					BatchNumber:   5,
+					VirtualBatch:  &state.VirtualBatch{BatchNumber: 5, TxHash: common.Hash{0x2}, BlockNumber: 100},
+					VerifiedBatch: &state.VerifiedBatch{BatchNumber: 5, TxHash: common.Hash{0x3}, BlockNumber: 110},
+				}, nil).Once()
+			},
+		},
+		{
+			Name: "Tx in a trusted block",
+			ExpectedResult: &rpcTxStatus{
+				Hash:        hash,
+				BlockNumber: ptrArgUint64(10),
+				BlockHash:   &common.Hash{0x1},
+				BatchNumber: ptrArgUint64(5),
+			},
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.Pool.On("GetTxState", context.Background(), hash).Return(pool.TxState(""), pool.ErrNotFound).Once()
+				m.State.On("GetTxStatus", context.Background(), hash, m.DbTx).Return(&state.TxStatus{
+					L2BlockNumber: 10,
+					L2BlockHash:   common.Hash{0x1},
+					BatchNumber:   5,
+				}, nil).Once()
+			},
+		},
+		{
+			Name:           "Tx not found",
+			ExpectedResult: nil,
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.Pool.On("GetTxState", context.Background(), hash).Return(pool.TxState(""), pool.ErrNotFound).Once()
+				m.State.On("GetTxStatus", context.Background(), hash, m.DbTx).Return(nil, state.ErrNotFound).Once()
+			},
+		},
+		{
+			Name:          "failed to get tx status from state",
+			ExpectedError: newRPCError(defaultErrorCode, "failed to get tx status from state"),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Rollback", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.Pool.On("GetTxState", context.Background(), hash).Return(pool.TxStatePending, nil).Once()
+				m.State.On("GetTxStatus", context.Background(), hash, m.DbTx).Return(nil, errors.New("failed to get status")).Once()
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tc := testCase
+			tc.SetupMocks(m)
+
+			res, err := s.JSONRPCCall("hez_getTransactionStatus", hash.String())
+			require.NoError(t, err)
+
+			if res.Error != nil || tc.ExpectedError != nil {
+				assert.Equal(t, tc.ExpectedError.ErrorCode(), res.Error.Code)
+				assert.Equal(t, tc.ExpectedError.Error(), res.Error.Message)
+				return
+			}
+
+			var result *rpcTxStatus
+			require.NoError(t, json.Unmarshal(res.Result, &result))
+			assert.Equal(t, tc.ExpectedResult, result)
+		})
+	}
+}
+
+func ptrArgUint64(n uint64) *argUint64 {
+	a := argUint64(n)
+	return &a
+}
+
+func TestGetTransactionsByAddress(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	address := common.HexToAddress("0x123")
+	blockHash := common.HexToHash("0x456")
+
+	txsInBlocks := func(n int) []state.TxInL2Block {
+		txs := make([]state.TxInL2Block, 0, n)
+		for i := 0; i < n; i++ {
+			txs = append(txs, state.TxInL2Block{
+				TxPosition: state.TxPosition{BlockNumber: uint64(i + 1)},
+				Tx:         types.NewTransaction(uint64(i), address, big.NewInt(1), 21000, big.NewInt(1), nil), //nolint:gomnd
+				BlockHash:  blockHash,
+			})
+		}
+		return txs
+	}
+
+	type testCase struct {
+		Name           string
+		Params         []interface{}
+		ExpectedTxs    int
+		ExpectedCursor *argBytes
+		ExpectedError  rpcError
+		SetupMocks     func(m *mocks)
+	}
+
+	cursor := pagePositionToCursor(pagePosition{BlockNumber: 100, Index: 0})
+
+	testCases := []testCase{
+		{
+			Name:        "Get all the txs of the address",
+			Params:      []interface{}{address.String()},
+			ExpectedTxs: 2, //nolint:gomnd
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetLastL2BlockNumber", context.Background(), m.DbTx).Return(uint64(20), nil).Once()
+				m.State.On("GetTxsByAddress", context.Background(), address, uint64(0), uint64(20), (*state.TxPosition)(nil), uint64(txsByAddressPageSize), m.DbTx).
+					Return(txsInBlocks(2), nil).Once() //nolint:gomnd
+			},
+		},
+		{
+			Name:           "Get a full page of txs in a range",
+			Params:         []interface{}{address.String(), "0x1", "0x200", hex.EncodeToHex(pagePositionToCursor(pagePosition{BlockNumber: 1, Index: 0}))},
+			ExpectedTxs:    txsByAddressPageSize,
+			ExpectedCursor: &cursor,
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetTxsByAddress", context.Background(), address, uint64(1), uint64(512), &state.TxPosition{BlockNumber: 1, Index: 0}, uint64(txsByAddressPageSize), m.DbTx).
+					Return(txsInBlocks(txsByAddressPageSize), nil).Once()
+			},
+		},
+		{
+			Name:          "Invalid cursor",
+			Params:        []interface{}{address.String(), "0x1", "0x200", "0x1234"},
+			ExpectedError: newRPCError(invalidParamsErrorCode, "invalid cursor length 2, expected 16"),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Rollback", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+			},
+		},
+		{
+			Name:          "failed to get the txs",
+			Params:        []interface{}{address.String(), "0x1", "0x200"},
+			ExpectedError: newRPCError(defaultErrorCode, "failed to get txs of address 0x0000000000000000000000000000000000000123 from state"),
+			SetupMocks: func(m *mocks) {
+				m.DbTx.On("Rollback", context.Background()).Return(nil).Once()
+				m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+				m.State.On("GetTxsByAddress", context.Background(), address, uint64(1), uint64(512), (*state.TxPosition)(nil), uint64(txsByAddressPageSize), m.DbTx).
+					Return(nil, errors.New("failed to get txs")).Once()
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tc := testCase
+			tc.SetupMocks(m)
+
+			res, err := s.JSONRPCCall("hez_getTransactionsByAddress", tc.Params...)
+			require.NoError(t, err)
+
+			if res.Error != nil || tc.ExpectedError != nil {
+				assert.Equal(t, tc.ExpectedError.ErrorCode(), res.Error.Code)
+				assert.Equal(t, tc.ExpectedError.Error(), res.Error.Message)
+				return
+			}
+
+			var result rpcTxsPage
+			require.NoError(t, json.Unmarshal(res.Result, &result))
+			assert.Equal(t, tc.ExpectedTxs, len(result.Transactions))
+			assert.Equal(t, tc.ExpectedCursor, result.Cursor)
+			for _, tx := range result.Transactions {
+				assert.Equal(t, blockHash, tx.BlockHash)
+			}
+		})
+	}
+}
+
+func TestGetTokenTransfers(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	address := common.HexToAddress("0x123")
+	transfer := state.TokenTransfer{
+		LogPosition: state.LogPosition{BlockNumber: 3, LogIndex: 2},
+		TxHash:      common.HexToHash("0x1"),
+		Token:       common.HexToAddress("0x456"),
+		TokenType:   state.TokenTypeERC20,
+		EventType:   state.TokenEventTransfer,
+		From:        address,
+		To:          common.HexToAddress("0x789"),
+		Value:       big.NewInt(100),
+	}
+
+	t.Run("Get the token transfers of the address", func(t *testing.T) {
+		m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+		m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+		m.State.On("GetLastL2BlockNumber", context.Background(), m.DbTx).Return(uint64(20), nil).Once()
+		m.State.On("GetTokenTransfers", context.Background(), address, uint64(0), uint64(20), (*state.LogPosition)(nil), uint64(tokenTransfersPageSize), m.DbTx).
+			Return([]state.TokenTransfer{transfer}, nil).Once()
+
+		res, err := s.JSONRPCCall("hez_getTokenTransfers", address.String())
+		require.NoError(t, err)
+		require.Nil(t, res.Error)
+
+		var result rpcTokenTransfersPage
+		require.NoError(t, json.Unmarshal(res.Result, &result))
+		require.Equal(t, 1, len(result.Transfers))
+		assert.Nil(t, result.Cursor)
+		assert.Equal(t, transfer.TxHash, result.Transfers[0].TxHash)
+		assert.Equal(t, argUint64(2), result.Transfers[0].LogIndex)
+		assert.Equal(t, transfer.Token, result.Transfers[0].Token)
+		assert.Equal(t, state.TokenTypeERC20, result.Transfers[0].TokenType)
+		assert.Equal(t, state.TokenEventTransfer, result.Transfers[0].EventType)
+		assert.Equal(t, transfer.To, result.Transfers[0].To)
+		assert.Equal(t, argBig(*big.NewInt(100)), result.Transfers[0].Value)
+	})
+
+	t.Run("The cursor of a full page points to its last event", func(t *testing.T) {
+		transfers := make([]state.TokenTransfer, tokenTransfersPageSize)
+		for i := range transfers {
+			transfers[i] = transfer
+		}
+
+		m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+		m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+		m.State.On("GetLastL2BlockNumber", context.Background(), m.DbTx).Return(uint64(20), nil).Once()
+		m.State.On("GetTokenTransfers", context.Background(), address, uint64(0), uint64(20), (*state.LogPosition)(nil), uint64(tokenTransfersPageSize), m.DbTx).
+			Return(transfers, nil).Once()
+
+		res, err := s.JSONRPCCall("hez_getTokenTransfers", address.String())
+		require.NoError(t, err)
+		require.Nil(t, res.Error)
+
+		var result rpcTokenTransfersPage
+		require.NoError(t, json.Unmarshal(res.Result, &result))
+		require.NotNil(t, result.Cursor)
+		assert.Equal(t, pagePositionToCursor(pagePosition{BlockNumber: 3, Index: 2}), *result.Cursor)
+	})
+
+	t.Run("failed to get the token transfers", func(t *testing.T) {
+		m.DbTx.On("Rollback", context.Background()).Return(nil).Once()
+		m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+		m.State.On("GetTokenTransfers", context.Background(), address, uint64(1), uint64(2), &state.LogPosition{BlockNumber: 1, LogIndex: 5}, uint64(tokenTransfersPageSize), m.DbTx).
+			Return(nil, errors.New("failed to get transfers")).Once()
+
+		res, err := s.JSONRPCCall("hez_getTokenTransfers", address.String(), "0x1", "0x2", hex.EncodeToHex(pagePositionToCursor(pagePosition{BlockNumber: 1, Index: 5})))
+		require.NoError(t, err)
+		require.NotNil(t, res.Error)
+		assert.Equal(t, defaultErrorCode, res.Error.Code)
+		assert.Equal(t, "failed to get token transfers of address 0x0000000000000000000000000000000000000123 from state", res.Error.Message)
+	})
+}
+
+func TestGetTokenBalances(t *testing.T) {
+	s, m, _ := newSequencerMockedServer(t)
+	defer s.Stop()
+
+	address := common.HexToAddress("0x123")
+	balances := []state.TokenBalance{{Token: common.HexToAddress("0x1"), Balance: big.NewInt(10)}}
+	ownerships := []state.TokenOwnership{{Token: common.HexToAddress("0x2"), TokenID: big.NewInt(7)}}
+
+	m.DbTx.On("Commit", context.Background()).Return(nil).Once()
+	m.State.On("BeginStateTransaction", context.Background()).Return(m.DbTx, nil).Once()
+	m.State.On("GetTokenBalances", context.Background(), address, m.DbTx).Return(balances, ownerships, nil).Once()
+
+	res, err := s.JSONRPCCall("hez_getTokenBalances", address.String())
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+
+	var result rpcTokenBalances
+	require.NoError(t, json.Unmarshal(res.Result, &result))
+	assert.Equal(t, []rpcTokenBalance{{Token: common.HexToAddress("0x1"), Balance: argBig(*big.NewInt(10))}}, result.ERC20)
+	assert.Equal(t, []rpcTokenOwnership{{Token: common.HexToAddress("0x2"), TokenID: argBig(*big.NewInt(7))}}, result.ERC721)
+}
@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/0xPolygonHermez/zkevm-node/gasprice"
 	"github.com/0xPolygonHermez/zkevm-node/pool"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime"
@@ -19,6 +20,8 @@ type jsonRPCTxPool interface {
 	GetPendingTxs(ctx context.Context, isClaims bool, limit uint64) ([]pool.Transaction, error)
 	GetGasPrice(ctx context.Context) (uint64, error)
 	GetPendingTxHashesSince(ctx context.Context, since time.Time) ([]common.Hash, error)
+	GetPendingNonce(ctx context.Context, address common.Address, stateNonce uint64) (uint64, error)
+	GetTxState(ctx context.Context, hash common.Hash) (pool.TxState, error)
 }
 
 // gasPriceEstimator contains the methods required to interact with gas price estimator
@@ -26,21 +29,30 @@ type gasPriceEstimator interface {
 	GetAvgGasPrice(ctx context.Context) (*big.Int, error)
 }
 
+// feeHistoryOracle contains the methods required to get the fee history and
+// the suggested tip
+type feeHistoryOracle interface {
+	FeeHistory(ctx context.Context, blockCount, newestBlock uint64, rewardPercentiles []float64, dbTx pgx.Tx) (*gasprice.FeeHistoryResult, error)
+	SuggestTipCap(ctx context.Context) (*big.Int, error)
+}
+
 // stateInterface gathers the methods required to interact with the state.
 type stateInterface interface {
 	BeginStateTransaction(ctx context.Context) (pgx.Tx, error)
 
 	GetLastConsolidatedL2BlockNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error)
+	GetLastVirtualL2BlockNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error)
 	GetTransactionByHash(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*types.Transaction, error)
 	GetTransactionReceipt(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*types.Receipt, error)
 	GetLastL2BlockNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error)
 	GetLastL2Block(ctx context.Context, dbTx pgx.Tx) (*types.Block, error)
 	GetLastL2BlockHeader(ctx context.Context, dbTx pgx.Tx) (*types.Header, error)
-	EstimateGas(transaction *types.Transaction, senderAddress common.Address) (uint64, error)
+	EstimateGas(transaction *types.Transaction, senderAddress common.Address, l2BlockNumber *uint64, stateOverride state.StateOverride, gasCap uint64, dbTx pgx.Tx) (uint64, error)
 	GetBalance(ctx context.Context, address common.Address, blockNumber uint64, dbTx pgx.Tx) (*big.Int, error)
 	GetL2BlockByHash(ctx context.Context, hash common.Hash, dbTx pgx.Tx) (*types.Block, error)
 	GetL2BlockByNumber(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (*types.Block, error)
 	GetCode(ctx context.Context, address common.Address, blockNumber uint64, dbTx pgx.Tx) ([]byte, error)
+	GetProof(ctx context.Context, address common.Address, storageKeys []common.Hash, blockNumber uint64, dbTx pgx.Tx) (*state.AccountProof, error)
 	GetStorageAt(ctx context.Context, address common.Address, position *big.Int, blockNumber uint64, dbTx pgx.Tx) (*big.Int, error)
 	GetSyncingInfo(ctx context.Context, dbTx pgx.Tx) (state.SyncingInfo, error)
 	GetTransactionByL2BlockHashAndIndex(ctx context.Context, blockHash common.Hash, index uint64, dbTx pgx.Tx) (*types.Transaction, error)
@@ -52,7 +64,25 @@ type stateInterface interface {
 	GetLogs(ctx context.Context, fromBlock uint64, toBlock uint64, addresses []common.Address, topics [][]common.Hash, blockHash *common.Hash, since *time.Time, dbTx pgx.Tx) ([]*types.Log, error)
 	GetL2BlockHashesSince(ctx context.Context, since time.Time, dbTx pgx.Tx) ([]common.Hash, error)
 	DebugTransaction(ctx context.Context, transactionHash common.Hash, tracer string) (*runtime.ExecutionResult, error)
-	ProcessUnsignedTransaction(ctx context.Context, tx *types.Transaction, senderAddress common.Address, blockNumber uint64, dbTx pgx.Tx) *runtime.ExecutionResult
+	ProcessUnsignedTransaction(ctx context.Context, tx *types.Transaction, senderAddress common.Address, blockNumber uint64, stateOverride state.StateOverride, dbTx pgx.Tx) *runtime.ExecutionResult
+	GetTrustedReorgs(ctx context.Context, fromBatchNumber uint64, limit uint64, dbTx pgx.Tx) ([]state.TrustedReorg, error)
+	GetLastBatchNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error)
+	GetLastVirtualBatchNum(ctx context.Context, dbTx pgx.Tx) (uint64, error)
+	GetLastVerifiedBatch(ctx context.Context, dbTx pgx.Tx) (*state.VerifiedBatch, error)
+	GetBatchByNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (*state.Batch, error)
+	GetVirtualBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (*state.VirtualBatch, error)
+	GetVerifiedBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (*state.VerifiedBatch, error)
+	GetTxsHashesByBatchNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) ([]common.Hash, error)
+	GetBatchNumberOfL2Block(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (uint64, error)
+	GetLastL2BlockNumberUntilBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (uint64, error)
+	IsBatchClosed(ctx context.Context, batchNum uint64, dbTx pgx.Tx) (bool, error)
+	GetTxsByBatchNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) ([]*types.Transaction, error)
+	IsL2BlockVirtualized(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (bool, error)
+	GetTxsByAddress(ctx context.Context, address common.Address, fromBlock, toBlock uint64, after *state.TxPosition, limit uint64, dbTx pgx.Tx) ([]state.TxInL2Block, error)
+	GetTokenTransfers(ctx context.Context, address common.Address, fromBlock, toBlock uint64, after *state.LogPosition, limit uint64, dbTx pgx.Tx) ([]state.TokenTransfer, error)
+	GetTokenBalances(ctx context.Context, address common.Address, dbTx pgx.Tx) ([]state.TokenBalance, []state.TokenOwnership, error)
+	GetTxStatus(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*state.TxStatus, error)
+	IsL2BlockConsolidated(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (bool, error)
 }
 
 type storageInterface interface {
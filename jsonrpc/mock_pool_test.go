@@ -56,6 +56,27 @@ func (_m *poolMock) GetGasPrice(ctx context.Context) (uint64, error) {
 	return r0, r1
 }
 
+// GetPendingNonce provides a mock function with given fields: ctx, address, stateNonce
+func (_m *poolMock) GetPendingNonce(ctx context.Context, address common.Address, stateNonce uint64) (uint64, error) {
+	ret := _m.Called(ctx, address, stateNonce)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address, uint64) uint64); ok {
+		r0 = rf(ctx, address, stateNonce)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Address, uint64) error); ok {
+		r1 = rf(ctx, address, stateNonce)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPendingTxHashesSince provides a mock function with given fields: ctx, since
 func (_m *poolMock) GetPendingTxHashesSince(ctx context.Context, since time.Time) ([]common.Hash, error) {
 	ret := _m.Called(ctx, since)
@@ -102,6 +123,27 @@ func (_m *poolMock) GetPendingTxs(ctx context.Context, isClaims bool, limit uint
 	return r0, r1
 }
 
+// GetTxState provides a mock function with given fields: ctx, hash
+func (_m *poolMock) GetTxState(ctx context.Context, hash common.Hash) (pool.TxState, error) {
+	ret := _m.Called(ctx, hash)
+
+	var r0 pool.TxState
+	if rf, ok := ret.Get(0).(func(context.Context, common.Hash) pool.TxState); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		r0 = ret.Get(0).(pool.TxState)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Hash) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTnewPoolMock interface {
 	mock.TestingT
 	Cleanup(func())
@@ -72,20 +72,20 @@ func (_m *stateMock) DebugTransaction(ctx context.Context, transactionHash commo
 	return r0, r1
 }
 
-// EstimateGas provides a mock function with given fields: transaction, senderAddress
-func (_m *stateMock) EstimateGas(transaction *types.Transaction, senderAddress common.Address) (uint64, error) {
-	ret := _m.Called(transaction, senderAddress)
+// EstimateGas provides a mock function with given fields: transaction, senderAddress, l2BlockNumber, stateOverride, gasCap, dbTx
+func (_m *stateMock) EstimateGas(transaction *types.Transaction, senderAddress common.Address, l2BlockNumber *uint64, stateOverride state.StateOverride, gasCap uint64, dbTx pgx.Tx) (uint64, error) {
+	ret := _m.Called(transaction, senderAddress, l2BlockNumber, stateOverride, gasCap, dbTx)
 
 	var r0 uint64
-	if rf, ok := ret.Get(0).(func(*types.Transaction, common.Address) uint64); ok {
-		r0 = rf(transaction, senderAddress)
+	if rf, ok := ret.Get(0).(func(*types.Transaction, common.Address, *uint64, state.StateOverride, uint64, pgx.Tx) uint64); ok {
+		r0 = rf(transaction, senderAddress, l2BlockNumber, stateOverride, gasCap, dbTx)
 	} else {
 		r0 = ret.Get(0).(uint64)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*types.Transaction, common.Address) error); ok {
-		r1 = rf(transaction, senderAddress)
+	if rf, ok := ret.Get(1).(func(*types.Transaction, common.Address, *uint64, state.StateOverride, uint64, pgx.Tx) error); ok {
+		r1 = rf(transaction, senderAddress, l2BlockNumber, stateOverride, gasCap, dbTx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -116,6 +116,50 @@ func (_m *stateMock) GetBalance(ctx context.Context, address common.Address, blo
 	return r0, r1
 }
 
+// GetBatchByNumber provides a mock function with given fields: ctx, batchNumber, dbTx
+func (_m *stateMock) GetBatchByNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (*state.Batch, error) {
+	ret := _m.Called(ctx, batchNumber, dbTx)
+
+	var r0 *state.Batch
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) *state.Batch); ok {
+		r0 = rf(ctx, batchNumber, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*state.Batch)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, batchNumber, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBatchNumberOfL2Block provides a mock function with given fields: ctx, blockNumber, dbTx
+func (_m *stateMock) GetBatchNumberOfL2Block(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (uint64, error) {
+	ret := _m.Called(ctx, blockNumber, dbTx)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) uint64); ok {
+		r0 = rf(ctx, blockNumber, dbTx)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, blockNumber, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCode provides a mock function with given fields: ctx, address, blockNumber, dbTx
 func (_m *stateMock) GetCode(ctx context.Context, address common.Address, blockNumber uint64, dbTx pgx.Tx) ([]byte, error) {
 	ret := _m.Called(ctx, address, blockNumber, dbTx)
@@ -273,6 +317,27 @@ func (_m *stateMock) GetL2BlockTransactionCountByNumber(ctx context.Context, blo
 	return r0, r1
 }
 
+// GetLastBatchNumber provides a mock function with given fields: ctx, dbTx
+func (_m *stateMock) GetLastBatchNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
+	ret := _m.Called(ctx, dbTx)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx) uint64); ok {
+		r0 = rf(ctx, dbTx)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx) error); ok {
+		r1 = rf(ctx, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLastConsolidatedL2BlockNumber provides a mock function with given fields: ctx, dbTx
 func (_m *stateMock) GetLastConsolidatedL2BlockNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
 	ret := _m.Called(ctx, dbTx)
@@ -361,6 +426,92 @@ func (_m *stateMock) GetLastL2BlockNumber(ctx context.Context, dbTx pgx.Tx) (uin
 	return r0, r1
 }
 
+// GetLastL2BlockNumberUntilBatch provides a mock function with given fields: ctx, batchNumber, dbTx
+func (_m *stateMock) GetLastL2BlockNumberUntilBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (uint64, error) {
+	ret := _m.Called(ctx, batchNumber, dbTx)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) uint64); ok {
+		r0 = rf(ctx, batchNumber, dbTx)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, batchNumber, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLastVerifiedBatch provides a mock function with given fields: ctx, dbTx
+func (_m *stateMock) GetLastVerifiedBatch(ctx context.Context, dbTx pgx.Tx) (*state.VerifiedBatch, error) {
+	ret := _m.Called(ctx, dbTx)
+
+	var r0 *state.VerifiedBatch
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx) *state.VerifiedBatch); ok {
+		r0 = rf(ctx, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*state.VerifiedBatch)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx) error); ok {
+		r1 = rf(ctx, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLastVirtualBatchNum provides a mock function with given fields: ctx, dbTx
+func (_m *stateMock) GetLastVirtualBatchNum(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
+	ret := _m.Called(ctx, dbTx)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx) uint64); ok {
+		r0 = rf(ctx, dbTx)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx) error); ok {
+		r1 = rf(ctx, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLastVirtualL2BlockNumber provides a mock function with given fields: ctx, dbTx
+func (_m *stateMock) GetLastVirtualL2BlockNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
+	ret := _m.Called(ctx, dbTx)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx) uint64); ok {
+		r0 = rf(ctx, dbTx)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx) error); ok {
+		r1 = rf(ctx, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLogs provides a mock function with given fields: ctx, fromBlock, toBlock, addresses, topics, blockHash, since, dbTx
 func (_m *stateMock) GetLogs(ctx context.Context, fromBlock uint64, toBlock uint64, addresses []common.Address, topics [][]common.Hash, blockHash *common.Hash, since *time.Time, dbTx pgx.Tx) ([]*types.Log, error) {
 	ret := _m.Called(ctx, fromBlock, toBlock, addresses, topics, blockHash, since, dbTx)
@@ -405,6 +556,29 @@ func (_m *stateMock) GetNonce(ctx context.Context, address common.Address, block
 	return r0, r1
 }
 
+// GetProof provides a mock function with given fields: ctx, address, storageKeys, blockNumber, dbTx
+func (_m *stateMock) GetProof(ctx context.Context, address common.Address, storageKeys []common.Hash, blockNumber uint64, dbTx pgx.Tx) (*state.AccountProof, error) {
+	ret := _m.Called(ctx, address, storageKeys, blockNumber, dbTx)
+
+	var r0 *state.AccountProof
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address, []common.Hash, uint64, pgx.Tx) *state.AccountProof); ok {
+		r0 = rf(ctx, address, storageKeys, blockNumber, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*state.AccountProof)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Address, []common.Hash, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, address, storageKeys, blockNumber, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetStorageAt provides a mock function with given fields: ctx, address, position, blockNumber, dbTx
 func (_m *stateMock) GetStorageAt(ctx context.Context, address common.Address, position *big.Int, blockNumber uint64, dbTx pgx.Tx) (*big.Int, error) {
 	ret := _m.Called(ctx, address, position, blockNumber, dbTx)
@@ -449,6 +623,61 @@ func (_m *stateMock) GetSyncingInfo(ctx context.Context, dbTx pgx.Tx) (state.Syn
 	return r0, r1
 }
 
+// GetTokenBalances provides a mock function with given fields: ctx, address, dbTx
+func (_m *stateMock) GetTokenBalances(ctx context.Context, address common.Address, dbTx pgx.Tx) ([]state.TokenBalance, []state.TokenOwnership, error) {
+	ret := _m.Called(ctx, address, dbTx)
+
+	var r0 []state.TokenBalance
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address, pgx.Tx) []state.TokenBalance); ok {
+		r0 = rf(ctx, address, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]state.TokenBalance)
+		}
+	}
+
+	var r1 []state.TokenOwnership
+	if rf, ok := ret.Get(1).(func(context.Context, common.Address, pgx.Tx) []state.TokenOwnership); ok {
+		r1 = rf(ctx, address, dbTx)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]state.TokenOwnership)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, common.Address, pgx.Tx) error); ok {
+		r2 = rf(ctx, address, dbTx)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetTokenTransfers provides a mock function with given fields: ctx, address, fromBlock, toBlock, after, limit, dbTx
+func (_m *stateMock) GetTokenTransfers(ctx context.Context, address common.Address, fromBlock uint64, toBlock uint64, after *state.LogPosition, limit uint64, dbTx pgx.Tx) ([]state.TokenTransfer, error) {
+	ret := _m.Called(ctx, address, fromBlock, toBlock, after, limit, dbTx)
+
+	var r0 []state.TokenTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address, uint64, uint64, *state.LogPosition, uint64, pgx.Tx) []state.TokenTransfer); ok {
+		r0 = rf(ctx, address, fromBlock, toBlock, after, limit, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]state.TokenTransfer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Address, uint64, uint64, *state.LogPosition, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, address, fromBlock, toBlock, after, limit, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTransactionByHash provides a mock function with given fields: ctx, transactionHash, dbTx
 func (_m *stateMock) GetTransactionByHash(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*types.Transaction, error) {
 	ret := _m.Called(ctx, transactionHash, dbTx)
@@ -541,13 +770,237 @@ func (_m *stateMock) GetTransactionReceipt(ctx context.Context, transactionHash
 	return r0, r1
 }
 
-// ProcessUnsignedTransaction provides a mock function with given fields: ctx, tx, senderAddress, blockNumber, dbTx
-func (_m *stateMock) ProcessUnsignedTransaction(ctx context.Context, tx *types.Transaction, senderAddress common.Address, blockNumber uint64, dbTx pgx.Tx) *runtime.ExecutionResult {
-	ret := _m.Called(ctx, tx, senderAddress, blockNumber, dbTx)
+// GetTrustedReorgs provides a mock function with given fields: ctx, fromBatchNumber, limit, dbTx
+func (_m *stateMock) GetTrustedReorgs(ctx context.Context, fromBatchNumber uint64, limit uint64, dbTx pgx.Tx) ([]state.TrustedReorg, error) {
+	ret := _m.Called(ctx, fromBatchNumber, limit, dbTx)
+
+	var r0 []state.TrustedReorg
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64, pgx.Tx) []state.TrustedReorg); ok {
+		r0 = rf(ctx, fromBatchNumber, limit, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]state.TrustedReorg)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, fromBatchNumber, limit, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTxStatus provides a mock function with given fields: ctx, transactionHash, dbTx
+func (_m *stateMock) GetTxStatus(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*state.TxStatus, error) {
+	ret := _m.Called(ctx, transactionHash, dbTx)
+
+	var r0 *state.TxStatus
+	if rf, ok := ret.Get(0).(func(context.Context, common.Hash, pgx.Tx) *state.TxStatus); ok {
+		r0 = rf(ctx, transactionHash, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*state.TxStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Hash, pgx.Tx) error); ok {
+		r1 = rf(ctx, transactionHash, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTxsByAddress provides a mock function with given fields: ctx, address, fromBlock, toBlock, after, limit, dbTx
+func (_m *stateMock) GetTxsByAddress(ctx context.Context, address common.Address, fromBlock uint64, toBlock uint64, after *state.TxPosition, limit uint64, dbTx pgx.Tx) ([]state.TxInL2Block, error) {
+	ret := _m.Called(ctx, address, fromBlock, toBlock, after, limit, dbTx)
+
+	var r0 []state.TxInL2Block
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address, uint64, uint64, *state.TxPosition, uint64, pgx.Tx) []state.TxInL2Block); ok {
+		r0 = rf(ctx, address, fromBlock, toBlock, after, limit, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]state.TxInL2Block)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Address, uint64, uint64, *state.TxPosition, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, address, fromBlock, toBlock, after, limit, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTxsByBatchNumber provides a mock function with given fields: ctx, batchNumber, dbTx
+func (_m *stateMock) GetTxsByBatchNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) ([]*types.Transaction, error) {
+	ret := _m.Called(ctx, batchNumber, dbTx)
+
+	var r0 []*types.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) []*types.Transaction); ok {
+		r0 = rf(ctx, batchNumber, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, batchNumber, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTxsHashesByBatchNumber provides a mock function with given fields: ctx, batchNumber, dbTx
+func (_m *stateMock) GetTxsHashesByBatchNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) ([]common.Hash, error) {
+	ret := _m.Called(ctx, batchNumber, dbTx)
+
+	var r0 []common.Hash
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) []common.Hash); ok {
+		r0 = rf(ctx, batchNumber, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]common.Hash)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, batchNumber, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetVerifiedBatch provides a mock function with given fields: ctx, batchNumber, dbTx
+func (_m *stateMock) GetVerifiedBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (*state.VerifiedBatch, error) {
+	ret := _m.Called(ctx, batchNumber, dbTx)
+
+	var r0 *state.VerifiedBatch
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) *state.VerifiedBatch); ok {
+		r0 = rf(ctx, batchNumber, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*state.VerifiedBatch)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, batchNumber, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetVirtualBatch provides a mock function with given fields: ctx, batchNumber, dbTx
+func (_m *stateMock) GetVirtualBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (*state.VirtualBatch, error) {
+	ret := _m.Called(ctx, batchNumber, dbTx)
+
+	var r0 *state.VirtualBatch
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) *state.VirtualBatch); ok {
+		r0 = rf(ctx, batchNumber, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*state.VirtualBatch)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, batchNumber, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsBatchClosed provides a mock function with given fields: ctx, batchNum, dbTx
+func (_m *stateMock) IsBatchClosed(ctx context.Context, batchNum uint64, dbTx pgx.Tx) (bool, error) {
+	ret := _m.Called(ctx, batchNum, dbTx)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) bool); ok {
+		r0 = rf(ctx, batchNum, dbTx)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, batchNum, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsL2BlockConsolidated provides a mock function with given fields: ctx, blockNumber, dbTx
+func (_m *stateMock) IsL2BlockConsolidated(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (bool, error) {
+	ret := _m.Called(ctx, blockNumber, dbTx)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) bool); ok {
+		r0 = rf(ctx, blockNumber, dbTx)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, blockNumber, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsL2BlockVirtualized provides a mock function with given fields: ctx, blockNumber, dbTx
+func (_m *stateMock) IsL2BlockVirtualized(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (bool, error) {
+	ret := _m.Called(ctx, blockNumber, dbTx)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) bool); ok {
+		r0 = rf(ctx, blockNumber, dbTx)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, blockNumber, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProcessUnsignedTransaction provides a mock function with given fields: ctx, tx, senderAddress, blockNumber, stateOverride, dbTx
+func (_m *stateMock) ProcessUnsignedTransaction(ctx context.Context, tx *types.Transaction, senderAddress common.Address, blockNumber uint64, stateOverride state.StateOverride, dbTx pgx.Tx) *runtime.ExecutionResult {
+	ret := _m.Called(ctx, tx, senderAddress, blockNumber, stateOverride, dbTx)
 
 	var r0 *runtime.ExecutionResult
-	if rf, ok := ret.Get(0).(func(context.Context, *types.Transaction, common.Address, uint64, pgx.Tx) *runtime.ExecutionResult); ok {
-		r0 = rf(ctx, tx, senderAddress, blockNumber, dbTx)
+	if rf, ok := ret.Get(0).(func(context.Context, *types.Transaction, common.Address, uint64, state.StateOverride, pgx.Tx) *runtime.ExecutionResult); ok {
+		r0 = rf(ctx, tx, senderAddress, blockNumber, stateOverride, dbTx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*runtime.ExecutionResult)
@@ -82,17 +82,8 @@ func (f *LogFilter) MarshalJSON() ([]byte, error) {
 
 	obj.BlockHash = f.BlockHash
 
-	if f.FromBlock == LatestBlockNumber {
-		obj.FromBlock = ""
-	} else {
-		obj.FromBlock = hex.EncodeUint64(uint64(f.FromBlock))
-	}
-
-	if f.ToBlock == LatestBlockNumber {
-		obj.ToBlock = ""
-	} else {
-		obj.ToBlock = hex.EncodeUint64(uint64(f.ToBlock))
-	}
+	obj.FromBlock = blockNumberToString(f.FromBlock)
+	obj.ToBlock = blockNumberToString(f.ToBlock)
 
 	if f.Addresses != nil {
 		if len(f.Addresses) == 1 {
@@ -116,6 +107,25 @@ func (f *LogFilter) MarshalJSON() ([]byte, error) {
 	return json.Marshal(obj)
 }
 
+// blockNumberToString encodes a block number the way it is decoded by
+// stringToBlockNumber, keeping the tags of the non numeric block numbers
+func blockNumberToString(b BlockNumber) string {
+	switch b {
+	case LatestBlockNumber:
+		return ""
+	case EarliestBlockNumber:
+		return Earliest
+	case PendingBlockNumber:
+		return Pending
+	case SafeBlockNumber:
+		return Safe
+	case FinalizedBlockNumber:
+		return Finalized
+	default:
+		return hex.EncodeUint64(uint64(b))
+	}
+}
+
 // UnmarshalJSON decodes a json object
 func (f *LogFilter) UnmarshalJSON(data []byte) error {
 	var obj LogFilterRequest
@@ -38,11 +38,11 @@ type Server struct {
 
 // NewServer returns the JsonRPC server
 func NewServer(cfg Config, p jsonRPCTxPool, s stateInterface,
-	gpe gasPriceEstimator, storage storageInterface, apis map[string]bool) *Server {
+	gpe gasPriceEstimator, fho feeHistoryOracle, storage storageInterface, apis map[string]bool) *Server {
 	handler := newJSONRpcHandler()
 
 	if _, ok := apis[APIEth]; ok {
-		ethEndpoints := &Eth{cfg: cfg, pool: p, state: s, gpe: gpe, storage: storage}
+		ethEndpoints := &Eth{cfg: cfg, pool: p, state: s, gpe: gpe, fho: fho, storage: storage}
 		handler.registerService(APIEth, ethEndpoints)
 	}
 
@@ -52,7 +52,7 @@ func NewServer(cfg Config, p jsonRPCTxPool, s stateInterface,
 	}
 
 	if _, ok := apis[APIHez]; ok {
-		hezEndpoints := &Hez{state: s}
+		hezEndpoints := &Hez{pool: p, state: s}
 		handler.registerService(APIHez, hezEndpoints)
 	}
 
@@ -24,6 +24,7 @@ type mocks struct {
 	Pool              *poolMock
 	State             *stateMock
 	GasPriceEstimator *gasPriceEstimatorMock
+	FeeHistoryOracle  *feeHistoryOracleMock
 	Storage           *storageMock
 	DbTx              *dbTxMock
 }
@@ -32,6 +33,7 @@ func newMockedServer(t *testing.T, cfg Config) (*mockedServer, *mocks, *ethclien
 	pool := newPoolMock(t)
 	state := newStateMock(t)
 	gasPriceEstimator := newGasPriceEstimatorMock(t)
+	feeHistoryOracle := newFeeHistoryOracleMock(t)
 	storage := newStorageMock(t)
 	dbTx := newDbTxMock(t)
 	apis := map[string]bool{
@@ -43,7 +45,7 @@ func newMockedServer(t *testing.T, cfg Config) (*mockedServer, *mocks, *ethclien
 		APIWeb3:   true,
 	}
 
-	server := NewServer(cfg, pool, state, gasPriceEstimator, storage, apis)
+	server := NewServer(cfg, pool, state, gasPriceEstimator, feeHistoryOracle, storage, apis)
 
 	go func() {
 		err := server.Start()
@@ -75,6 +77,7 @@ func newMockedServer(t *testing.T, cfg Config) (*mockedServer, *mocks, *ethclien
 		Pool:              pool,
 		State:             state,
 		GasPriceEstimator: gasPriceEstimator,
+		FeeHistoryOracle:  feeHistoryOracle,
 		Storage:           storage,
 		DbTx:              dbTx,
 	}
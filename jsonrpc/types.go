@@ -1,12 +1,17 @@
 package jsonrpc
 
 import (
+	"encoding/binary"
+	"fmt"
 	"math/big"
 	"strconv"
 	"strings"
 
 	"github.com/0xPolygonHermez/zkevm-node/encoding"
+	"github.com/0xPolygonHermez/zkevm-node/gasprice"
 	"github.com/0xPolygonHermez/zkevm-node/hex"
+	"github.com/0xPolygonHermez/zkevm-node/merkletree"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -96,6 +101,51 @@ func encodeToHex(b []byte) []byte {
 	return []byte("0x" + str)
 }
 
+// stateOverride is the collection of accounts to be overridden before
+// executing eth_call and eth_estimateGas, indexed by address
+type stateOverride map[common.Address]overrideAccount
+
+// overrideAccount indicates the fields of an account to be overridden
+type overrideAccount struct {
+	Nonce     *argUint64                   `json:"nonce"`
+	Code      *argBytes                    `json:"code"`
+	Balance   *argBig                      `json:"balance"`
+	State     *map[common.Hash]common.Hash `json:"state"`
+	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
+}
+
+// toStateOverride converts the rpc state override into a state override
+func (o *stateOverride) toStateOverride() state.StateOverride {
+	if o == nil {
+		return nil
+	}
+
+	stateOverride := make(state.StateOverride, len(*o))
+	for address, account := range *o {
+		overrideAccount := state.OverrideAccount{}
+		if account.Nonce != nil {
+			nonce := uint64(*account.Nonce)
+			overrideAccount.Nonce = &nonce
+		}
+		if account.Code != nil {
+			code := []byte(*account.Code)
+			overrideAccount.Code = &code
+		}
+		if account.Balance != nil {
+			overrideAccount.Balance = (*big.Int)(account.Balance)
+		}
+		if account.State != nil {
+			overrideAccount.State = *account.State
+		}
+		if account.StateDiff != nil {
+			overrideAccount.StateDiff = *account.StateDiff
+		}
+		stateOverride[address] = overrideAccount
+	}
+
+	return stateOverride
+}
+
 // txnArgs is the transaction argument for the rpc endpoints
 type txnArgs struct {
 	From     common.Address
@@ -310,6 +360,7 @@ type rpcReceipt struct {
 	ToAddr            *common.Address `json:"to"`
 	ContractAddress   *common.Address `json:"contractAddress"`
 	Type              argUint64       `json:"type"`
+	EffectiveGasPrice argBig          `json:"effectiveGasPrice"`
 }
 
 func receiptToRPCReceipt(tx types.Transaction, r *types.Receipt) (rpcReceipt, error) {
@@ -350,6 +401,7 @@ func receiptToRPCReceipt(tx types.Transaction, r *types.Receipt) (rpcReceipt, er
 		FromAddr:          from,
 		ToAddr:            to,
 		Type:              argUint64(r.Type),
+		EffectiveGasPrice: argBig(*state.EffectiveGasPrice(&tx)),
 	}, nil
 }
 
@@ -378,3 +430,417 @@ func logToRPCLog(l types.Log) rpcLog {
 		Removed:     l.Removed,
 	}
 }
+
+type rpcTrustedReorg struct {
+	ID              argUint64     `json:"id"`
+	BatchNumber     argUint64     `json:"batchNumber"`
+	BlockNumber     argUint64     `json:"blockNumber"`
+	DivergentFields []string      `json:"divergentFields"`
+	OldStateRoot    common.Hash   `json:"oldStateRoot"`
+	NewStateRoot    common.Hash   `json:"newStateRoot"`
+	L2BlockNumbers  []argUint64   `json:"l2BlockNumbers"`
+	TxHashes        []common.Hash `json:"transactionHashes"`
+	ReportedAt      argUint64     `json:"reportedAt"`
+}
+
+func trustedReorgToRPCTrustedReorg(r state.TrustedReorg) rpcTrustedReorg {
+	l2BlockNumbers := make([]argUint64, 0, len(r.L2BlockNumbers))
+	for _, l2BlockNumber := range r.L2BlockNumbers {
+		l2BlockNumbers = append(l2BlockNumbers, argUint64(l2BlockNumber))
+	}
+	txHashes := r.TxHashes
+	if txHashes == nil {
+		txHashes = []common.Hash{}
+	}
+
+	return rpcTrustedReorg{
+		ID:              argUint64(r.ID),
+		BatchNumber:     argUint64(r.BatchNumber),
+		BlockNumber:     argUint64(r.BlockNumber),
+		DivergentFields: r.DivergentFields,
+		OldStateRoot:    r.OldStateRoot,
+		NewStateRoot:    r.NewStateRoot,
+		L2BlockNumbers:  l2BlockNumbers,
+		TxHashes:        txHashes,
+		ReportedAt:      argUint64(r.ReportedAt.Unix()),
+	}
+}
+
+type rpcBatch struct {
+	Number              argUint64              `json:"number"`
+	Coinbase            common.Address         `json:"coinbase"`
+	StateRoot           common.Hash            `json:"stateRoot"`
+	GlobalExitRoot      common.Hash            `json:"globalExitRoot"`
+	LocalExitRoot       common.Hash            `json:"localExitRoot"`
+	Timestamp           argUint64              `json:"timestamp"`
+	SendSequencesTxHash *common.Hash           `json:"sendSequencesTxHash"`
+	VerifyBatchTxHash   *common.Hash           `json:"verifyBatchTxHash"`
+	Transactions        []rpcTransactionOrHash `json:"transactions"`
+}
+
+func batchToRPCBatch(b state.Batch, virtualBatch *state.VirtualBatch, verifiedBatch *state.VerifiedBatch, txs []rpcTransactionOrHash) *rpcBatch {
+	res := &rpcBatch{
+		Number:         argUint64(b.BatchNumber),
+		Coinbase:       b.Coinbase,
+		StateRoot:      b.StateRoot,
+		GlobalExitRoot: b.GlobalExitRoot,
+		LocalExitRoot:  b.LocalExitRoot,
+		Timestamp:      argUint64(b.Timestamp.Unix()),
+		Transactions:   txs,
+	}
+
+	if virtualBatch != nil {
+		res.SendSequencesTxHash = &virtualBatch.TxHash
+	}
+	if verifiedBatch != nil {
+		res.VerifyBatchTxHash = &verifiedBatch.TxHash
+	}
+
+	return res
+}
+
+// rpcTxStatus describes how far a tx has gone on its way to finality. The
+// fields of the stages the tx hasn't reached yet are null
+type rpcTxStatus struct {
+	Hash          common.Hash       `json:"hash"`
+	PoolState     *string           `json:"poolState"`
+	BlockNumber   *argUint64        `json:"blockNumber"`
+	BlockHash     *common.Hash      `json:"blockHash"`
+	BatchNumber   *argUint64        `json:"batchNumber"`
+	VirtualBatch  *rpcL1BatchAction `json:"virtualBatch"`
+	VerifiedBatch *rpcL1BatchAction `json:"verifiedBatch"`
+}
+
+// rpcL1BatchAction references the L1 tx that sequenced or verified a batch
+type rpcL1BatchAction struct {
+	BatchNumber   argUint64   `json:"batchNumber"`
+	L1TxHash      common.Hash `json:"l1TxHash"`
+	L1BlockNumber argUint64   `json:"l1BlockNumber"`
+}
+
+func txStatusToRPCTxStatus(hash common.Hash, poolState *pool.TxState, status *state.TxStatus) *rpcTxStatus {
+	res := &rpcTxStatus{
+		Hash: hash,
+	}
+
+	if poolState != nil {
+		s := poolState.String()
+		res.PoolState = &s
+	}
+
+	if status == nil {
+		return res
+	}
+
+	blockNumber := argUint64(status.L2BlockNumber)
+	blockHash := status.L2BlockHash
+	batchNumber := argUint64(status.BatchNumber)
+	res.BlockNumber = &blockNumber
+	res.BlockHash = &blockHash
+	res.BatchNumber = &batchNumber
+
+	if status.VirtualBatch != nil {
+		res.VirtualBatch = &rpcL1BatchAction{
+			BatchNumber:   argUint64(status.VirtualBatch.BatchNumber),
+			L1TxHash:      status.VirtualBatch.TxHash,
+			L1BlockNumber: argUint64(status.VirtualBatch.BlockNumber),
+		}
+	}
+	if status.VerifiedBatch != nil {
+		res.VerifiedBatch = &rpcL1BatchAction{
+			BatchNumber:   argUint64(status.VerifiedBatch.BatchNumber),
+			L1TxHash:      status.VerifiedBatch.TxHash,
+			L1BlockNumber: argUint64(status.VerifiedBatch.BlockNumber),
+		}
+	}
+
+	return res
+}
+
+// rpcTxsPage is a page of txs, the cursor is null when there are no more
+// pages to request
+type rpcTxsPage struct {
+	Transactions []*rpcTransaction `json:"transactions"`
+	Cursor       *argBytes         `json:"cursor"`
+}
+
+// pageCursorLength is the length of a cursor, the block number and the index
+// in the block of the last item of a page
+const pageCursorLength = 16
+
+// pagePosition locates the last item of a page in the l2 blocks, the index
+// is the tx index for txs and the log index for token events
+type pagePosition struct {
+	BlockNumber uint64
+	Index       uint64
+}
+
+func txsInL2BlocksToRPCTxsPage(txs []state.TxInL2Block, pageSize int) *rpcTxsPage {
+	res := &rpcTxsPage{
+		Transactions: make([]*rpcTransaction, 0, len(txs)),
+	}
+	for _, tx := range txs {
+		res.Transactions = append(res.Transactions,
+			toRPCTransaction(tx.Tx, new(big.Int).SetUint64(tx.BlockNumber), tx.BlockHash, tx.Index))
+	}
+
+	if len(txs) == pageSize {
+		last := txs[len(txs)-1]
+		cursor := pagePositionToCursor(pagePosition{BlockNumber: last.BlockNumber, Index: last.Index})
+		res.Cursor = &cursor
+	}
+
+	return res
+}
+
+func pagePositionToCursor(position pagePosition) argBytes {
+	cursor := make(argBytes, pageCursorLength)
+	binary.BigEndian.PutUint64(cursor[:pageCursorLength/2], position.BlockNumber)
+	binary.BigEndian.PutUint64(cursor[pageCursorLength/2:], position.Index)
+	return cursor
+}
+
+func cursorToPagePosition(cursor argBytes) (pagePosition, error) {
+	if len(cursor) != pageCursorLength {
+		return pagePosition{}, fmt.Errorf("invalid cursor length %d, expected %d", len(cursor), pageCursorLength)
+	}
+	return pagePosition{
+		BlockNumber: binary.BigEndian.Uint64(cursor[:pageCursorLength/2]),
+		Index:       binary.BigEndian.Uint64(cursor[pageCursorLength/2:]),
+	}, nil
+}
+
+// rpcTokenTransfer is a Transfer or Approval event of an ERC-20 or ERC-721
+// token. The value is the amount for ERC-20 tokens and the token id for
+// ERC-721 tokens
+type rpcTokenTransfer struct {
+	TxHash      common.Hash    `json:"transactionHash"`
+	LogIndex    argUint64      `json:"logIndex"`
+	BlockNumber argUint64      `json:"blockNumber"`
+	Token       common.Address `json:"token"`
+	TokenType   string         `json:"tokenType"`
+	EventType   string         `json:"eventType"`
+	From        common.Address `json:"from"`
+	To          common.Address `json:"to"`
+	Value       argBig         `json:"value"`
+}
+
+// rpcTokenTransfersPage is a page of token events, the cursor is null when
+// there are no more pages to request
+type rpcTokenTransfersPage struct {
+	Transfers []rpcTokenTransfer `json:"transfers"`
+	Cursor    *argBytes          `json:"cursor"`
+}
+
+func tokenTransfersToRPCTokenTransfersPage(transfers []state.TokenTransfer, pageSize int) *rpcTokenTransfersPage {
+	res := &rpcTokenTransfersPage{
+		Transfers: make([]rpcTokenTransfer, 0, len(transfers)),
+	}
+	for _, t := range transfers {
+		res.Transfers = append(res.Transfers, rpcTokenTransfer{
+			TxHash:      t.TxHash,
+			LogIndex:    argUint64(t.LogIndex),
+			BlockNumber: argUint64(t.BlockNumber),
+			Token:       t.Token,
+			TokenType:   t.TokenType,
+			EventType:   t.EventType,
+			From:        t.From,
+			To:          t.To,
+			Value:       argBig(*t.Value),
+		})
+	}
+
+	if len(transfers) == pageSize {
+		last := transfers[len(transfers)-1]
+		cursor := pagePositionToCursor(pagePosition{BlockNumber: last.BlockNumber, Index: last.LogIndex})
+		res.Cursor = &cursor
+	}
+
+	return res
+}
+
+// rpcTokenBalances contains the balances of an address computed from the
+// indexed token events
+type rpcTokenBalances struct {
+	ERC20  []rpcTokenBalance   `json:"erc20"`
+	ERC721 []rpcTokenOwnership `json:"erc721"`
+}
+
+type rpcTokenBalance struct {
+	Token   common.Address `json:"token"`
+	Balance argBig         `json:"balance"`
+}
+
+type rpcTokenOwnership struct {
+	Token   common.Address `json:"token"`
+	TokenID argBig         `json:"tokenId"`
+}
+
+func tokenBalancesToRPCTokenBalances(balances []state.TokenBalance, ownerships []state.TokenOwnership) *rpcTokenBalances {
+	res := &rpcTokenBalances{
+		ERC20:  make([]rpcTokenBalance, 0, len(balances)),
+		ERC721: make([]rpcTokenOwnership, 0, len(ownerships)),
+	}
+	for _, b := range balances {
+		res.ERC20 = append(res.ERC20, rpcTokenBalance{Token: b.Token, Balance: argBig(*b.Balance)})
+	}
+	for _, o := range ownerships {
+		res.ERC721 = append(res.ERC721, rpcTokenOwnership{Token: o.Token, TokenID: argBig(*o.TokenID)})
+	}
+	return res
+}
+
+// rpcAccountProof is the geth compatible representation of an account proof.
+// The merkletree keeps a leaf for each value of an account instead of an account
+// trie, so the account proof contains the nodes of the balance path and the
+// storage hash is the state root, which the storage proofs are verified against
+type rpcAccountProof struct {
+	Address      common.Address    `json:"address"`
+	AccountProof []argBytes        `json:"accountProof"`
+	Balance      argBig            `json:"balance"`
+	CodeHash     common.Hash       `json:"codeHash"`
+	Nonce        argUint64         `json:"nonce"`
+	StorageHash  common.Hash       `json:"storageHash"`
+	StorageProof []rpcStorageProof `json:"storageProof"`
+}
+
+type rpcStorageProof struct {
+	Key   common.Hash `json:"key"`
+	Value argBig      `json:"value"`
+	Proof []argBytes  `json:"proof"`
+}
+
+func accountProofToRPCAccountProof(p state.AccountProof) rpcAccountProof {
+	storageProof := make([]rpcStorageProof, 0, len(p.StorageProof))
+	for _, sp := range p.StorageProof {
+		storageProof = append(storageProof, rpcStorageProof{
+			Key:   sp.Key,
+			Value: argBig(*sp.Value),
+			Proof: siblingsToRPCNodes(sp.Proof.Siblings),
+		})
+	}
+
+	return rpcAccountProof{
+		Address:      p.Address,
+		AccountProof: siblingsToRPCNodes(p.BalanceProof.Siblings),
+		Balance:      argBig(*p.Balance),
+		CodeHash:     p.CodeHash,
+		Nonce:        argUint64(p.Nonce.Uint64()),
+		StorageHash:  p.StateRoot,
+		StorageProof: storageProof,
+	}
+}
+
+// siblingsToRPCNodes encodes each node as the concatenation of its field
+// elements in big endian
+func siblingsToRPCNodes(siblings [][]uint64) []argBytes {
+	nodes := make([]argBytes, 0, len(siblings))
+	for _, sibling := range siblings {
+		node := make([]byte, len(sibling)*8) //nolint:gomnd
+		for i, e := range sibling {
+			binary.BigEndian.PutUint64(node[i*8:], e) //nolint:gomnd
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// rpcZKAccountProof is the representation of an account proof including the
+// sparse merkle tree proof of each of the values of the account
+type rpcZKAccountProof struct {
+	Address   common.Address      `json:"address"`
+	StateRoot common.Hash         `json:"stateRoot"`
+	Balance   rpcSMTProof         `json:"balance"`
+	Nonce     rpcSMTProof         `json:"nonce"`
+	CodeHash  rpcSMTProof         `json:"codeHash"`
+	Storage   []rpcZKStorageProof `json:"storage"`
+}
+
+type rpcZKStorageProof struct {
+	Key common.Hash `json:"key"`
+	rpcSMTProof
+}
+
+type rpcSMTProof struct {
+	Key      common.Hash   `json:"smtKey"`
+	Value    argBig        `json:"value"`
+	Siblings [][]argUint64 `json:"siblings"`
+	InsKey   *common.Hash  `json:"insKey"`
+	InsValue *argBig       `json:"insValue"`
+	IsOld0   bool          `json:"isOld0"`
+}
+
+func smtProofToRPCSMTProof(p *merkletree.Proof) rpcSMTProof {
+	siblings := make([][]argUint64, 0, len(p.Siblings))
+	for _, sibling := range p.Siblings {
+		node := make([]argUint64, 0, len(sibling))
+		for _, e := range sibling {
+			node = append(node, argUint64(e))
+		}
+		siblings = append(siblings, node)
+	}
+
+	res := rpcSMTProof{
+		Key:      merkletree.H4ToHash(p.Key),
+		Value:    argBig(*merkletree.FeaToScalar(p.Value)),
+		Siblings: siblings,
+		IsOld0:   p.IsOld0,
+	}
+	if p.InsKey != nil {
+		insKey := merkletree.H4ToHash(p.InsKey)
+		res.InsKey = &insKey
+	}
+	if p.InsValue != nil {
+		insValue := argBig(*merkletree.FeaToScalar(p.InsValue))
+		res.InsValue = &insValue
+	}
+	return res
+}
+
+func accountProofToRPCZKAccountProof(p state.AccountProof) rpcZKAccountProof {
+	storage := make([]rpcZKStorageProof, 0, len(p.StorageProof))
+	for _, sp := range p.StorageProof {
+		storage = append(storage, rpcZKStorageProof{
+			Key:         sp.Key,
+			rpcSMTProof: smtProofToRPCSMTProof(sp.Proof),
+		})
+	}
+
+	return rpcZKAccountProof{
+		Address:   p.Address,
+		StateRoot: p.StateRoot,
+		Balance:   smtProofToRPCSMTProof(p.BalanceProof),
+		Nonce:     smtProofToRPCSMTProof(p.NonceProof),
+		CodeHash:  smtProofToRPCSMTProof(p.CodeHashProof),
+		Storage:   storage,
+	}
+}
+
+type rpcFeeHistory struct {
+	OldestBlock  argUint64  `json:"oldestBlock"`
+	Reward       [][]argBig `json:"reward,omitempty"`
+	BaseFee      []argBig   `json:"baseFeePerGas"`
+	GasUsedRatio []float64  `json:"gasUsedRatio"`
+}
+
+func feeHistoryToRPCFeeHistory(h *gasprice.FeeHistoryResult) *rpcFeeHistory {
+	res := &rpcFeeHistory{
+		OldestBlock:  argUint64(h.OldestBlock),
+		BaseFee:      make([]argBig, 0, len(h.BaseFee)),
+		GasUsedRatio: h.GasUsedRatio,
+	}
+	for _, baseFee := range h.BaseFee {
+		res.BaseFee = append(res.BaseFee, argBig(*baseFee))
+	}
+	if h.Reward != nil {
+		res.Reward = make([][]argBig, 0, len(h.Reward))
+		for _, blockReward := range h.Reward {
+			reward := make([]argBig, 0, len(blockReward))
+			for _, tip := range blockReward {
+				reward = append(reward, argBig(*tip))
+			}
+			res.Reward = append(res.Reward, reward)
+		}
+	}
+	return res
+}
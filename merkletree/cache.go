@@ -0,0 +1,93 @@
+package merkletree
+
+import (
+	"github.com/0xPolygonHermez/zkevm-node/metrics"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	cacheHitsMetricName          = "merkletree/cache/hits"
+	cacheMissesMetricName        = "merkletree/cache/misses"
+	programCacheHitsMetricName   = "merkletree/programcache/hits"
+	programCacheMissesMetricName = "merkletree/programcache/misses"
+)
+
+// cache keeps the values read from the merkletree indexed by root and key.
+// Since a root identifies an immutable tree, the value of a key in a given
+// root never changes and can be kept until it is evicted. Programs are
+// indexed by their hash, so they never change either.
+//
+// A nil cache is valid and does not cache anything.
+type cache struct {
+	values   *lru.Cache
+	programs *lru.Cache
+}
+
+// newCache creates a cache with the given configuration, it returns nil when
+// the cache is bypassed
+func newCache(cfg CacheConfig) (*cache, error) {
+	if cfg.Bypass {
+		return nil, nil
+	}
+
+	values, err := lru.New(cfg.Size)
+	if err != nil {
+		return nil, err
+	}
+	programs, err := lru.New(cfg.ProgramSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cache{
+		values:   values,
+		programs: programs,
+	}, nil
+}
+
+func (c *cache) getValue(root, key []uint64) ([]uint64, bool) {
+	if c == nil {
+		return nil, false
+	}
+	value, found := c.values.Get(valueCacheKey(root, key))
+	if !found {
+		metrics.GetOrRegisterCounter(cacheMissesMetricName).Inc(1)
+		return nil, false
+	}
+	metrics.GetOrRegisterCounter(cacheHitsMetricName).Inc(1)
+	return append([]uint64{}, value.([]uint64)...), true
+}
+
+func (c *cache) addValue(root, key, value []uint64) {
+	if c == nil {
+		return
+	}
+	c.values.Add(valueCacheKey(root, key), append([]uint64{}, value...))
+}
+
+func (c *cache) getProgram(key []uint64) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	data, found := c.programs.Get(h4ToString(key))
+	if !found {
+		metrics.GetOrRegisterCounter(programCacheMissesMetricName).Inc(1)
+		return nil, false
+	}
+	metrics.GetOrRegisterCounter(programCacheHitsMetricName).Inc(1)
+	return append([]byte{}, data.([]byte)...), true
+}
+
+func (c *cache) addProgram(key []uint64, data []byte) {
+	if c == nil {
+		return
+	}
+	c.programs.Add(h4ToString(key), append([]byte{}, data...))
+}
+
+func valueCacheKey(root, key []uint64) [2 * h4Length]uint64 {
+	var k [2 * h4Length]uint64
+	copy(k[:h4Length], root)
+	copy(k[h4Length:], key)
+	return k
+}
@@ -0,0 +1,170 @@
+package merkletree
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygonHermez/zkevm-node/merkletree/pb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// countingStateDBClient counts the reads that reach the merkletree
+type countingStateDBClient struct {
+	pb.StateDBServiceClient
+	gets        int
+	getPrograms int
+	// failedGets is the number of next reads that fail
+	failedGets int
+}
+
+func (c *countingStateDBClient) Get(ctx context.Context, in *pb.GetRequest, opts ...grpc.CallOption) (*pb.GetResponse, error) {
+	c.gets++
+	if c.failedGets > 0 {
+		c.failedGets--
+		return &pb.GetResponse{Value: "0", Result: &pb.ResultCode{Code: pb.ResultCode_CODE_DB_ERROR}}, nil
+	}
+	return c.StateDBServiceClient.Get(ctx, in, opts...)
+}
+
+func (c *countingStateDBClient) GetProgram(ctx context.Context, in *pb.GetProgramRequest, opts ...grpc.CallOption) (*pb.GetProgramResponse, error) {
+	c.getPrograms++
+	return c.StateDBServiceClient.GetProgram(ctx, in, opts...)
+}
+
+func newCachedTestTree(t *testing.T, cfg CacheConfig) (*StateTree, *countingStateDBClient) {
+	client := &countingStateDBClient{StateDBServiceClient: NewLocalStateDBClient(NewMemoryStorage())}
+	tree, err := NewStateTreeWithCache(client, cfg)
+	require.NoError(t, err)
+	return tree, client
+}
+
+func TestStateTreeCache(t *testing.T) {
+	ctx := context.Background()
+	tree, client := newCachedTestTree(t, CacheConfig{Size: 10, ProgramSize: 10}) //nolint:gomnd
+
+	address := common.HexToAddress("0x1")
+	code := common.FromHex("0x6080604052")
+
+	oldRoot, _, err := tree.SetBalance(ctx, address, big.NewInt(1), nil)
+	require.NoError(t, err)
+	newRoot, _, err := tree.SetBalance(ctx, address, big.NewInt(2), oldRoot) //nolint:gomnd
+	require.NoError(t, err)
+	newRoot, _, err = tree.SetCode(ctx, address, code, newRoot)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		balance, err := tree.GetBalance(ctx, address, oldRoot)
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(1), balance)
+
+		balance, err = tree.GetBalance(ctx, address, newRoot)
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(2), balance) //nolint:gomnd
+
+		storedCode, err := tree.GetCode(ctx, address, newRoot)
+		require.NoError(t, err)
+		assert.Equal(t, code, storedCode)
+	}
+	// each root and key is read once, along with the code hash
+	assert.Equal(t, 3, client.gets) //nolint:gomnd
+	assert.Equal(t, 1, client.getPrograms)
+
+	// proofs are never served from the cache
+	_, err = tree.GetProof(ctx, mustKeyEthAddrBalance(t, address), newRoot)
+	require.NoError(t, err)
+	assert.Equal(t, 4, client.gets) //nolint:gomnd
+}
+
+func TestStateTreeCacheSize(t *testing.T) {
+	ctx := context.Background()
+	tree, client := newCachedTestTree(t, CacheConfig{Size: 1, ProgramSize: 1})
+
+	addresses := []common.Address{common.HexToAddress("0x1"), common.HexToAddress("0x2")}
+	var root []byte
+	var err error
+	for _, address := range addresses {
+		root, _, err = tree.SetNonce(ctx, address, big.NewInt(1), root)
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		for _, address := range addresses {
+			_, err := tree.GetNonce(ctx, address, root)
+			require.NoError(t, err)
+		}
+	}
+	// every read evicts the previous one
+	assert.Equal(t, 4, client.gets) //nolint:gomnd
+}
+
+func TestStateTreeCacheBypass(t *testing.T) {
+	ctx := context.Background()
+	tree, client := newCachedTestTree(t, CacheConfig{Size: 10, ProgramSize: 10, Bypass: true}) //nolint:gomnd
+
+	address := common.HexToAddress("0x1")
+	root, _, err := tree.SetNonce(ctx, address, big.NewInt(1), nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		nonce, err := tree.GetNonce(ctx, address, root)
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(1), nonce)
+	}
+	assert.Equal(t, 3, client.gets) //nolint:gomnd
+}
+
+func TestStateTreeCacheInvalidSize(t *testing.T) {
+	_, err := NewStateTreeWithCache(NewLocalStateDBClient(NewMemoryStorage()), CacheConfig{})
+	assert.Error(t, err)
+}
+
+func mustKeyEthAddrBalance(t *testing.T, address common.Address) []byte {
+	key, err := KeyEthAddrBalance(address)
+	require.NoError(t, err)
+	return key
+}
+
+func TestStateTreeCacheProgramNotFound(t *testing.T) {
+	ctx := context.Background()
+	tree, client := newCachedTestTree(t, CacheConfig{Size: 10, ProgramSize: 10}) //nolint:gomnd
+
+	code := common.FromHex("0x6080604052")
+	key, err := hashContractBytecode(code)
+	require.NoError(t, err)
+
+	// the missing program is not cached, so it is found once it is set
+	program, err := tree.getProgram(ctx, key)
+	require.NoError(t, err)
+	assert.Empty(t, program.Data)
+
+	require.NoError(t, tree.setProgram(ctx, key, code, true))
+
+	program, err = tree.getProgram(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, code, program.Data)
+	assert.Equal(t, 2, client.getPrograms) //nolint:gomnd
+}
+
+func TestStateTreeCacheGetFailed(t *testing.T) {
+	ctx := context.Background()
+	tree, client := newCachedTestTree(t, CacheConfig{Size: 10, ProgramSize: 10}) //nolint:gomnd
+
+	address := common.HexToAddress("0x1")
+	root, _, err := tree.SetBalance(ctx, address, big.NewInt(1), nil)
+	require.NoError(t, err)
+
+	// the value of the failed read is not cached, so the next read gets the
+	// stored one
+	client.failedGets = 1
+	_, err = tree.GetBalance(ctx, address, root)
+	require.NoError(t, err)
+
+	balance, err := tree.GetBalance(ctx, address, root)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), balance)
+	assert.Equal(t, 2, client.gets) //nolint:gomnd
+}
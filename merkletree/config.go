@@ -1,7 +1,37 @@
 package merkletree
 
+const (
+	// RemoteType uses an external statedb service through gRPC
+	RemoteType = "remote"
+	// PostgresType uses an in-process merkletree stored in the state database
+	PostgresType = "postgres"
+	// MemoryType uses an in-process merkletree stored in memory
+	MemoryType = "memory"
+)
+
 // Config represents the configuration of the merkletree server.
 type Config struct {
-	// URI is the server URI.
+	// Type is the merkletree implementation to use.
+	// Valid values are "remote", "postgres" and "memory". The in-process
+	// types can't be used along with an executor, which has its own statedb,
+	// so they are meant for tests and tools running without one.
+	Type string `mapstructure:"Type"`
+
+	// URI is the server URI, only used by the remote type.
 	URI string `mapstructure:"URI"`
+
+	// Cache is the configuration of the cache of values read from the tree
+	Cache CacheConfig `mapstructure:"Cache"`
+}
+
+// CacheConfig represents the configuration of the merkletree read cache.
+type CacheConfig struct {
+	// Size is the max number of values kept in the cache
+	Size int `mapstructure:"Size"`
+
+	// ProgramSize is the max number of smart contract codes kept in the cache
+	ProgramSize int `mapstructure:"ProgramSize"`
+
+	// Bypass disables the cache, so every read goes to the merkletree
+	Bypass bool `mapstructure:"Bypass"`
 }
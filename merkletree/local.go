@@ -0,0 +1,501 @@
+package merkletree
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	"github.com/0xPolygonHermez/zkevm-node/merkletree/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	// elementLength is the number of bytes used to store a field element
+	elementLength = 8
+
+	modeUpdate         = "update"
+	modeInsertFound    = "insertFound"
+	modeInsertNotFound = "insertNotFound"
+	modeDeleteFound    = "deleteFound"
+	modeDeleteNotFound = "deleteNotFound"
+	modeDeleteLast     = "deleteLast"
+	modeZeroToZero     = "zeroToZero"
+)
+
+var (
+	leafCapacity         = []uint64{1, 0, 0, 0}
+	intermediateCapacity = []uint64{0, 0, 0, 0}
+)
+
+// LocalStateDBClient is an in-process implementation of the statedb service,
+// computing the zkEVM sparse merkle tree in Go. Nodes written with persistence
+// are kept in the storage, the rest are kept in memory by the client that
+// wrote them, so each ephemeral tree gets its own client and its nodes are
+// released along with it.
+type LocalStateDBClient struct {
+	storage   Storage
+	temporary *MemoryStorage
+}
+
+var _ pb.StateDBServiceClient = (*LocalStateDBClient)(nil)
+
+// NewLocalStateDBClient creates a new LocalStateDBClient
+func NewLocalStateDBClient(storage Storage) *LocalStateDBClient {
+	return &LocalStateDBClient{
+		storage:   storage,
+		temporary: NewMemoryStorage(),
+	}
+}
+
+// Set sets the value of a key in the tree with the given root, returning the
+// new root of the tree
+func (c *LocalStateDBClient) Set(ctx context.Context, in *pb.SetRequest, opts ...grpc.CallOption) (*pb.SetResponse, error) {
+	oldRoot := feaToH4(in.OldRoot)
+	key := feaToH4(in.Key)
+	value, err := string2fea(strings.TrimPrefix(in.Value, "0x"))
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.SetResponse{
+		OldRoot: in.OldRoot,
+		Key:     in.Key,
+		IsOld0:  true,
+		Result:  &pb.ResultCode{Code: pb.ResultCode_CODE_SUCCESS},
+	}
+
+	var (
+		r            = oldRoot
+		newRoot      = oldRoot
+		level        = 0
+		accKey       []int
+		siblings     = map[int][]uint64{}
+		foundKey     []uint64
+		foundRKey    []uint64
+		foundValue   []uint64
+		foundOldValH []uint64
+	)
+
+	// Find the path of the key, up to an empty node or a leaf
+	for !isZeroH4(r) && foundKey == nil {
+		node, err := c.read(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		siblings[level] = node
+
+		if isLeafNode(node) {
+			foundOldValH = node[4:8]
+			foundRKey = node[0:4]
+			foundKey = joinKey(accKey, foundRKey)
+			foundValue, err = c.readValue(ctx, foundOldValH)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			bit := getKeyBit(key, level)
+			r = node[bit*4 : bit*4+4]
+			accKey = append(accKey, bit)
+			level++
+		}
+	}
+
+	level--
+	if len(accKey) > 0 {
+		accKey = accKey[:len(accKey)-1]
+	}
+
+	oldValue := make([]uint64, nodeLength)
+	if foundKey != nil && equalH4(key, foundKey) {
+		oldValue = foundValue
+	}
+
+	setChild := func(level int, hash []uint64) {
+		if level >= 0 {
+			bit := getKeyBit(key, level)
+			copy(siblings[level][bit*4:bit*4+4], hash)
+		} else {
+			newRoot = hash
+		}
+	}
+
+	if !isZeroFea(value) {
+		newValH, err := c.hashSave(ctx, value, intermediateCapacity, in.Persistent)
+		if err != nil {
+			return nil, err
+		}
+
+		if foundKey != nil && equalH4(key, foundKey) {
+			res.Mode = modeUpdate
+			newLeafHash, err := c.hashSave(ctx, append(append([]uint64{}, foundRKey...), newValH...), leafCapacity, in.Persistent)
+			if err != nil {
+				return nil, err
+			}
+			setChild(level, newLeafHash)
+		} else if foundKey != nil {
+			res.Mode = modeInsertFound
+			res.InsKey = h4ToFea(foundKey)
+			res.InsValue = fea2hex(foundValue)
+			res.IsOld0 = false
+
+			// Go down until the paths of both keys diverge
+			level2 := level + 1
+			for getKeyBit(key, level2) == getKeyBit(foundKey, level2) {
+				level2++
+			}
+
+			oldKey := removeKeyBits(foundKey, level2+1)
+			oldLeafHash, err := c.hashSave(ctx, append(oldKey, foundOldValH...), leafCapacity, in.Persistent)
+			if err != nil {
+				return nil, err
+			}
+
+			newKey := removeKeyBits(key, level2+1)
+			newLeafHash, err := c.hashSave(ctx, append(newKey, newValH...), leafCapacity, in.Persistent)
+			if err != nil {
+				return nil, err
+			}
+
+			node := make([]uint64, nodeLength)
+			copy(node[getKeyBit(key, level2)*4:], newLeafHash)
+			copy(node[getKeyBit(foundKey, level2)*4:], oldLeafHash)
+			r2, err := c.hashSave(ctx, node, intermediateCapacity, in.Persistent)
+			if err != nil {
+				return nil, err
+			}
+
+			// Add the intermediate nodes shared by both keys
+			for level2--; level2 != level; level2-- {
+				node := make([]uint64, nodeLength)
+				copy(node[getKeyBit(key, level2)*4:], r2)
+				r2, err = c.hashSave(ctx, node, intermediateCapacity, in.Persistent)
+				if err != nil {
+					return nil, err
+				}
+			}
+			setChild(level, r2)
+		} else {
+			res.Mode = modeInsertNotFound
+			newKey := removeKeyBits(key, level+1)
+			newLeafHash, err := c.hashSave(ctx, append(newKey, newValH...), leafCapacity, in.Persistent)
+			if err != nil {
+				return nil, err
+			}
+			setChild(level, newLeafHash)
+		}
+	} else if foundKey != nil && equalH4(key, foundKey) {
+		if level >= 0 {
+			bit := getKeyBit(key, level)
+			copy(siblings[level][bit*4:bit*4+4], make([]uint64, h4Length))
+
+			res.Mode = modeDeleteNotFound
+			uKey := getUniqueSibling(siblings[level])
+			if uKey >= 0 {
+				node, err := c.read(ctx, siblings[level][uKey*4:uKey*4+4])
+				if err != nil {
+					return nil, err
+				}
+				siblings[level+1] = node
+
+				// A single leaf remaining in the subtree is moved up to the
+				// highest level where it doesn't have siblings
+				if isLeafNode(node) {
+					res.Mode = modeDeleteFound
+					valH := node[4:8]
+					insKey := joinKey(append(accKey, uKey), node[0:4])
+					insValue, err := c.readValue(ctx, valH)
+					if err != nil {
+						return nil, err
+					}
+					res.InsKey = h4ToFea(insKey)
+					res.InsValue = fea2hex(insValue)
+					res.IsOld0 = false
+
+					for uKey >= 0 && level >= 0 {
+						level--
+						if level >= 0 {
+							uKey = getUniqueSibling(siblings[level])
+						}
+					}
+
+					oldKey := removeKeyBits(insKey, level+1)
+					oldLeafHash, err := c.hashSave(ctx, append(oldKey, valH...), leafCapacity, in.Persistent)
+					if err != nil {
+						return nil, err
+					}
+					setChild(level, oldLeafHash)
+				}
+			}
+		} else {
+			res.Mode = modeDeleteLast
+			newRoot = make([]uint64, h4Length)
+		}
+	} else {
+		res.Mode = modeZeroToZero
+	}
+
+	for l := range siblings {
+		if l > level {
+			delete(siblings, l)
+		}
+	}
+
+	// Recompute the hashes of the path up to the root
+	for level >= 0 {
+		node := siblings[level]
+		newRoot, err = c.hashSave(ctx, node[:nodeLength], node[nodeLength:leafLength], in.Persistent)
+		if err != nil {
+			return nil, err
+		}
+		level--
+		setChild(level, newRoot)
+	}
+
+	res.NewRoot = h4ToFea(newRoot)
+	res.Siblings = siblingsToPB(siblings)
+	res.OldValue = fea2hex(oldValue)
+	res.NewValue = fea2hex(value)
+
+	return res, nil
+}
+
+// Get gets the value of a key in the tree with the given root
+func (c *LocalStateDBClient) Get(ctx context.Context, in *pb.GetRequest, opts ...grpc.CallOption) (*pb.GetResponse, error) {
+	key := feaToH4(in.Key)
+
+	res := &pb.GetResponse{
+		Root:   in.Root,
+		Key:    in.Key,
+		IsOld0: true,
+		Result: &pb.ResultCode{Code: pb.ResultCode_CODE_SUCCESS},
+	}
+
+	var (
+		r          = feaToH4(in.Root)
+		level      = 0
+		accKey     []int
+		siblings   = map[int][]uint64{}
+		foundKey   []uint64
+		foundValue []uint64
+	)
+
+	for !isZeroH4(r) && foundKey == nil {
+		node, err := c.read(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		siblings[level] = node
+
+		if isLeafNode(node) {
+			foundKey = joinKey(accKey, node[0:4])
+			foundValue, err = c.readValue(ctx, node[4:8])
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			bit := getKeyBit(key, level)
+			r = node[bit*4 : bit*4+4]
+			accKey = append(accKey, bit)
+			level++
+		}
+	}
+
+	value := make([]uint64, nodeLength)
+	if foundKey != nil {
+		if equalH4(key, foundKey) {
+			value = foundValue
+		} else {
+			res.InsKey = h4ToFea(foundKey)
+			res.InsValue = fea2hex(foundValue)
+			res.IsOld0 = false
+		}
+	}
+	res.Value = fea2hex(value)
+
+	if in.Details {
+		res.Siblings = siblingsToPB(siblings)
+	}
+
+	return res, nil
+}
+
+// SetProgram stores the given program indexed by its key
+func (c *LocalStateDBClient) SetProgram(ctx context.Context, in *pb.SetProgramRequest, opts ...grpc.CallOption) (*pb.SetProgramResponse, error) {
+	storage := c.getStorage(in.Persistent)
+	if err := storage.Set(ctx, h4ToFilledByteSlice(feaToH4(in.Key)), in.Data); err != nil {
+		return nil, err
+	}
+	return &pb.SetProgramResponse{
+		Result: &pb.ResultCode{Code: pb.ResultCode_CODE_SUCCESS},
+	}, nil
+}
+
+// GetProgram gets the program stored for the given key
+func (c *LocalStateDBClient) GetProgram(ctx context.Context, in *pb.GetProgramRequest, opts ...grpc.CallOption) (*pb.GetProgramResponse, error) {
+	data, err := c.get(ctx, h4ToFilledByteSlice(feaToH4(in.Key)))
+	if errors.Is(err, ErrNotFound) {
+		return &pb.GetProgramResponse{
+			Result: &pb.ResultCode{Code: pb.ResultCode_CODE_KEY_NOT_FOUND},
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &pb.GetProgramResponse{
+		Data:   data,
+		Result: &pb.ResultCode{Code: pb.ResultCode_CODE_SUCCESS},
+	}, nil
+}
+
+// Flush waits for the pending writes to be stored. The nodes are stored as
+// they are written, so there is nothing to wait for.
+func (c *LocalStateDBClient) Flush(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
+
+// ephemeral returns a client sharing the storage whose nodes written without
+// persistence are kept apart from the ones of the rest of the clients.
+func (c *LocalStateDBClient) ephemeral() pb.StateDBServiceClient {
+	return NewLocalStateDBClient(c.storage)
+}
+
+func (c *LocalStateDBClient) getStorage(persistent bool) Storage {
+	if persistent {
+		return c.storage
+	}
+	return c.temporary
+}
+
+// get reads the data stored for the given hash, looking first at the nodes
+// written without persistence
+func (c *LocalStateDBClient) get(ctx context.Context, hash []byte) ([]byte, error) {
+	data, err := c.temporary.Get(ctx, hash)
+	if errors.Is(err, ErrNotFound) {
+		return c.storage.Get(ctx, hash)
+	}
+	return data, err
+}
+
+// read returns the elements stored for the given hash
+func (c *LocalStateDBClient) read(ctx context.Context, hash []uint64) ([]uint64, error) {
+	data, err := c.get(ctx, h4ToFilledByteSlice(hash))
+	if err != nil {
+		return nil, err
+	}
+	return decodeElements(data), nil
+}
+
+// readValue returns the value stored for the given value hash
+func (c *LocalStateDBClient) readValue(ctx context.Context, hash []uint64) ([]uint64, error) {
+	elements, err := c.read(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) < nodeLength {
+		return nil, ErrMalformedProof
+	}
+	return elements[:nodeLength], nil
+}
+
+// hashSave computes the hash of the given elements and capacity and stores
+// them indexed by the resulting hash
+func (c *LocalStateDBClient) hashSave(ctx context.Context, elements, capacity []uint64, persistent bool) ([]uint64, error) {
+	node := make([]uint64, 0, leafLength)
+	node = append(node, elements[:nodeLength]...)
+	node = append(node, capacity[:h4Length]...)
+
+	hash, err := hashNode(node)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.getStorage(persistent).Set(ctx, h4ToFilledByteSlice(hash), encodeElements(node)); err != nil {
+		return nil, err
+	}
+	return hash, nil
+}
+
+// joinKey rebuilds a key from the bits used to reach a leaf and the
+// remaining key stored in it
+func joinKey(bits []int, rKey []uint64) []uint64 {
+	var n [h4Length]uint
+	var accs [h4Length]uint64
+	for i, bit := range bits {
+		if bit == 1 {
+			accs[i%h4Length] |= 1 << n[i%h4Length]
+		}
+		n[i%h4Length]++
+	}
+
+	key := make([]uint64, h4Length)
+	for i := 0; i < h4Length; i++ {
+		key[i] = rKey[i]<<n[i] | accs[i]
+	}
+	return key
+}
+
+// getUniqueSibling returns the index of the only non empty child of a node,
+// or -1 if the node doesn't have exactly one non empty child
+func getUniqueSibling(node []uint64) int {
+	found := -1
+	for i := 0; i < 2; i++ {
+		if !isZeroH4(node[i*4 : i*4+4]) {
+			if found >= 0 {
+				return -1
+			}
+			found = i
+		}
+	}
+	return found
+}
+
+func isZeroFea(fea []uint64) bool {
+	for _, e := range fea {
+		if e != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func feaToH4(fea *pb.Fea) []uint64 {
+	if fea == nil {
+		return make([]uint64, h4Length)
+	}
+	return []uint64{fea.Fe0, fea.Fe1, fea.Fe2, fea.Fe3}
+}
+
+func h4ToFea(h4 []uint64) *pb.Fea {
+	return &pb.Fea{Fe0: h4[0], Fe1: h4[1], Fe2: h4[2], Fe3: h4[3]}
+}
+
+// fea2hex converts an array of 32bit uint64 values into an hex string
+// without prefix, as used by the statedb service.
+func fea2hex(fea []uint64) string {
+	return strings.TrimPrefix(fea2string(fea), "0x")
+}
+
+func siblingsToPB(siblings map[int][]uint64) map[uint64]*pb.SiblingList {
+	res := make(map[uint64]*pb.SiblingList, len(siblings))
+	for level, sibling := range siblings {
+		res[uint64(level)] = &pb.SiblingList{Sibling: sibling}
+	}
+	return res
+}
+
+func encodeElements(elements []uint64) []byte {
+	data := make([]byte, len(elements)*elementLength)
+	for i, e := range elements {
+		binary.BigEndian.PutUint64(data[i*elementLength:], e)
+	}
+	return data
+}
+
+func decodeElements(data []byte) []uint64 {
+	elements := make([]uint64, len(data)/elementLength)
+	for i := range elements {
+		elements[i] = binary.BigEndian.Uint64(data[i*elementLength:])
+	}
+	return elements
+}
@@ -0,0 +1,192 @@
+package merkletree
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testVectorGenesis struct {
+	Addresses []struct {
+		Address string `json:"address"`
+		Balance string `json:"balance"`
+		Nonce   string `json:"nonce"`
+	} `json:"addresses"`
+	ExpectedRoot string `json:"expectedRoot"`
+}
+
+func newLocalTestTree() (*StateTree, *MemoryStorage) {
+	storage := NewMemoryStorage()
+	return NewStateTree(NewLocalStateDBClient(storage)), storage
+}
+
+func TestLocalStateDBClientRaw(t *testing.T) {
+	data, err := os.ReadFile("test/vectors/src/merkle-tree/smt-raw.json")
+	require.NoError(t, err)
+
+	var testVectors []testVectorRaw
+	err = json.Unmarshal(data, &testVectors)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for ti, testVector := range testVectors {
+		testVector := testVector
+		t.Run(fmt.Sprintf("test vector %d", ti), func(t *testing.T) {
+			tree, _ := newLocalTestTree()
+
+			root := make([]uint64, h4Length)
+			for i := range testVector.Keys {
+				key, ok := new(big.Int).SetString(testVector.Keys[i], 10)
+				require.True(t, ok)
+				value, ok := new(big.Int).SetString(testVector.Values[i], 10)
+				require.True(t, ok)
+
+				updateProof, err := tree.set(ctx, root, scalarToh4(key), scalar2fea(value))
+				require.NoError(t, err)
+				root = updateProof.NewRoot
+			}
+			assert.Equal(t, testVector.ExpectedRoot, h4ToString(root))
+
+			for _, leaf := range loadTestLeaves(t, testVector) {
+				proof, err := tree.get(ctx, root, leaf.key, true)
+				require.NoError(t, err)
+				assert.Equal(t, leaf.value, fea2scalar(proof.Value))
+				assert.NoError(t, VerifyProof(proof))
+			}
+
+			proof, err := tree.get(ctx, root, scalarToh4(big.NewInt(12345)), true) //nolint:gomnd
+			require.NoError(t, err)
+			assert.Equal(t, 0, fea2scalar(proof.Value).Sign())
+			assert.NoError(t, VerifyProof(proof))
+		})
+	}
+}
+
+func TestLocalStateDBClientGenesis(t *testing.T) {
+	data, err := os.ReadFile("test/vectors/src/merkle-tree/smt-genesis.json")
+	require.NoError(t, err)
+
+	var testVectors []testVectorGenesis
+	err = json.Unmarshal(data, &testVectors)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for ti, testVector := range testVectors {
+		testVector := testVector
+		t.Run(fmt.Sprintf("test vector %d", ti), func(t *testing.T) {
+			tree, _ := newLocalTestTree()
+
+			var root []byte
+			for _, account := range testVector.Addresses {
+				address := common.HexToAddress(account.Address)
+				balance, ok := new(big.Int).SetString(account.Balance, 10)
+				require.True(t, ok)
+				nonce, ok := new(big.Int).SetString(account.Nonce, 10)
+				require.True(t, ok)
+
+				root, _, err = tree.SetBalance(ctx, address, balance, root)
+				require.NoError(t, err)
+				root, _, err = tree.SetNonce(ctx, address, nonce, root)
+				require.NoError(t, err)
+			}
+
+			expectedRoot, ok := new(big.Int).SetString(testVector.ExpectedRoot, 10)
+			require.True(t, ok)
+			assert.Equal(t, expectedRoot.Bytes(), new(big.Int).SetBytes(root).Bytes())
+
+			for _, account := range testVector.Addresses {
+				balance, err := tree.GetBalance(ctx, common.HexToAddress(account.Address), root)
+				require.NoError(t, err)
+				assert.Equal(t, account.Balance, balance.String())
+			}
+		})
+	}
+}
+
+func TestLocalStateDBClientDelete(t *testing.T) {
+	ctx := context.Background()
+	tree, _ := newLocalTestTree()
+
+	keys := []int64{0, 1, 2, 3, 4369, 69905, 17185, 16929}
+	roots := [][]uint64{make([]uint64, h4Length)}
+	for i, k := range keys {
+		updateProof, err := tree.set(ctx, roots[i], scalarToh4(big.NewInt(k)), scalar2fea(big.NewInt(k+1)))
+		require.NoError(t, err)
+		roots = append(roots, updateProof.NewRoot)
+	}
+
+	// Removing the keys in the reverse order must go through the same roots
+	root := roots[len(roots)-1]
+	for i := len(keys) - 1; i >= 0; i-- {
+		updateProof, err := tree.set(ctx, root, scalarToh4(big.NewInt(keys[i])), scalar2fea(big.NewInt(0)))
+		require.NoError(t, err)
+		root = updateProof.NewRoot
+		assert.Equal(t, roots[i], root)
+	}
+}
+
+func TestLocalStateDBClientCode(t *testing.T) {
+	ctx := context.Background()
+	tree, storage := newLocalTestTree()
+
+	address := common.HexToAddress("0x1")
+	code := common.FromHex("0x6080604052")
+
+	root, _, err := tree.SetCode(ctx, address, code, nil)
+	require.NoError(t, err)
+
+	storedCode, err := tree.GetCode(ctx, address, root)
+	require.NoError(t, err)
+	assert.Equal(t, code, storedCode)
+
+	// Changes done through an ephemeral tree are not persisted
+	nodes := len(storage.data)
+	ephemeralTree := tree.Ephemeral()
+	ephemeralRoot, _, err := ephemeralTree.SetStorageAt(ctx, address, big.NewInt(1), big.NewInt(2), root)
+	require.NoError(t, err)
+	assert.Equal(t, nodes, len(storage.data))
+
+	value, err := ephemeralTree.GetStorageAt(ctx, address, big.NewInt(1), ephemeralRoot)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), value)
+
+	_, err = tree.GetStorageAt(ctx, address, big.NewInt(1), ephemeralRoot)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStateTreeFlush(t *testing.T) {
+	ctx := context.Background()
+	tree, storage := newLocalTestTree()
+	client := tree.grpcClient.(*LocalStateDBClient)
+
+	address := common.HexToAddress("0x1")
+	root, _, err := tree.SetBalance(ctx, address, big.NewInt(1), nil)
+	require.NoError(t, err)
+	nodes := len(storage.data)
+
+	// the nodes of each ephemeral tree are kept apart, so flushing doesn't
+	// invalidate the roots of the ephemeral trees in use
+	ephemeralTree := tree.Ephemeral()
+	ephemeralRoot, _, err := ephemeralTree.SetNonce(ctx, address, big.NewInt(1), root)
+	require.NoError(t, err)
+	assert.Empty(t, client.temporary.data)
+	assert.NotEmpty(t, ephemeralTree.grpcClient.(*LocalStateDBClient).temporary.data)
+
+	require.NoError(t, tree.Flush(ctx))
+	assert.Equal(t, nodes, len(storage.data))
+
+	nonce, err := ephemeralTree.GetNonce(ctx, address, ephemeralRoot)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), nonce)
+
+	balance, err := tree.GetBalance(ctx, address, root)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), balance)
+}
@@ -0,0 +1,190 @@
+package merkletree
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	poseidon "github.com/iden3/go-iden3-crypto/goldenposeidon"
+)
+
+const (
+	// h4Length is the number of field elements of a hash
+	h4Length = 4
+	// nodeLength is the number of field elements hashed in a node
+	nodeLength = 8
+	// leafLength is the number of field elements stored for a leaf node,
+	// the node elements followed by the capacity used to hash it
+	leafLength = 12
+)
+
+var (
+	// ErrInvalidProof indicates the root computed from the proof siblings
+	// doesn't match the root of the proof
+	ErrInvalidProof = errors.New("invalid merkletree proof")
+	// ErrMalformedProof indicates the proof doesn't have the expected format
+	ErrMalformedProof = errors.New("malformed merkletree proof")
+)
+
+// VerifyProof recomputes the root of the tree from the siblings of the proof
+// and checks it matches the root of the proof. A zero value is proven when
+// the path of the key ends in an empty node or in a leaf of another key.
+func VerifyProof(proof *Proof) error {
+	if proof == nil || len(proof.Root) != h4Length || len(proof.Key) != h4Length {
+		return ErrMalformedProof
+	}
+	for _, sibling := range proof.Siblings {
+		if len(sibling) < nodeLength {
+			return ErrMalformedProof
+		}
+	}
+
+	value := fea2scalar(proof.Value)
+	level := len(proof.Siblings) - 1
+
+	var current []uint64
+	if level < 0 {
+		// An empty tree can only prove zero values
+		if value.Sign() != 0 {
+			return ErrInvalidProof
+		}
+		current = make([]uint64, h4Length)
+	} else {
+		last := proof.Siblings[level]
+		if isLeafNode(last) {
+			if equalH4(last[:4], removeKeyBits(proof.Key, level)) {
+				valueHash, err := hashValue(scalar2fea(value))
+				if err != nil {
+					return err
+				}
+				if value.Sign() == 0 || !equalH4(last[4:8], valueHash) {
+					return ErrInvalidProof
+				}
+			} else if value.Sign() != 0 {
+				// The leaf belongs to another key, so the key is not in the tree
+				return ErrInvalidProof
+			}
+		} else {
+			// The path must end in an empty node for the key not to be in the tree
+			bit := getKeyBit(proof.Key, level)
+			if value.Sign() != 0 || !isZeroH4(last[bit*4:bit*4+4]) {
+				return ErrInvalidProof
+			}
+		}
+
+		var err error
+		current, err = hashNode(last)
+		if err != nil {
+			return err
+		}
+		level--
+	}
+
+	for ; level >= 0; level-- {
+		node := proof.Siblings[level]
+		bit := getKeyBit(proof.Key, level)
+		if isLeafNode(node) || !equalH4(node[bit*4:bit*4+4], current) {
+			return ErrInvalidProof
+		}
+
+		var err error
+		current, err = hashNode(node)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !equalH4(current, proof.Root) {
+		return ErrInvalidProof
+	}
+
+	return nil
+}
+
+// H4ToHash converts an array of 4 field elements into a hash.
+func H4ToHash(h4 []uint64) common.Hash {
+	return common.BytesToHash(h4ToFilledByteSlice(h4))
+}
+
+// FeaToScalar converts an array of 8 field elements of 32 bits into a scalar.
+func FeaToScalar(fea []uint64) *big.Int {
+	return fea2scalar(fea)
+}
+
+// getKeyBit returns the bit of the key used to choose the path at the given
+// level. The bits are taken alternating the elements of the key, starting
+// from the least significant bit of each of them.
+func getKeyBit(key []uint64, level int) int {
+	return int((key[level%h4Length] >> (level / h4Length)) & 1)
+}
+
+// removeKeyBits returns the remaining key of a leaf found at the given level,
+// which is the key without the bits used to reach the leaf.
+func removeKeyBits(key []uint64, level int) []uint64 {
+	fullLevels := level / h4Length
+	rKey := make([]uint64, h4Length)
+	for i := 0; i < h4Length; i++ {
+		n := fullLevels
+		if fullLevels*h4Length+i < level {
+			n++
+		}
+		rKey[i] = key[i] >> n
+	}
+	return rKey
+}
+
+// hashNode computes the hash of a node, using the capacity stored along the
+// node elements for leaf nodes and a zero capacity for intermediate nodes.
+func hashNode(node []uint64) ([]uint64, error) {
+	var elements [nodeLength]uint64
+	var capacity [h4Length]uint64
+	copy(elements[:], node[:nodeLength])
+	if len(node) >= leafLength {
+		copy(capacity[:], node[nodeLength:leafLength])
+	}
+
+	h, err := poseidon.Hash(elements, capacity)
+	if err != nil {
+		return nil, err
+	}
+	return h[:], nil
+}
+
+// hashValue computes the hash of the value stored in a leaf.
+func hashValue(value []uint64) ([]uint64, error) {
+	var elements [nodeLength]uint64
+	copy(elements[:], value)
+
+	h, err := poseidon.Hash(elements, [h4Length]uint64{})
+	if err != nil {
+		return nil, err
+	}
+	return h[:], nil
+}
+
+// isLeafNode checks if a node stored in the tree is a leaf, which is
+// identified by the first element of its capacity set to 1.
+func isLeafNode(node []uint64) bool {
+	return len(node) >= leafLength && node[nodeLength] == 1
+}
+
+func isZeroH4(h4 []uint64) bool {
+	for _, e := range h4 {
+		if e != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func equalH4(a, b []uint64) bool {
+	if len(a) < h4Length || len(b) < h4Length {
+		return false
+	}
+	for i := 0; i < h4Length; i++ {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,163 @@
+package merkletree
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testVectorRaw struct {
+	Keys         []string `json:"keys"`
+	Values       []string `json:"values"`
+	ExpectedRoot string   `json:"expectedRoot"`
+}
+
+type testLeaf struct {
+	key   []uint64
+	value *big.Int
+}
+
+// testNode builds the node stored in the tree for the given leaves at the
+// given level, nil means an empty node
+func testNode(t *testing.T, leaves []testLeaf, level int) []uint64 {
+	switch len(leaves) {
+	case 0:
+		return nil
+	case 1:
+		valueHash, err := hashValue(scalar2fea(leaves[0].value))
+		require.NoError(t, err)
+		node := append(removeKeyBits(leaves[0].key, level), valueHash...)
+		return append(node, 1, 0, 0, 0)
+	}
+
+	var left, right []testLeaf
+	for _, leaf := range leaves {
+		if getKeyBit(leaf.key, level) == 0 {
+			left = append(left, leaf)
+		} else {
+			right = append(right, leaf)
+		}
+	}
+	return append(testNodeHash(t, left, level+1), testNodeHash(t, right, level+1)...)
+}
+
+func testNodeHash(t *testing.T, leaves []testLeaf, level int) []uint64 {
+	node := testNode(t, leaves, level)
+	if node == nil {
+		return make([]uint64, h4Length)
+	}
+	h, err := hashNode(node)
+	require.NoError(t, err)
+	return h
+}
+
+// testProof builds the proof of a key collecting the nodes found in its path
+func testProof(t *testing.T, leaves []testLeaf, key []uint64, value *big.Int) *Proof {
+	proof := &Proof{
+		Root:  testNodeHash(t, leaves, 0),
+		Key:   key,
+		Value: scalar2fea(value),
+	}
+
+	current := leaves
+	for level := 0; ; level++ {
+		node := testNode(t, current, level)
+		if node == nil {
+			break
+		}
+		proof.Siblings = append(proof.Siblings, node)
+		if isLeafNode(node) {
+			break
+		}
+
+		var next []testLeaf
+		for _, leaf := range current {
+			if getKeyBit(leaf.key, level) == getKeyBit(key, level) {
+				next = append(next, leaf)
+			}
+		}
+		current = next
+	}
+
+	return proof
+}
+
+func loadTestLeaves(t *testing.T, testVector testVectorRaw) []testLeaf {
+	values := map[string]*big.Int{}
+	keys := []string{}
+	for i, k := range testVector.Keys {
+		value, ok := new(big.Int).SetString(testVector.Values[i], 10)
+		require.True(t, ok)
+		if _, found := values[k]; !found {
+			keys = append(keys, k)
+		}
+		values[k] = value
+	}
+
+	leaves := []testLeaf{}
+	for _, k := range keys {
+		if values[k].Sign() == 0 {
+			continue
+		}
+		key, ok := new(big.Int).SetString(k, 10)
+		require.True(t, ok)
+		leaves = append(leaves, testLeaf{key: scalarToh4(key), value: values[k]})
+	}
+	return leaves
+}
+
+func TestVerifyProof(t *testing.T) {
+	data, err := os.ReadFile("test/vectors/src/merkle-tree/smt-raw.json")
+	require.NoError(t, err)
+
+	var testVectors []testVectorRaw
+	err = json.Unmarshal(data, &testVectors)
+	require.NoError(t, err)
+
+	for ti, testVector := range testVectors {
+		testVector := testVector
+		t.Run(fmt.Sprintf("test vector %d", ti), func(t *testing.T) {
+			leaves := loadTestLeaves(t, testVector)
+
+			root := testNodeHash(t, leaves, 0)
+			require.Equal(t, testVector.ExpectedRoot, h4ToString(root))
+
+			for _, leaf := range leaves {
+				proof := testProof(t, leaves, leaf.key, leaf.value)
+				assert.NoError(t, VerifyProof(proof))
+
+				proof.Value = scalar2fea(new(big.Int).Add(leaf.value, big.NewInt(1)))
+				assert.ErrorIs(t, VerifyProof(proof), ErrInvalidProof)
+
+				proof.Value = scalar2fea(big.NewInt(0))
+				assert.ErrorIs(t, VerifyProof(proof), ErrInvalidProof)
+
+				proof.Value = scalar2fea(leaf.value)
+				proof.Root = []uint64{root[0] + 1, root[1], root[2], root[3]}
+				assert.ErrorIs(t, VerifyProof(proof), ErrInvalidProof)
+			}
+
+			missingKey := scalarToh4(big.NewInt(12345)) //nolint:gomnd
+			proof := testProof(t, leaves, missingKey, big.NewInt(0))
+			assert.NoError(t, VerifyProof(proof))
+
+			proof.Value = scalar2fea(big.NewInt(1))
+			assert.ErrorIs(t, VerifyProof(proof), ErrInvalidProof)
+		})
+	}
+}
+
+func TestVerifyMalformedProof(t *testing.T) {
+	assert.ErrorIs(t, VerifyProof(nil), ErrMalformedProof)
+	assert.ErrorIs(t, VerifyProof(&Proof{Root: []uint64{0}, Key: make([]uint64, h4Length)}), ErrMalformedProof)
+	assert.ErrorIs(t, VerifyProof(&Proof{
+		Root:     make([]uint64, h4Length),
+		Key:      make([]uint64, h4Length),
+		Siblings: [][]uint64{{1, 2, 3}},
+	}), ErrMalformedProof)
+}
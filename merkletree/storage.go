@@ -0,0 +1,90 @@
+package merkletree
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// ErrNotFound indicates the data was not found in the storage
+var ErrNotFound = errors.New("not found")
+
+const (
+	getMerkleTreeDataSQL = "SELECT data FROM state.merkletree WHERE hash = $1"
+	setMerkleTreeDataSQL = "INSERT INTO state.merkletree (hash, data) VALUES ($1, $2) ON CONFLICT (hash) DO NOTHING"
+)
+
+// Storage keeps the nodes, values and programs of the in-process merkletree
+// indexed by their hash
+type Storage interface {
+	Get(ctx context.Context, hash []byte) ([]byte, error)
+	Set(ctx context.Context, hash []byte, data []byte) error
+}
+
+// MemoryStorage is a Storage kept in memory
+type MemoryStorage struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// NewMemoryStorage creates a new MemoryStorage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		data: make(map[string][]byte),
+	}
+}
+
+// Get returns the data stored for the given hash
+func (m *MemoryStorage) Get(ctx context.Context, hash []byte) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, found := m.data[string(hash)]
+	if !found {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+// Set stores the data for the given hash
+func (m *MemoryStorage) Set(ctx context.Context, hash []byte, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data[string(hash)] = data
+	return nil
+}
+
+// PostgresStorage is a Storage kept in the state.merkletree table
+type PostgresStorage struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresStorage creates a new PostgresStorage
+func NewPostgresStorage(db *pgxpool.Pool) *PostgresStorage {
+	return &PostgresStorage{
+		db: db,
+	}
+}
+
+// Get returns the data stored for the given hash
+func (p *PostgresStorage) Get(ctx context.Context, hash []byte) ([]byte, error) {
+	var data []byte
+	err := p.db.QueryRow(ctx, getMerkleTreeDataSQL, hash).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Set stores the data for the given hash. Since the data is indexed by its
+// hash, data already stored is never modified
+func (p *PostgresStorage) Set(ctx context.Context, hash []byte, data []byte) error {
+	_, err := p.db.Exec(ctx, setMerkleTreeDataSQL, hash, data)
+	return err
+}
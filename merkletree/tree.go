@@ -9,17 +9,56 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/hex"
 	"github.com/0xPolygonHermez/zkevm-node/merkletree/pb"
 	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // StateTree provides methods to access and modify state in merkletree
 type StateTree struct {
 	grpcClient pb.StateDBServiceClient
+	persistent bool
+	cache      *cache
 }
 
 // NewStateTree creates new StateTree.
 func NewStateTree(client pb.StateDBServiceClient) *StateTree {
 	return &StateTree{
 		grpcClient: client,
+		persistent: true,
+	}
+}
+
+// NewStateTreeWithCache creates new StateTree whose reads are cached by root
+// and key, so repeated reads of the same root don't reach the merkletree.
+func NewStateTreeWithCache(client pb.StateDBServiceClient, cfg CacheConfig) (*StateTree, error) {
+	c, err := newCache(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StateTree{
+		grpcClient: client,
+		persistent: true,
+		cache:      c,
+	}, nil
+}
+
+// ephemeralClient is implemented by the clients able to keep the nodes
+// written without persistence of each ephemeral tree apart.
+type ephemeralClient interface {
+	ephemeral() pb.StateDBServiceClient
+}
+
+// Ephemeral returns a StateTree sharing the same storage whose modifications
+// are not persisted, so the roots it generates are only valid temporarily.
+// Since those roots are not kept, the ephemeral tree doesn't cache reads.
+func (tree *StateTree) Ephemeral() *StateTree {
+	client := tree.grpcClient
+	if c, ok := client.(ephemeralClient); ok {
+		client = c.ephemeral()
+	}
+	return &StateTree{
+		grpcClient: client,
+		persistent: false,
 	}
 }
 
@@ -33,7 +72,7 @@ func (tree *StateTree) GetBalance(ctx context.Context, address common.Address, r
 	}
 
 	k := new(big.Int).SetBytes(key[:])
-	proof, err := tree.get(ctx, scalarToh4(r), scalarToh4(k))
+	proof, err := tree.get(ctx, scalarToh4(r), scalarToh4(k), false)
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +92,7 @@ func (tree *StateTree) GetNonce(ctx context.Context, address common.Address, roo
 	}
 
 	k := new(big.Int).SetBytes(key[:])
-	proof, err := tree.get(ctx, scalarToh4(r), scalarToh4(k))
+	proof, err := tree.get(ctx, scalarToh4(r), scalarToh4(k), false)
 	if err != nil {
 		return nil, err
 	}
@@ -73,7 +112,7 @@ func (tree *StateTree) GetCodeHash(ctx context.Context, address common.Address,
 	}
 	// this code gets only the hash of the smart contract code from the merkle tree
 	k := new(big.Int).SetBytes(key[:])
-	proof, err := tree.get(ctx, scalarToh4(r), scalarToh4(k))
+	proof, err := tree.get(ctx, scalarToh4(r), scalarToh4(k), false)
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +152,7 @@ func (tree *StateTree) GetStorageAt(ctx context.Context, address common.Address,
 	}
 
 	k := new(big.Int).SetBytes(key[:])
-	proof, err := tree.get(ctx, scalarToh4(r), scalarToh4(k))
+	proof, err := tree.get(ctx, scalarToh4(r), scalarToh4(k), false)
 	if err != nil {
 		return nil, err
 	}
@@ -123,6 +162,15 @@ func (tree *StateTree) GetStorageAt(ctx context.Context, address common.Address,
 	return fea2scalar(proof.Value), nil
 }
 
+// GetProof returns the proof of the value stored in the given key, including
+// the siblings found in the path from the root to the key.
+func (tree *StateTree) GetProof(ctx context.Context, key []byte, root []byte) (*Proof, error) {
+	r := new(big.Int).SetBytes(root)
+	k := new(big.Int).SetBytes(key)
+
+	return tree.get(ctx, scalarToh4(r), scalarToh4(k), true)
+}
+
 // SetBalance sets balance.
 func (tree *StateTree) SetBalance(ctx context.Context, address common.Address, balance *big.Int, root []byte) (newRoot []byte, proof *UpdateProof, err error) {
 	if balance.Cmp(big.NewInt(0)) == -1 {
@@ -179,7 +227,7 @@ func (tree *StateTree) SetCode(ctx context.Context, address common.Address, code
 	}
 
 	// store smart contract code by its hash
-	err = tree.setProgram(ctx, scCodeHash4, code, true)
+	err = tree.setProgram(ctx, scCodeHash4, code, tree.persistent)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -226,10 +274,21 @@ func (tree *StateTree) SetStorageAt(ctx context.Context, address common.Address,
 	return h4ToFilledByteSlice(updateProof.NewRoot), updateProof, nil
 }
 
-func (tree *StateTree) get(ctx context.Context, root, key []uint64) (*Proof, error) {
+func (tree *StateTree) get(ctx context.Context, root, key []uint64, details bool) (*Proof, error) {
+	if !details {
+		if value, found := tree.cache.getValue(root, key); found {
+			return &Proof{
+				Root:  []uint64{root[0], root[1], root[2], root[3]},
+				Key:   key,
+				Value: value,
+			}, nil
+		}
+	}
+
 	result, err := tree.grpcClient.Get(ctx, &pb.GetRequest{
-		Root: &pb.Fea{Fe0: root[0], Fe1: root[1], Fe2: root[2], Fe3: root[3]},
-		Key:  &pb.Fea{Fe0: key[0], Fe1: key[1], Fe2: key[2], Fe3: key[3]},
+		Root:    &pb.Fea{Fe0: root[0], Fe1: root[1], Fe2: root[2], Fe3: root[3]},
+		Key:     &pb.Fea{Fe0: key[0], Fe1: key[1], Fe2: key[2], Fe3: key[3]},
+		Details: details,
 	})
 	if err != nil {
 		return nil, err
@@ -239,20 +298,58 @@ func (tree *StateTree) get(ctx context.Context, root, key []uint64) (*Proof, err
 	if err != nil {
 		return nil, err
 	}
-	return &Proof{
+	proof := &Proof{
 		Root:  []uint64{root[0], root[1], root[2], root[3]},
 		Key:   key,
 		Value: value,
-	}, nil
+	}
+	// the roots are immutable, so the values read from them are cached unless
+	// the read failed
+	if result.Result == nil || result.Result.Code == pb.ResultCode_CODE_SUCCESS {
+		tree.cache.addValue(root, key, value)
+	}
+	if !details {
+		return proof, nil
+	}
+
+	proof.Siblings = make([][]uint64, len(result.Siblings))
+	for level, siblings := range result.Siblings {
+		if level >= uint64(len(proof.Siblings)) {
+			return nil, fmt.Errorf("unexpected sibling level %d in a path of %d levels", level, len(result.Siblings))
+		}
+		proof.Siblings[level] = siblings.Sibling
+	}
+	if result.InsKey != nil {
+		proof.InsKey = []uint64{result.InsKey.Fe0, result.InsKey.Fe1, result.InsKey.Fe2, result.InsKey.Fe3}
+	}
+	if result.InsValue != "" {
+		proof.InsValue, err = string2fea(result.InsValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+	proof.IsOld0 = result.IsOld0
+
+	return proof, nil
 }
 
 func (tree *StateTree) getProgram(ctx context.Context, key []uint64) (*ProgramProof, error) {
+	if data, found := tree.cache.getProgram(key); found {
+		return &ProgramProof{
+			Data: data,
+		}, nil
+	}
+
 	result, err := tree.grpcClient.GetProgram(ctx, &pb.GetProgramRequest{
 		Key: &pb.Fea{Fe0: key[0], Fe1: key[1], Fe2: key[2], Fe3: key[3]},
 	})
 	if err != nil {
 		return nil, err
 	}
+	// a program not found yet can be set later, so only found ones are cached
+	if result.Result == nil || result.Result.Code == pb.ResultCode_CODE_SUCCESS {
+		tree.cache.addProgram(key, result.Data)
+	}
 
 	return &ProgramProof{
 		Data: result.Data,
@@ -268,7 +365,7 @@ func (tree *StateTree) set(ctx context.Context, oldRoot, key, value []uint64) (*
 		OldRoot:    &pb.Fea{Fe0: oldRoot[0], Fe1: oldRoot[1], Fe2: oldRoot[2], Fe3: oldRoot[3]},
 		Key:        &pb.Fea{Fe0: key[0], Fe1: key[1], Fe2: key[2], Fe3: key[3]},
 		Value:      feaValue,
-		Persistent: true,
+		Persistent: tree.persistent,
 	})
 	if err != nil {
 		return nil, err
@@ -298,3 +395,9 @@ func (tree *StateTree) setProgram(ctx context.Context, key []uint64, data []byte
 	})
 	return err
 }
+
+// Flush waits for the pending writes of the merkletree to be stored.
+func (tree *StateTree) Flush(ctx context.Context) error {
+	_, err := tree.grpcClient.Flush(ctx, &emptypb.Empty{})
+	return err
+}
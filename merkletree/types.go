@@ -24,6 +24,18 @@ type Proof struct {
 	Key []uint64
 	// Value is the proof value.
 	Value []uint64
+	// Siblings are the nodes found in the path from the root to the key,
+	// indexed by level. Only filled when the proof is requested with details.
+	Siblings [][]uint64
+	// InsKey is the key of the leaf found in the path of a key not present
+	// in the tree.
+	InsKey []uint64
+	// InsValue is the value of the leaf found in the path of a key not
+	// present in the tree.
+	InsValue []uint64
+	// IsOld0 indicates the path of a key not present in the tree ends in an
+	// empty node.
+	IsOld0 bool
 }
 
 // UpdateProof is a proof generated on Set operation.
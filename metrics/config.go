@@ -0,0 +1,11 @@
+package metrics
+
+// Config represents the configuration of the metrics server
+type Config struct {
+	// Host is the address where the metrics are exposed
+	Host string `mapstructure:"Host"`
+	// Port is the port where the metrics are exposed
+	Port int `mapstructure:"Port"`
+	// Enabled enables the metrics server
+	Enabled bool `mapstructure:"Enabled"`
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	gethmetrics "github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
+)
+
+const endpoint = "/metrics"
+
+// GetOrRegisterCounter returns the counter registered with the given name,
+// creating it if needed. Counters are always collected, no matter if the
+// metrics server is enabled or not.
+func GetOrRegisterCounter(name string) gethmetrics.Counter {
+	return gethmetrics.GetOrRegisterCounterForced(name, nil)
+}
+
+// GetOrRegisterGauge returns the gauge registered with the given name,
+// creating it if needed. Gauges are always collected, no matter if the
+// metrics server is enabled or not.
+func GetOrRegisterGauge(name string) gethmetrics.Gauge {
+	return gethmetrics.DefaultRegistry.GetOrRegister(name, func() gethmetrics.Gauge {
+		return &gethmetrics.StandardGauge{}
+	}).(gethmetrics.Gauge)
+}
+
+// StartServer exposes the registered metrics in prometheus format
+func StartServer(cfg Config) error {
+	address := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+	log.Infof("metrics server started: %s%s", address, endpoint)
+
+	mux := http.NewServeMux()
+	mux.Handle(endpoint, prometheus.Handler(gethmetrics.DefaultRegistry))
+
+	if err := http.ListenAndServe(address, mux); err != nil { //nolint:gosec
+		log.Errorf("closed metrics http connection: %v", err)
+		return err
+	}
+	return nil
+}
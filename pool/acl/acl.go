@@ -0,0 +1,176 @@
+package acl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// ErrSenderNotAllowed is returned if the sender of a transaction is not
+	// allowed to send transactions.
+	ErrSenderNotAllowed = errors.New("sender not allowed")
+
+	// ErrRecipientNotAllowed is returned if the recipient of a transaction is
+	// not allowed to receive transactions.
+	ErrRecipientNotAllowed = errors.New("recipient not allowed")
+
+	// ErrContractCreationNotAllowed is returned if the sender of a contract
+	// creation transaction is not allowed to deploy contracts.
+	ErrContractCreationNotAllowed = errors.New("contract creation not allowed")
+)
+
+// Lists are the allow and deny lists of senders, recipients and contract
+// deployers. When an allow list is not empty only the addresses in it are
+// allowed, the addresses in a deny list are never allowed
+type Lists struct {
+	AllowedSenders    []common.Address `json:"allowedSenders"`
+	DeniedSenders     []common.Address `json:"deniedSenders"`
+	AllowedRecipients []common.Address `json:"allowedRecipients"`
+	DeniedRecipients  []common.Address `json:"deniedRecipients"`
+	AllowedDeployers  []common.Address `json:"allowedDeployers"`
+	DeniedDeployers   []common.Address `json:"deniedDeployers"`
+}
+
+type list struct {
+	allowed, denied map[common.Address]struct{}
+}
+
+func newList(allowed, denied []common.Address) list {
+	l := list{
+		allowed: make(map[common.Address]struct{}, len(allowed)),
+		denied:  make(map[common.Address]struct{}, len(denied)),
+	}
+	for _, addr := range allowed {
+		l.allowed[addr] = struct{}{}
+	}
+	for _, addr := range denied {
+		l.denied[addr] = struct{}{}
+	}
+	return l
+}
+
+func (l list) isAllowed(addr common.Address) bool {
+	if _, ok := l.denied[addr]; ok {
+		return false
+	}
+	if len(l.allowed) == 0 {
+		return true
+	}
+	_, ok := l.allowed[addr]
+	return ok
+}
+
+// ACL enforces the allow and deny lists on the txs, the lists are reloaded
+// periodically from a file or from the pool db
+type ACL struct {
+	cfg     Config
+	storage storage
+
+	mu                             sync.RWMutex
+	senders, recipients, deployers list
+}
+
+// New creates an ACL and loads its lists
+func New(cfg Config, storage storage) (*ACL, error) {
+	a := &ACL{
+		cfg:     cfg,
+		storage: storage,
+	}
+	if !cfg.Enabled {
+		return a, nil
+	}
+	if err := a.load(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to load the access control lists, err: %v", err)
+	}
+	return a, nil
+}
+
+// Start reloads the lists on each interval until the context is done. When
+// a reload fails the previous lists are kept
+func (a *ACL) Start(ctx context.Context) {
+	if !a.cfg.Enabled {
+		return
+	}
+	ticker := time.NewTicker(a.cfg.ReloadInterval.Duration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.load(ctx); err != nil {
+				log.Errorf("failed to reload the access control lists, err: %v", err)
+			}
+		}
+	}
+}
+
+// CheckTx checks that the sender is allowed to send the tx to the given
+// recipient, a nil recipient being a contract creation. A nil ACL allows
+// every tx
+func (a *ACL) CheckTx(sender common.Address, to *common.Address) error {
+	if a == nil || !a.cfg.Enabled {
+		return nil
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.senders.isAllowed(sender) {
+		return ErrSenderNotAllowed
+	}
+	if to == nil {
+		if !a.deployers.isAllowed(sender) {
+			return ErrContractCreationNotAllowed
+		}
+		return nil
+	}
+	if !a.recipients.isAllowed(*to) {
+		return ErrRecipientNotAllowed
+	}
+	return nil
+}
+
+func (a *ACL) load(ctx context.Context) error {
+	var (
+		lists *Lists
+		err   error
+	)
+	if a.cfg.FilePath != "" {
+		lists, err = loadFile(a.cfg.FilePath)
+	} else {
+		lists, err = a.storage.GetACL(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	senders := newList(lists.AllowedSenders, lists.DeniedSenders)
+	recipients := newList(lists.AllowedRecipients, lists.DeniedRecipients)
+	deployers := newList(lists.AllowedDeployers, lists.DeniedDeployers)
+
+	a.mu.Lock()
+	a.senders, a.recipients, a.deployers = senders, recipients, deployers
+	a.mu.Unlock()
+	return nil
+}
+
+func loadFile(path string) (*Lists, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lists Lists
+	if err := json.Unmarshal(b, &lists); err != nil {
+		return nil, err
+	}
+	return &lists, nil
+}
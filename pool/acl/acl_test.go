@@ -0,0 +1,73 @@
+package acl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	addr1 = common.HexToAddress("0x1")
+	addr2 = common.HexToAddress("0x2")
+	addr3 = common.HexToAddress("0x3")
+)
+
+func TestCheckTx(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acl.json")
+	lists := `{
+		"allowedSenders": ["0x0000000000000000000000000000000000000001", "0x0000000000000000000000000000000000000002"],
+		"deniedRecipients": ["0x0000000000000000000000000000000000000003"],
+		"allowedDeployers": ["0x0000000000000000000000000000000000000001"]
+	}`
+	require.NoError(t, os.WriteFile(path, []byte(lists), 0600)) //nolint:gomnd
+
+	a, err := New(Config{Enabled: true, FilePath: path}, nil)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name          string
+		Sender        common.Address
+		To            *common.Address
+		ExpectedError error
+	}{
+		{Name: "allowed sender", Sender: addr1, To: &addr2},
+		{Name: "sender not in the allow list", Sender: addr3, To: &addr2, ExpectedError: ErrSenderNotAllowed},
+		{Name: "denied recipient", Sender: addr1, To: &addr3, ExpectedError: ErrRecipientNotAllowed},
+		{Name: "allowed deployer", Sender: addr1},
+		{Name: "deployer not in the allow list", Sender: addr2, ExpectedError: ErrContractCreationNotAllowed},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.ExpectedError, a.CheckTx(tc.Sender, tc.To))
+		})
+	}
+
+	t.Run("reload the lists", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(`{"deniedSenders": ["0x0000000000000000000000000000000000000001"]}`), 0600)) //nolint:gomnd
+		require.NoError(t, a.load(context.Background()))
+		assert.Equal(t, ErrSenderNotAllowed, a.CheckTx(addr1, &addr2))
+		assert.NoError(t, a.CheckTx(addr3, nil))
+	})
+
+	t.Run("keep the lists when the reload fails", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(`{`), 0600)) //nolint:gomnd
+		require.Error(t, a.load(context.Background()))
+		assert.Equal(t, ErrSenderNotAllowed, a.CheckTx(addr1, &addr2))
+	})
+}
+
+func TestCheckTxDisabled(t *testing.T) {
+	var nilACL *ACL
+	assert.NoError(t, nilACL.CheckTx(addr1, nil))
+
+	a, err := New(Config{Enabled: false, FilePath: "missing.json"}, nil)
+	require.NoError(t, err)
+	assert.NoError(t, a.CheckTx(addr1, nil))
+}
@@ -0,0 +1,20 @@
+package acl
+
+import (
+	"github.com/0xPolygonHermez/zkevm-node/config/types"
+)
+
+// Config represents the configuration of the access control lists
+type Config struct {
+	// Enabled enables the access control lists, when disabled every tx is
+	// allowed
+	Enabled bool `mapstructure:"Enabled"`
+
+	// FilePath is the path of the JSON file with the lists, when empty the
+	// lists are loaded from the pool.acl table
+	FilePath string `mapstructure:"FilePath"`
+
+	// ReloadInterval is the interval at which the lists are reloaded, so they
+	// can be changed without restarting the node
+	ReloadInterval types.Duration `mapstructure:"ReloadInterval"`
+}
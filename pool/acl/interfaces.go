@@ -0,0 +1,10 @@
+package acl
+
+import (
+	"context"
+)
+
+// storage contains the methods required to load the lists from the pool db
+type storage interface {
+	GetACL(ctx context.Context) (*Lists, error)
+}
@@ -7,6 +7,9 @@ import (
 )
 
 var (
+	// ErrNotFound is returned if the requested tx is not in the pool.
+	ErrNotFound = errors.New("not found")
+
 	// ErrTxTypeNotSupported is returned if a transaction is not supported in the
 	// current network configuration.
 	ErrTxTypeNotSupported = types.ErrTxTypeNotSupported
@@ -27,6 +30,23 @@ var (
 	// one present in the local chain.
 	ErrNonceTooLow = errors.New("nonce too low")
 
+	// ErrOutOfCounters is returned if the zk counters used by a transaction
+	// exceed the ones a batch can handle, so it could never be sequenced.
+	ErrOutOfCounters = errors.New("the transaction doesn't fit in a batch, it exceeds the batch zk counters")
+
+	// ErrInvalidClaim is returned if the data of a claim transaction can't be
+	// decoded as a call to the claim method of the bridge.
+	ErrInvalidClaim = errors.New("invalid claim")
+
+	// ErrFreeClaimFailed is returned if a claim transaction sent with zero gas
+	// price fails when it is executed, so it would be sequenced for free
+	// without claiming anything.
+	ErrFreeClaimFailed = errors.New("the claim transaction fails and doesn't pay for gas")
+
+	// ErrGasPriceTooLow is returned if the gas price of a transaction is lower
+	// than the minimum gas price accepted by the pool.
+	ErrGasPriceTooLow = errors.New("gas price too low")
+
 	// ErrInsufficientFunds is returned if the total cost of executing a transaction
 	// is higher than the balance of the user's account.
 	ErrInsufficientFunds = errors.New("insufficient funds for gas * price + value")
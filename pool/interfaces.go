@@ -5,7 +5,9 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/jackc/pgx/v4"
 )
 
@@ -19,13 +21,19 @@ type storage interface {
 	CountTransactionsByState(ctx context.Context, state TxState) (uint64, error)
 	GetPendingTxHashesSince(ctx context.Context, since time.Time) ([]common.Hash, error)
 	IsTxPending(ctx context.Context, hash common.Hash) (bool, error)
+	GetTxState(ctx context.Context, hash common.Hash) (TxState, error)
 	DeleteTxsByHashes(ctx context.Context, hashes []common.Hash) error
 	MarkReorgedTxsAsPending(ctx context.Context) error
 	GetTopPendingTxByProfitabilityAndZkCounters(ctx context.Context, maxZkCounters ZkCounters) (*Transaction, error)
+	GetPendingTxsFittingZkCounters(ctx context.Context, maxZkCounters ZkCounters, limit uint64) ([]Transaction, error)
+	GetNonce(ctx context.Context, address common.Address, stateNonce uint64) (uint64, error)
+	GetPendingTxsNotPreExecuted(ctx context.Context) ([]Transaction, error)
+	UpdateTxZkCounters(ctx context.Context, hash common.Hash, zkCounters ZkCounters) error
 }
 
 type stateInterface interface {
 	GetLastL2BlockNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error)
 	GetNonce(ctx context.Context, address common.Address, batchNumber uint64, dbTx pgx.Tx) (uint64, error)
 	GetBalance(ctx context.Context, address common.Address, batchNumber uint64, dbTx pgx.Tx) (*big.Int, error)
+	PreProcessTransaction(ctx context.Context, tx *types.Transaction, dbTx pgx.Tx) (*state.ProcessBatchResponse, error)
 }
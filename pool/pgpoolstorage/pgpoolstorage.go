@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/0xPolygonHermez/zkevm-node/db"
 	"github.com/0xPolygonHermez/zkevm-node/hex"
 	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/pool/acl"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/jackc/pgx/v4"
@@ -54,6 +56,11 @@ func (p *PostgresPoolStorage) AddTx(ctx context.Context, tx pool.Transaction) er
 	}
 	decoded := string(b)
 
+	from, err := state.GetSender(tx.Transaction)
+	if err != nil {
+		return err
+	}
+
 	gasPrice := tx.GasPrice().Uint64()
 	nonce := tx.Nonce()
 	sql := `
@@ -62,6 +69,7 @@ func (p *PostgresPoolStorage) AddTx(ctx context.Context, tx pool.Transaction) er
 			hash,
 			encoded,
 			decoded,
+			from_address,
 			state,
 			gas_price,
 			nonce,
@@ -74,15 +82,17 @@ func (p *PostgresPoolStorage) AddTx(ctx context.Context, tx pool.Transaction) er
 			used_arithmetics,
 			used_binaries,
 			used_steps,
+			is_pre_executed,
 			received_at
 		) 
 		VALUES 
-			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 	`
 	if _, err := p.db.Exec(ctx, sql,
 		hash,
 		encoded,
 		decoded,
+		from.String(),
 		tx.State,
 		gasPrice,
 		nonce,
@@ -95,6 +105,7 @@ func (p *PostgresPoolStorage) AddTx(ctx context.Context, tx pool.Transaction) er
 		tx.UsedArithmetics,
 		tx.UsedBinaries,
 		tx.UsedSteps,
+		tx.IsPreExecuted,
 		tx.ReceivedAt); err != nil {
 		return err
 	}
@@ -112,18 +123,37 @@ func (p *PostgresPoolStorage) MarkReorgedTxsAsPending(ctx context.Context) error
 
 // GetTxsByState returns an array of transactions filtered by state
 // limit parameter is used to limit amount txs from the db,
-// if limit = 0, then there is no limit
+// if limit = 0, then there is no limit. The txs paying the same gas price are
+// ordered by sender and nonce
 func (p *PostgresPoolStorage) GetTxsByState(ctx context.Context, state pool.TxState, isClaims bool, limit uint64) ([]pool.Transaction, error) {
 	var (
 		rows pgx.Rows
 		err  error
 		sql  string
 	)
+	const selectTxs = `
+		SELECT
+			encoded,
+			state,
+			is_claims,
+			cumulative_gas_used,
+			used_keccak_hashes,
+			used_poseidon_hashes,
+			used_poseidon_paddings,
+			used_mem_aligns,
+			used_arithmetics,
+			used_binaries,
+			used_steps,
+			is_pre_executed,
+			received_at
+		FROM
+			pool.txs
+	`
 	if limit == 0 {
-		sql = "SELECT encoded, state, received_at FROM pool.txs WHERE state = $1 ORDER BY gas_price DESC"
+		sql = selectTxs + "WHERE state = $1 ORDER BY gas_price DESC, from_address, nonce"
 		rows, err = p.db.Query(ctx, sql, state.String())
 	} else {
-		sql = "SELECT encoded, state, received_at FROM pool.txs WHERE state = $1 AND is_claims = $2 ORDER BY gas_price DESC LIMIT $3"
+		sql = selectTxs + "WHERE state = $1 AND is_claims = $2 ORDER BY gas_price DESC, from_address, nonce LIMIT $3"
 		rows, err = p.db.Query(ctx, sql, state.String(), isClaims, limit)
 	}
 	if err != nil {
@@ -133,30 +163,15 @@ func (p *PostgresPoolStorage) GetTxsByState(ctx context.Context, state pool.TxSt
 
 	txs := make([]pool.Transaction, 0, len(rows.RawValues()))
 	for rows.Next() {
-		var (
-			encoded, state string
-			receivedAt     time.Time
-		)
-
-		if err := rows.Scan(&encoded, &state, &receivedAt); err != nil {
-			return nil, err
-		}
-
-		tx := new(pool.Transaction)
-
-		b, err := hex.DecodeHex(encoded)
+		tx, err := scanTxWithZkCounters(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		if err := tx.UnmarshalBinary(b); err != nil {
-			return nil, err
-		}
-
-		tx.State = pool.TxState(state)
-		tx.ReceivedAt = receivedAt
 		txs = append(txs, *tx)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	return txs, nil
 }
@@ -182,12 +197,15 @@ func (p *PostgresPoolStorage) GetPendingTxHashesSince(ctx context.Context, since
 	return hashes, nil
 }
 
-// GetTopPendingTxByProfitabilityAndZkCounters gets top pending tx by profitability and zk counter
+// GetTopPendingTxByProfitabilityAndZkCounters gets top pending tx by profitability and zk counter,
+// claim txs are left out as they are selected by the claims lane of the sequencer, and
+// so are the txs whose zk counters are still unknown
 func (p *PostgresPoolStorage) GetTopPendingTxByProfitabilityAndZkCounters(ctx context.Context, maxZkCounters pool.ZkCounters) (*pool.Transaction, error) {
 	sql := `
 		SELECT 
 			encoded, 
 			state,
+			is_claims,
 			cumulative_gas_used,
 			used_keccak_hashes,
 			used_poseidon_hashes,
@@ -196,11 +214,14 @@ func (p *PostgresPoolStorage) GetTopPendingTxByProfitabilityAndZkCounters(ctx co
 			used_arithmetics,
 			used_binaries,
 			used_steps,
+			is_pre_executed,
 			received_at 
 		FROM
 			pool.txs 
 		WHERE 
 			state = $1 AND 
+			is_claims = false AND
+			is_pre_executed = true AND
 			cumulative_gas_used < $2 AND 
 			used_keccak_hashes < $3 AND 
 			used_poseidon_hashes < $4 AND 
@@ -212,15 +233,7 @@ func (p *PostgresPoolStorage) GetTopPendingTxByProfitabilityAndZkCounters(ctx co
 		ORDER BY gas_price DESC
 		LIMIT 1
 	`
-	var (
-		encoded, state    string
-		receivedAt        time.Time
-		cumulativeGasUsed int64
-
-		usedKeccakHashes, usedPoseidonHashes, usedPoseidonPaddings,
-		usedMemAligns, usedArithmetics, usedBinaries, usedSteps int32
-	)
-	err := p.db.QueryRow(ctx, sql,
+	row := p.db.QueryRow(ctx, sql,
 		pool.TxStatePending,
 		maxZkCounters.CumulativeGasUsed,
 		maxZkCounters.UsedKeccakHashes,
@@ -229,25 +242,113 @@ func (p *PostgresPoolStorage) GetTopPendingTxByProfitabilityAndZkCounters(ctx co
 		maxZkCounters.UsedMemAligns,
 		maxZkCounters.UsedArithmetics,
 		maxZkCounters.UsedBinaries,
-		maxZkCounters.UsedSteps).
-		Scan(&encoded,
-			&state,
-			&cumulativeGasUsed,
-			&usedKeccakHashes,
-			&usedPoseidonHashes,
-			&usedPoseidonPaddings,
-			&usedMemAligns,
-			&usedArithmetics,
-			&usedBinaries,
-			&usedSteps,
-			&receivedAt)
+		maxZkCounters.UsedSteps)
 
+	tx, err := scanTxWithZkCounters(row)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrNotFound
 	} else if err != nil {
 		return nil, err
 	}
 
+	return tx, nil
+}
+
+// GetPendingTxsFittingZkCounters gets up to limit pending txs that fit in the
+// given zk counters, ordered by gas price. Claim txs are left out as they are
+// selected by the claims lane of the sequencer, and so are the txs whose zk
+// counters are still unknown
+func (p *PostgresPoolStorage) GetPendingTxsFittingZkCounters(ctx context.Context, maxZkCounters pool.ZkCounters, limit uint64) ([]pool.Transaction, error) {
+	sql := `
+		SELECT 
+			encoded, 
+			state,
+			is_claims,
+			cumulative_gas_used,
+			used_keccak_hashes,
+			used_poseidon_hashes,
+			used_poseidon_paddings, 
+			used_mem_aligns,
+			used_arithmetics,
+			used_binaries,
+			used_steps,
+			is_pre_executed,
+			received_at 
+		FROM
+			pool.txs 
+		WHERE 
+			state = $1 AND 
+			is_claims = false AND
+			is_pre_executed = true AND
+			cumulative_gas_used <= $2 AND 
+			used_keccak_hashes <= $3 AND 
+			used_poseidon_hashes <= $4 AND 
+			used_poseidon_paddings <= $5 AND
+			used_mem_aligns <= $6 AND 
+			used_arithmetics <= $7 AND
+			used_binaries <= $8 AND 
+			used_steps <= $9
+		ORDER BY gas_price DESC
+		LIMIT $10
+	`
+	rows, err := p.db.Query(ctx, sql,
+		pool.TxStatePending,
+		maxZkCounters.CumulativeGasUsed,
+		maxZkCounters.UsedKeccakHashes,
+		maxZkCounters.UsedPoseidonHashes,
+		maxZkCounters.UsedPoseidonPaddings,
+		maxZkCounters.UsedMemAligns,
+		maxZkCounters.UsedArithmetics,
+		maxZkCounters.UsedBinaries,
+		maxZkCounters.UsedSteps,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	txs := make([]pool.Transaction, 0, limit)
+	for rows.Next() {
+		tx, err := scanTxWithZkCounters(rows)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, *tx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+func scanTxWithZkCounters(row pgx.Row) (*pool.Transaction, error) {
+	var (
+		encoded, state          string
+		isClaims, isPreExecuted bool
+		receivedAt              time.Time
+		cumulativeGasUsed       int64
+
+		usedKeccakHashes, usedPoseidonHashes, usedPoseidonPaddings,
+		usedMemAligns, usedArithmetics, usedBinaries, usedSteps int32
+	)
+	err := row.Scan(&encoded,
+		&state,
+		&isClaims,
+		&cumulativeGasUsed,
+		&usedKeccakHashes,
+		&usedPoseidonHashes,
+		&usedPoseidonPaddings,
+		&usedMemAligns,
+		&usedArithmetics,
+		&usedBinaries,
+		&usedSteps,
+		&isPreExecuted,
+		&receivedAt)
+	if err != nil {
+		return nil, err
+	}
+
 	tx := new(pool.Transaction)
 	b, err := hex.DecodeHex(encoded)
 	if err != nil {
@@ -258,6 +359,8 @@ func (p *PostgresPoolStorage) GetTopPendingTxByProfitabilityAndZkCounters(ctx co
 	}
 
 	tx.State = pool.TxState(state)
+	tx.IsClaims = isClaims
+	tx.IsPreExecuted = isPreExecuted
 	tx.ReceivedAt = receivedAt
 	tx.ZkCounters = pool.ZkCounters{
 		CumulativeGasUsed:    cumulativeGasUsed,
@@ -273,6 +376,91 @@ func (p *PostgresPoolStorage) GetTopPendingTxByProfitabilityAndZkCounters(ctx co
 	return tx, nil
 }
 
+// GetPendingTxsNotPreExecuted returns the pending tx with the lowest nonce of
+// each sender among the ones whose zk counters are still unknown
+func (p *PostgresPoolStorage) GetPendingTxsNotPreExecuted(ctx context.Context) ([]pool.Transaction, error) {
+	sql := `
+		SELECT DISTINCT ON (from_address)
+			encoded,
+			state,
+			is_claims,
+			received_at
+		FROM
+			pool.txs
+		WHERE
+			state = $1 AND
+			is_pre_executed = false
+		ORDER BY from_address, nonce
+	`
+	rows, err := p.db.Query(ctx, sql, pool.TxStatePending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	txs := make([]pool.Transaction, 0, len(rows.RawValues()))
+	for rows.Next() {
+		var (
+			encoded, state string
+			isClaims       bool
+			receivedAt     time.Time
+		)
+		if err := rows.Scan(&encoded, &state, &isClaims, &receivedAt); err != nil {
+			return nil, err
+		}
+
+		tx := new(pool.Transaction)
+		b, err := hex.DecodeHex(encoded)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.UnmarshalBinary(b); err != nil {
+			return nil, err
+		}
+
+		tx.State = pool.TxState(state)
+		tx.IsClaims = isClaims
+		tx.ReceivedAt = receivedAt
+		txs = append(txs, *tx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// UpdateTxZkCounters stores the zk counters the tx with the given hash uses,
+// once it was pre-executed
+func (p *PostgresPoolStorage) UpdateTxZkCounters(ctx context.Context, hash common.Hash, zkCounters pool.ZkCounters) error {
+	sql := `
+		UPDATE pool.txs SET
+			cumulative_gas_used = $1,
+			used_keccak_hashes = $2,
+			used_poseidon_hashes = $3,
+			used_poseidon_paddings = $4,
+			used_mem_aligns = $5,
+			used_arithmetics = $6,
+			used_binaries = $7,
+			used_steps = $8,
+			is_pre_executed = true
+		WHERE hash = $9
+	`
+	if _, err := p.db.Exec(ctx, sql,
+		zkCounters.CumulativeGasUsed,
+		zkCounters.UsedKeccakHashes,
+		zkCounters.UsedPoseidonHashes,
+		zkCounters.UsedPoseidonPaddings,
+		zkCounters.UsedMemAligns,
+		zkCounters.UsedArithmetics,
+		zkCounters.UsedBinaries,
+		zkCounters.UsedSteps,
+		hash.Hex()); err != nil {
+		return err
+	}
+	return nil
+}
+
 // CountTransactionsByState get number of transactions
 // accordingly to the provided state
 func (p *PostgresPoolStorage) CountTransactionsByState(ctx context.Context, state pool.TxState) (uint64, error) {
@@ -323,9 +511,14 @@ func (p *PostgresPoolStorage) DeleteTxsByHashes(ctx context.Context, hashes []co
 	return nil
 }
 
-// SetGasPrice allows an external component to define the gas price
+// SetGasPrice allows an external component to define the gas price, the
+// previous gas prices are removed
 func (p *PostgresPoolStorage) SetGasPrice(ctx context.Context, gasPrice uint64) error {
-	sql := "INSERT INTO pool.gas_price (price, timestamp) VALUES ($1, $2)"
+	sql := `
+		WITH new_gas_price AS (
+			INSERT INTO pool.gas_price (price, timestamp) VALUES ($1, $2) RETURNING item_id
+		)
+		DELETE FROM pool.gas_price WHERE item_id < (SELECT item_id FROM new_gas_price)`
 	if _, err := p.db.Exec(ctx, sql, gasPrice, time.Now().UTC()); err != nil {
 		return err
 	}
@@ -356,6 +549,19 @@ func (p *PostgresPoolStorage) GetGasPrice(ctx context.Context) (uint64, error) {
 	return gasPrice, nil
 }
 
+// GetTxState returns the state of the tx with the given hash in the pool
+func (p *PostgresPoolStorage) GetTxState(ctx context.Context, hash common.Hash) (pool.TxState, error) {
+	var state string
+	sql := "SELECT state FROM pool.txs WHERE hash = $1"
+	err := p.db.QueryRow(ctx, sql, hash.Hex()).Scan(&state)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", pool.ErrNotFound
+	} else if err != nil {
+		return "", err
+	}
+	return pool.TxState(state), nil
+}
+
 // IsTxPending determines if the tx associated to the given hash is pending or
 // not.
 func (p *PostgresPoolStorage) IsTxPending(ctx context.Context, hash common.Hash) (bool, error) {
@@ -368,3 +574,73 @@ func (p *PostgresPoolStorage) IsTxPending(ctx context.Context, hash common.Hash)
 
 	return exists, nil
 }
+
+// GetNonce returns the nonce following the pending txs sent by the given
+// address whose nonces run on without gaps from the given nonce of the
+// address in the state, the given nonce if there are no such txs
+func (p *PostgresPoolStorage) GetNonce(ctx context.Context, address common.Address, stateNonce uint64) (uint64, error) {
+	sql := "SELECT DISTINCT nonce FROM pool.txs WHERE from_address = $1 AND state = $2 AND nonce >= $3 ORDER BY nonce ASC"
+	rows, err := p.db.Query(ctx, sql, address.String(), pool.TxStatePending, stateNonce)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	nonce := stateNonce
+	for rows.Next() {
+		var txNonce uint64
+		if err := rows.Scan(&txNonce); err != nil {
+			return 0, err
+		}
+		if txNonce != nonce {
+			// the txs after a gap can't be processed until it is filled
+			break
+		}
+		nonce++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	return nonce, nil
+}
+
+// GetACL returns the access control lists stored in the pool.acl table
+func (p *PostgresPoolStorage) GetACL(ctx context.Context) (*acl.Lists, error) {
+	sql := "SELECT address, list FROM pool.acl"
+	rows, err := p.db.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := new(acl.Lists)
+	for rows.Next() {
+		var address, list string
+		if err := rows.Scan(&address, &list); err != nil {
+			return nil, err
+		}
+		addr := common.HexToAddress(address)
+		switch list {
+		case "allowed_sender":
+			lists.AllowedSenders = append(lists.AllowedSenders, addr)
+		case "denied_sender":
+			lists.DeniedSenders = append(lists.DeniedSenders, addr)
+		case "allowed_recipient":
+			lists.AllowedRecipients = append(lists.AllowedRecipients, addr)
+		case "denied_recipient":
+			lists.DeniedRecipients = append(lists.DeniedRecipients, addr)
+		case "allowed_deployer":
+			lists.AllowedDeployers = append(lists.AllowedDeployers, addr)
+		case "denied_deployer":
+			lists.DeniedDeployers = append(lists.DeniedDeployers, addr)
+		default:
+			return nil, fmt.Errorf("unknown access control list %q for address %s", list, address)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lists, nil
+}
@@ -2,8 +2,11 @@ package pool
 
 import (
 	"context"
+	"errors"
+	"math/big"
 	"time"
 
+	"github.com/0xPolygonHermez/zkevm-node/pool/acl"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -21,34 +24,38 @@ const (
 	// more expensive to propagate; larger transactions also take more resources
 	// to validate whether they fit into the pool or not.
 	txMaxSize = 4 * txSlotSize // 128KB
-
-	// bridgeClaimMethodSignature for tracking bridgeClaimMethodSignature method
-	bridgeClaimMethodSignature = "0x122650ff"
 )
 
 // Pool is an implementation of the Pool interface
 // that uses a postgres database to store the data
 type Pool struct {
 	storage
-	state                       stateInterface
-	l2GlobalExitRootManagerAddr common.Address
+	state            stateInterface
+	l2BridgeAddr     common.Address
+	maxZkCounters    ZkCounters
+	acl              *acl.ACL
+	checkMinGasPrice bool
 }
 
-// NewPool creates and initializes an instance of Pool
-func NewPool(s storage, st stateInterface, l2GlobalExitRootManagerAddr common.Address) *Pool {
+// NewPool creates and initializes an instance of Pool, l2BridgeAddr is the
+// address of the bridge the claim txs are sent to, maxZkCounters are the zk
+// counters a batch can handle and acl the access control lists the txs have
+// to comply with, nil to allow every tx. When checkMinGasPrice is set, the
+// txs have to pay at least the gas price of the pool, that is only a minimum
+// when it is derived from the l1 gas price
+func NewPool(s storage, st stateInterface, l2BridgeAddr common.Address, maxZkCounters ZkCounters, acl *acl.ACL, checkMinGasPrice bool) *Pool {
 	return &Pool{
-		storage:                     s,
-		state:                       st,
-		l2GlobalExitRootManagerAddr: l2GlobalExitRootManagerAddr,
+		storage:          s,
+		state:            st,
+		l2BridgeAddr:     l2BridgeAddr,
+		maxZkCounters:    maxZkCounters,
+		acl:              acl,
+		checkMinGasPrice: checkMinGasPrice,
 	}
 }
 
 // AddTx adds a transaction to the pool with the pending state
 func (p *Pool) AddTx(ctx context.Context, tx types.Transaction) error {
-	if err := p.validateTx(ctx, tx); err != nil {
-		return err
-	}
-
 	poolTx := Transaction{
 		Transaction: tx,
 		State:       TxStatePending,
@@ -56,11 +63,73 @@ func (p *Pool) AddTx(ctx context.Context, tx types.Transaction) error {
 		ReceivedAt:  time.Now(),
 	}
 
-	poolTx.IsClaims = poolTx.IsClaimTx(p.l2GlobalExitRootManagerAddr)
+	poolTx.IsClaims = poolTx.IsClaimTx(p.l2BridgeAddr)
+
+	if err := p.validateTx(ctx, poolTx); err != nil {
+		return err
+	}
+
+	// a tx with a nonce ahead of the state fails early when it is executed,
+	// so its zk counters are unknown until the txs before it are processed
+	stateNonce, err := p.getStateNonce(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if tx.Nonce() == stateNonce {
+		zkCounters, err := p.preExecuteTx(ctx, poolTx)
+		if err != nil {
+			return err
+		}
+		poolTx.ZkCounters = zkCounters
+		poolTx.IsPreExecuted = true
+	}
 
 	return p.storage.AddTx(ctx, poolTx)
 }
 
+// PreExecutePendingTxs pre-executes the pending txs added to the pool with a
+// nonce ahead of the state once the txs before them are processed, to get the
+// zk counters they use. The ones that use more counters than a batch can
+// handle or are free claims that fail are marked as invalid, and so are the
+// ones whose nonce was taken by another tx in the meantime
+func (p *Pool) PreExecutePendingTxs(ctx context.Context) error {
+	txs, err := p.storage.GetPendingTxsNotPreExecuted(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, tx := range txs {
+		stateNonce, err := p.getStateNonce(ctx, tx.Transaction)
+		if err != nil {
+			return err
+		}
+		if tx.Nonce() > stateNonce {
+			continue
+		}
+		if tx.Nonce() < stateNonce {
+			if err := p.storage.UpdateTxState(ctx, tx.Hash(), TxStateInvalid); err != nil {
+				return err
+			}
+			continue
+		}
+
+		zkCounters, err := p.preExecuteTx(ctx, tx)
+		if errors.Is(err, ErrOutOfCounters) || errors.Is(err, ErrFreeClaimFailed) {
+			if err := p.storage.UpdateTxState(ctx, tx.Hash(), TxStateInvalid); err != nil {
+				return err
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := p.storage.UpdateTxZkCounters(ctx, tx.Hash(), zkCounters); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // GetPendingTxs from the pool
 // limit parameter is used to limit amount of pending txs from the db,
 // if limit = 0, then there is no limit
@@ -100,12 +169,93 @@ func (p *Pool) CountPendingTransactions(ctx context.Context) (uint64, error) {
 	return p.storage.CountTransactionsByState(ctx, TxStatePending)
 }
 
+// GetPendingNonce returns the nonce the next tx sent by the given address
+// should have, taking into account its txs still pending in the pool and
+// the given nonce of the address in the state
+func (p *Pool) GetPendingNonce(ctx context.Context, address common.Address, stateNonce uint64) (uint64, error) {
+	return p.storage.GetNonce(ctx, address, stateNonce)
+}
+
 // IsTxPending check if tx is still pending
 func (p *Pool) IsTxPending(ctx context.Context, hash common.Hash) (bool, error) {
 	return p.storage.IsTxPending(ctx, hash)
 }
 
-func (p *Pool) validateTx(ctx context.Context, tx types.Transaction) error {
+// GetTxState returns the state of the tx with the given hash in the pool,
+// ErrNotFound is returned when the pool doesn't know the tx
+func (p *Pool) GetTxState(ctx context.Context, hash common.Hash) (TxState, error) {
+	return p.storage.GetTxState(ctx, hash)
+}
+
+// CheckTxPermissions checks that the tx complies with the access control
+// lists. The lists can change at runtime, so they are checked when the tx is
+// added to the pool and again when it is sequenced
+func (p *Pool) CheckTxPermissions(tx types.Transaction) error {
+	from, err := state.GetSender(tx)
+	if err != nil {
+		return ErrInvalidSender
+	}
+	return p.acl.CheckTx(from, tx.To())
+}
+
+// preExecuteTx executes the tx on top of the latest state to get the zk
+// counters it uses, so the sequencer can select it by them. Txs that use more
+// counters than a batch can handle are rejected, and so are the claims sent
+// for free that fail, as they would be sequenced without claiming anything.
+// The tx has to have the nonce of its sender in the state, otherwise it fails
+// early and its counters are meaningless
+func (p *Pool) preExecuteTx(ctx context.Context, poolTx Transaction) (ZkCounters, error) {
+	tx := poolTx.Transaction
+	processBatchResponse, err := p.state.PreProcessTransaction(ctx, &tx, nil)
+	if err != nil {
+		return ZkCounters{}, err
+	}
+
+	zkCounters := ZkCounters{
+		CumulativeGasUsed:    int64(processBatchResponse.CumulativeGasUsed),
+		UsedKeccakHashes:     int32(processBatchResponse.CntKeccakHashes),
+		UsedPoseidonHashes:   int32(processBatchResponse.CntPoseidonHashes),
+		UsedPoseidonPaddings: int32(processBatchResponse.CntPoseidonPaddings),
+		UsedMemAligns:        int32(processBatchResponse.CntMemAligns),
+		UsedArithmetics:      int32(processBatchResponse.CntArithmetics),
+		UsedBinaries:         int32(processBatchResponse.CntBinaries),
+		UsedSteps:            int32(processBatchResponse.CntSteps),
+	}
+
+	remaining := p.maxZkCounters
+	remaining.Sub(zkCounters)
+	if remaining.IsZkCountersBelowZero() {
+		return ZkCounters{}, ErrOutOfCounters
+	}
+
+	if poolTx.IsClaims && tx.GasPrice().Sign() == 0 {
+		for _, response := range processBatchResponse.Responses {
+			if response.Error != "" {
+				return ZkCounters{}, ErrFreeClaimFailed
+			}
+		}
+	}
+
+	return zkCounters, nil
+}
+
+// getStateNonce returns the nonce of the sender of the tx in the latest state
+func (p *Pool) getStateNonce(ctx context.Context, tx types.Transaction) (uint64, error) {
+	from, err := state.GetSender(tx)
+	if err != nil {
+		return 0, ErrInvalidSender
+	}
+
+	lastL2BlockNumber, err := p.state.GetLastL2BlockNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return p.state.GetNonce(ctx, from, lastL2BlockNumber, nil)
+}
+
+func (p *Pool) validateTx(ctx context.Context, poolTx Transaction) error {
+	tx := poolTx.Transaction
 	// Accept only legacy transactions until EIP-2718/2930 activates.
 	if tx.Type() != types.LegacyTxType {
 		return ErrTxTypeNotSupported
@@ -127,6 +277,10 @@ func (p *Pool) validateTx(ctx context.Context, tx types.Transaction) error {
 	if err != nil {
 		return ErrInvalidSender
 	}
+	// Ensure the transaction complies with the access control lists
+	if err := p.acl.CheckTx(from, tx.To()); err != nil {
+		return err
+	}
 
 	lastL2BlockNumber, err := p.state.GetLastL2BlockNumber(ctx, nil)
 	if err != nil {
@@ -142,6 +296,18 @@ func (p *Pool) validateTx(ctx context.Context, tx types.Transaction) error {
 		return ErrNonceTooLow
 	}
 
+	// Claims can be sent for free, so users bridging from l1 with no l2 ETH
+	// can claim their funds. The rest of txs have to pay the minimum gas price
+	if p.checkMinGasPrice && !poolTx.IsClaims {
+		minGasPrice, err := p.storage.GetGasPrice(ctx)
+		if err != nil && !errors.Is(err, state.ErrNotFound) {
+			return err
+		}
+		if tx.GasPrice().Cmp(new(big.Int).SetUint64(minGasPrice)) < 0 {
+			return ErrGasPriceTooLow
+		}
+	}
+
 	// Transactor should have enough funds to cover the costs
 	// cost == V + GP * GL
 	balance, err := p.state.GetBalance(ctx, from, lastL2BlockNumber, nil)
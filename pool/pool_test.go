@@ -17,10 +17,12 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/log"
 	"github.com/0xPolygonHermez/zkevm-node/merkletree"
 	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/pool/acl"
 	"github.com/0xPolygonHermez/zkevm-node/pool/pgpoolstorage"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
 	"github.com/0xPolygonHermez/zkevm-node/test/dbutils"
+	"github.com/0xPolygonHermez/zkevm-node/test/operations"
 	"github.com/0xPolygonHermez/zkevm-node/test/testutils"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -86,7 +88,7 @@ func Test_AddTx(t *testing.T) {
 		t.Error(err)
 	}
 
-	p := pool.NewPool(s, st, common.Address{})
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
 
 	txRLPHash := "0xf86e8212658082520894fd8b27a263e19f0e9592180e61f0f8c9dfeb1ff6880de0b6b3a764000080850133333355a01eac4c2defc7ed767ae36bbd02613c581b8fb87d0e4f579c9ee3a7cfdb16faa7a043ce30f43d952b9d034cf8f04fecb631192a5dbc7ee2a47f1f49c0d022a8849d"
 	b, err := hex.DecodeHex(txRLPHash)
@@ -126,6 +128,153 @@ func Test_AddTx(t *testing.T) {
 	assert.Equal(t, 1, c, "invalid number of txs in the pool")
 }
 
+func Test_AddTxOutOfCounters(t *testing.T) {
+	_, st, s := setupAddTxTest(t)
+	ctx := context.Background()
+
+	// a batch that can only handle a single step
+	batchZkCounters := operations.MaxZkCounters
+	batchZkCounters.UsedSteps = 1
+	p := pool.NewPool(s, st, common.Address{}, batchZkCounters, nil, false)
+
+	txRLPHash := "0xf86e8212658082520894fd8b27a263e19f0e9592180e61f0f8c9dfeb1ff6880de0b6b3a764000080850133333355a01eac4c2defc7ed767ae36bbd02613c581b8fb87d0e4f579c9ee3a7cfdb16faa7a043ce30f43d952b9d034cf8f04fecb631192a5dbc7ee2a47f1f49c0d022a8849d"
+	b, err := hex.DecodeHex(txRLPHash)
+	require.NoError(t, err)
+	tx := new(types.Transaction)
+	require.NoError(t, tx.UnmarshalBinary(b))
+
+	err = p.AddTx(ctx, *tx)
+	require.ErrorIs(t, err, pool.ErrOutOfCounters)
+
+	count, err := p.CountPendingTransactions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), count)
+}
+
+func Test_AddTxNonceAhead(t *testing.T) {
+	ctx := context.Background()
+
+	require.NoError(t, dbutils.InitOrReset(dbCfg))
+
+	sqlDB, err := db.NewSQLDB(dbCfg)
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	st := newState(sqlDB)
+
+	genesisBlock := state.Block{
+		BlockNumber: 0,
+		BlockHash:   state.ZeroHash,
+		ParentHash:  state.ZeroHash,
+		ReceivedAt:  time.Now(),
+	}
+	balance, _ := big.NewInt(0).SetString("1000000000000000000000", encoding.Base10)
+	genesis := state.Genesis{
+		Balances: map[common.Address]*big.Int{
+			common.HexToAddress("0x617b3a3528F9cDd6630fd3301B9c8911F7Bf063D"): balance,
+		},
+	}
+	dbTx, err := st.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	require.NoError(t, st.SetGenesis(ctx, genesisBlock, genesis, dbTx))
+	require.NoError(t, dbTx.Commit(ctx))
+
+	s, err := pgpoolstorage.NewPostgresPoolStorage(dbCfg)
+	require.NoError(t, err)
+
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
+
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(senderPrivateKey, "0x"))
+	require.NoError(t, err)
+
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, big.NewInt(1337))
+	require.NoError(t, err)
+
+	hashes := make([]common.Hash, 0, 2)
+	for i := 0; i < 2; i++ {
+		tx := types.NewTransaction(uint64(i), common.Address{}, big.NewInt(10), uint64(21000), big.NewInt(10), []byte{})
+		signedTx, err := auth.Signer(auth.From, tx)
+		require.NoError(t, err)
+		require.NoError(t, p.AddTx(ctx, *signedTx))
+		hashes = append(hashes, signedTx.Hash())
+	}
+
+	isPreExecuted := func(hash common.Hash) bool {
+		var preExecuted bool
+		err := sqlDB.QueryRow(ctx, "SELECT is_pre_executed FROM pool.txs WHERE hash = $1", hash.Hex()).Scan(&preExecuted)
+		require.NoError(t, err)
+		return preExecuted
+	}
+	assert.True(t, isPreExecuted(hashes[0]))
+	assert.False(t, isPreExecuted(hashes[1]))
+
+	// the tx is not pre-executed while its nonce is still ahead of the state
+	require.NoError(t, p.PreExecutePendingTxs(ctx))
+	assert.False(t, isPreExecuted(hashes[1]))
+
+	// and it is left out of the selection meanwhile
+	txs, err := p.GetPendingTxsFittingZkCounters(ctx, operations.MaxZkCounters, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(txs))
+	assert.Equal(t, hashes[0], txs[0].Hash())
+}
+
+func Test_AddTxNotAllowed(t *testing.T) {
+	sqlDB, st, s := setupAddTxTest(t)
+	ctx := context.Background()
+
+	// deny the recipient of the tx
+	_, err := sqlDB.Exec(ctx, "INSERT INTO pool.acl (address, list) VALUES ($1, $2)", "0xfd8b27a263e19f0e9592180e61f0f8c9dfeb1ff6", "denied_recipient")
+	require.NoError(t, err)
+	poolACL, err := acl.New(acl.Config{Enabled: true}, s)
+	require.NoError(t, err)
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, poolACL, false)
+
+	txRLPHash := "0xf86e8212658082520894fd8b27a263e19f0e9592180e61f0f8c9dfeb1ff6880de0b6b3a764000080850133333355a01eac4c2defc7ed767ae36bbd02613c581b8fb87d0e4f579c9ee3a7cfdb16faa7a043ce30f43d952b9d034cf8f04fecb631192a5dbc7ee2a47f1f49c0d022a8849d"
+	b, err := hex.DecodeHex(txRLPHash)
+	require.NoError(t, err)
+	tx := new(types.Transaction)
+	require.NoError(t, tx.UnmarshalBinary(b))
+
+	err = p.AddTx(ctx, *tx)
+	require.ErrorIs(t, err, acl.ErrRecipientNotAllowed)
+	require.ErrorIs(t, p.CheckTxPermissions(*tx), acl.ErrRecipientNotAllowed)
+
+	count, err := p.CountPendingTransactions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), count)
+}
+
+func Test_AddTxGasPriceTooLow(t *testing.T) {
+	_, st, s := setupAddTxTest(t)
+	ctx := context.Background()
+
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, true)
+	require.NoError(t, p.SetGasPrice(ctx, 1))
+
+	// the tx has a gas price of 0
+	txRLPHash := "0xf86e8212658082520894fd8b27a263e19f0e9592180e61f0f8c9dfeb1ff6880de0b6b3a764000080850133333355a01eac4c2defc7ed767ae36bbd02613c581b8fb87d0e4f579c9ee3a7cfdb16faa7a043ce30f43d952b9d034cf8f04fecb631192a5dbc7ee2a47f1f49c0d022a8849d"
+	b, err := hex.DecodeHex(txRLPHash)
+	require.NoError(t, err)
+	tx := new(types.Transaction)
+	require.NoError(t, tx.UnmarshalBinary(b))
+
+	err = p.AddTx(ctx, *tx)
+	require.ErrorIs(t, err, pool.ErrGasPriceTooLow)
+
+	count, err := p.CountPendingTransactions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), count)
+
+	// the gas price of the pool is not a minimum unless it is checked
+	p = pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
+	require.NoError(t, p.AddTx(ctx, *tx))
+
+	count, err = p.CountPendingTransactions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+}
+
 func Test_GetPendingTxs(t *testing.T) {
 	if err := dbutils.InitOrReset(dbCfg); err != nil {
 		panic(err)
@@ -163,7 +312,7 @@ func Test_GetPendingTxs(t *testing.T) {
 		t.Error(err)
 	}
 
-	p := pool.NewPool(s, st, common.Address{})
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
 
 	const txsCount = 10
 	const limit = 5
@@ -233,7 +382,7 @@ func Test_GetPendingTxsZeroPassed(t *testing.T) {
 		t.Error(err)
 	}
 
-	p := pool.NewPool(s, st, common.Address{})
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
 
 	const txsCount = 10
 	const limit = 0
@@ -303,7 +452,7 @@ func Test_GetTopPendingTxByProfitabilityAndZkCounters(t *testing.T) {
 		t.Error(err)
 	}
 
-	p := pool.NewPool(s, st, common.Address{})
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
 
 	const txsCount = 10
 
@@ -333,9 +482,11 @@ func Test_GetTopPendingTxByProfitabilityAndZkCounters(t *testing.T) {
 		UsedBinaries:         1,
 		UsedSteps:            1,
 	}
+	// only the tx with the nonce of the state could be pre-executed, the rest
+	// are left out until their zk counters are known
 	tx, err := p.GetTopPendingTxByProfitabilityAndZkCounters(ctx, zkCounters)
 	require.NoError(t, err)
-	assert.Equal(t, tx.Transaction.GasPrice().Uint64(), uint64(19))
+	assert.Equal(t, tx.Transaction.GasPrice().Uint64(), uint64(10))
 }
 
 func Test_UpdateTxsState(t *testing.T) {
@@ -376,7 +527,7 @@ func Test_UpdateTxsState(t *testing.T) {
 		t.Error(err)
 	}
 
-	p := pool.NewPool(s, st, common.Address{})
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
 
 	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(senderPrivateKey, "0x"))
 	require.NoError(t, err)
@@ -449,7 +600,7 @@ func Test_UpdateTxState(t *testing.T) {
 		t.Error(err)
 	}
 
-	p := pool.NewPool(s, st, common.Address{})
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
 
 	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(senderPrivateKey, "0x"))
 	require.NoError(t, err)
@@ -494,7 +645,7 @@ func Test_SetAndGetGasPrice(t *testing.T) {
 		t.Error(err)
 	}
 
-	p := pool.NewPool(s, nil, common.Address{})
+	p := pool.NewPool(s, nil, common.Address{}, operations.MaxZkCounters, nil, false)
 
 	nBig, err := rand.Int(rand.Reader, big.NewInt(0).SetUint64(math.MaxUint64))
 	if err != nil {
@@ -517,6 +668,31 @@ func Test_SetAndGetGasPrice(t *testing.T) {
 	assert.Equal(t, expectedGasPrice, gasPrice)
 }
 
+func Test_SetGasPriceRemovesPreviousGasPrices(t *testing.T) {
+	require.NoError(t, dbutils.InitOrReset(dbCfg))
+
+	sqlDB, err := db.NewSQLDB(dbCfg)
+	require.NoError(t, err)
+	defer sqlDB.Close() //nolint:gosec,errcheck
+
+	s, err := pgpoolstorage.NewPostgresPoolStorage(dbCfg)
+	require.NoError(t, err)
+	p := pool.NewPool(s, nil, common.Address{}, operations.MaxZkCounters, nil, false)
+
+	ctx := context.Background()
+	for _, gasPrice := range []uint64{1, 2, 3} {
+		require.NoError(t, p.SetGasPrice(ctx, gasPrice))
+	}
+
+	gasPrice, err := p.GetGasPrice(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), gasPrice)
+
+	var count int
+	require.NoError(t, sqlDB.QueryRow(ctx, "SELECT COUNT(*) FROM pool.gas_price").Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
 func TestMarkReorgedTxsAsPending(t *testing.T) {
 	if err := dbutils.InitOrReset(dbCfg); err != nil {
 		panic(err)
@@ -553,7 +729,7 @@ func TestMarkReorgedTxsAsPending(t *testing.T) {
 		t.Error(err)
 	}
 
-	p := pool.NewPool(s, st, common.Address{})
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
 
 	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(senderPrivateKey, "0x"))
 	require.NoError(t, err)
@@ -584,8 +760,9 @@ func TestMarkReorgedTxsAsPending(t *testing.T) {
 	require.NoError(t, err)
 	txs, err := p.GetPendingTxs(ctx, false, 100)
 	require.NoError(t, err)
-	require.Equal(t, signedTx1.Hash().Hex(), txs[1].Hash().Hex())
-	require.Equal(t, signedTx2.Hash().Hex(), txs[0].Hash().Hex())
+	// the txs paying the same gas price are ordered by nonce
+	require.Equal(t, signedTx1.Hash().Hex(), txs[0].Hash().Hex())
+	require.Equal(t, signedTx2.Hash().Hex(), txs[1].Hash().Hex())
 }
 
 func TestGetPendingTxSince(t *testing.T) {
@@ -625,7 +802,7 @@ func TestGetPendingTxSince(t *testing.T) {
 		t.Error(err)
 	}
 
-	p := pool.NewPool(s, st, common.Address{})
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
 
 	const txsCount = 10
 
@@ -730,7 +907,7 @@ func Test_DeleteTxsByHashes(t *testing.T) {
 		t.Error(err)
 	}
 
-	p := pool.NewPool(s, st, common.Address{})
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
 
 	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(senderPrivateKey, "0x"))
 	require.NoError(t, err)
@@ -765,6 +942,40 @@ func Test_DeleteTxsByHashes(t *testing.T) {
 	assert.Equal(t, 0, count)
 }
 
+// setupAddTxTest resets the db and sets a genesis funding the sender of the
+// test txs, returning the db, the state and the storage to create the pool
+func setupAddTxTest(t *testing.T) (*pgxpool.Pool, *state.State, *pgpoolstorage.PostgresPoolStorage) {
+	require.NoError(t, dbutils.InitOrReset(dbCfg))
+
+	sqlDB, err := db.NewSQLDB(dbCfg)
+	require.NoError(t, err)
+	t.Cleanup(sqlDB.Close)
+
+	st := newState(sqlDB)
+
+	genesisBlock := state.Block{
+		BlockNumber: 0,
+		BlockHash:   state.ZeroHash,
+		ParentHash:  state.ZeroHash,
+		ReceivedAt:  time.Now(),
+	}
+	balance, _ := big.NewInt(0).SetString("1000000000000000000000", encoding.Base10)
+	genesis := state.Genesis{
+		Balances: map[common.Address]*big.Int{
+			common.HexToAddress("0xb48cA794d49EeC406A5dD2c547717e37b5952a83"): balance,
+		},
+	}
+	ctx := context.Background()
+	dbTx, err := st.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	require.NoError(t, st.SetGenesis(ctx, genesisBlock, genesis, dbTx))
+	require.NoError(t, dbTx.Commit(ctx))
+
+	s, err := pgpoolstorage.NewPostgresPoolStorage(dbCfg)
+	require.NoError(t, err)
+	return sqlDB, st, s
+}
+
 func newState(sqlDB *pgxpool.Pool) *state.State {
 	ctx := context.Background()
 	stateDb := state.NewPostgresStorage(sqlDB)
@@ -778,3 +989,89 @@ func newState(sqlDB *pgxpool.Pool) *state.State {
 	st := state.NewState(state.Config{MaxCumulativeGasUsed: 800000}, stateDb, executorClient, stateTree)
 	return st
 }
+
+func Test_GetPendingNonce(t *testing.T) {
+	ctx := context.Background()
+
+	if err := dbutils.InitOrReset(dbCfg); err != nil {
+		panic(err)
+	}
+
+	sqlDB, err := db.NewSQLDB(dbCfg)
+	if err != nil {
+		t.Error(err)
+	}
+	defer sqlDB.Close() //nolint:gosec,errcheck
+
+	st := newState(sqlDB)
+
+	genesisBlock := state.Block{
+		BlockNumber: 0,
+		BlockHash:   state.ZeroHash,
+		ParentHash:  state.ZeroHash,
+		ReceivedAt:  time.Now(),
+	}
+	balance, _ := big.NewInt(0).SetString("1000000000000000000000", encoding.Base10)
+	genesis := state.Genesis{
+		Balances: map[common.Address]*big.Int{
+			common.HexToAddress("0x617b3a3528F9cDd6630fd3301B9c8911F7Bf063D"): balance,
+		},
+	}
+	dbTx, err := st.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	err = st.SetGenesis(ctx, genesisBlock, genesis, dbTx)
+	require.NoError(t, err)
+	require.NoError(t, dbTx.Commit(ctx))
+
+	s, err := pgpoolstorage.NewPostgresPoolStorage(dbCfg)
+	if err != nil {
+		t.Error(err)
+	}
+
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
+
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(senderPrivateKey, "0x"))
+	require.NoError(t, err)
+
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, big.NewInt(1337))
+	require.NoError(t, err)
+
+	nonce, err := p.GetPendingNonce(ctx, auth.From, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), nonce)
+
+	for i := 0; i < 2; i++ {
+		tx := types.NewTransaction(uint64(i), common.Address{}, big.NewInt(10), uint64(1), big.NewInt(10), []byte{})
+		signedTx, err := auth.Signer(auth.From, tx)
+		require.NoError(t, err)
+		if err := p.AddTx(ctx, *signedTx); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// the tx after a nonce gap doesn't count
+	tx := types.NewTransaction(uint64(3), common.Address{}, big.NewInt(10), uint64(1), big.NewInt(10), []byte{})
+	signedTx, err := auth.Signer(auth.From, tx)
+	require.NoError(t, err)
+	if err := p.AddTx(ctx, *signedTx); err != nil {
+		t.Error(err)
+	}
+
+	nonce, err = p.GetPendingNonce(ctx, auth.From, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), nonce)
+
+	// the pending txs are counted from the nonce of the state
+	nonce, err = p.GetPendingNonce(ctx, auth.From, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), nonce)
+
+	// the nonce of the state is used when it's ahead of the pending txs
+	nonce, err = p.GetPendingNonce(ctx, auth.From, 5)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), nonce)
+
+	nonce, err = p.GetPendingNonce(ctx, common.HexToAddress("0x1"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), nonce)
+}
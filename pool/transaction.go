@@ -1,11 +1,14 @@
 package pool
 
 import (
-	"strings"
+	"bytes"
+	"fmt"
 	"time"
 
+	"github.com/0xPolygonHermez/zkevm-node/etherman/smartcontracts/bridge"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 const (
@@ -31,7 +34,10 @@ type Transaction struct {
 	State    TxState
 	IsClaims bool
 	ZkCounters
-	ReceivedAt time.Time
+	// IsPreExecuted is false while the tx couldn't be pre-executed to get
+	// its zk counters, as its nonce is ahead of the state
+	IsPreExecuted bool
+	ReceivedAt    time.Time
 }
 
 // ZkCounters counters for the tx
@@ -58,15 +64,68 @@ func (zkc *ZkCounters) IsZkCountersBelowZero() bool {
 		zkc.UsedKeccakHashes < 0
 }
 
-// IsClaimTx checks, if tx is a claim tx
-func (tx *Transaction) IsClaimTx(l2GlobalExitRootManagerAddr common.Address) bool {
-	if tx.To() == nil {
+// SumUp adds the given counters to the current ones
+func (zkc *ZkCounters) SumUp(other ZkCounters) {
+	zkc.CumulativeGasUsed += other.CumulativeGasUsed
+	zkc.UsedKeccakHashes += other.UsedKeccakHashes
+	zkc.UsedPoseidonHashes += other.UsedPoseidonHashes
+	zkc.UsedPoseidonPaddings += other.UsedPoseidonPaddings
+	zkc.UsedMemAligns += other.UsedMemAligns
+	zkc.UsedArithmetics += other.UsedArithmetics
+	zkc.UsedBinaries += other.UsedBinaries
+	zkc.UsedSteps += other.UsedSteps
+}
+
+// Sub subtracts the given counters from the current ones
+func (zkc *ZkCounters) Sub(other ZkCounters) {
+	zkc.CumulativeGasUsed -= other.CumulativeGasUsed
+	zkc.UsedKeccakHashes -= other.UsedKeccakHashes
+	zkc.UsedPoseidonHashes -= other.UsedPoseidonHashes
+	zkc.UsedPoseidonPaddings -= other.UsedPoseidonPaddings
+	zkc.UsedMemAligns -= other.UsedMemAligns
+	zkc.UsedArithmetics -= other.UsedArithmetics
+	zkc.UsedBinaries -= other.UsedBinaries
+	zkc.UsedSteps -= other.UsedSteps
+}
+
+// IsClaimTx checks, if tx is a call to the claim method of the l2 bridge
+func (tx *Transaction) IsClaimTx(l2BridgeAddr common.Address) bool {
+	if tx.To() == nil || *tx.To() != l2BridgeAddr {
+		return false
+	}
+
+	bridgeABI, err := bridge.BridgeMetaData.GetAbi()
+	if err != nil {
 		return false
 	}
+	return bytes.HasPrefix(tx.Data(), bridgeABI.Methods["claim"].ID)
+}
+
+// ClaimGlobalExitRoot returns the global exit root the claim tx proves the
+// deposit against, the claim can only be processed once this global exit
+// root is available in l2
+func (tx *Transaction) ClaimGlobalExitRoot() (common.Hash, error) {
+	const methodIDLength = 4
+	if len(tx.Data()) < methodIDLength {
+		return common.Hash{}, ErrInvalidClaim
+	}
 
-	if *tx.To() == l2GlobalExitRootManagerAddr &&
-		strings.HasPrefix("0x"+common.Bytes2Hex(tx.Data()), bridgeClaimMethodSignature) {
-		return true
+	bridgeABI, err := bridge.BridgeMetaData.GetAbi()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	args := make(map[string]interface{})
+	if err := bridgeABI.Methods["claim"].Inputs.UnpackIntoMap(args, tx.Data()[methodIDLength:]); err != nil {
+		return common.Hash{}, fmt.Errorf("%w: %v", ErrInvalidClaim, err)
+	}
+
+	mainnetExitRoot, ok := args["mainnetExitRoot"].([32]byte)
+	if !ok {
+		return common.Hash{}, ErrInvalidClaim
+	}
+	rollupExitRoot, ok := args["rollupExitRoot"].([32]byte)
+	if !ok {
+		return common.Hash{}, ErrInvalidClaim
 	}
-	return false
+	return crypto.Keccak256Hash(mainnetExitRoot[:], rollupExitRoot[:]), nil
 }
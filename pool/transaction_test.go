@@ -1,18 +1,29 @@
 package pool
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 
-	"github.com/0xPolygonHermez/zkevm-node/hex"
+	"github.com/0xPolygonHermez/zkevm-node/etherman/smartcontracts/bridge"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 func Test_IsClaimTx(t *testing.T) {
-	l2GlobalExitRootManagerAddr := common.HexToAddress("0x00000000000000000000000000000001")
+	l2BridgeAddr := common.HexToAddress("0x00000000000000000000000000000001")
 	differentAddr := common.HexToAddress("0x00000000000000000000000000000002")
-	claimData := hex.DecodeHexToBig(bridgeClaimMethodSignature).Bytes()
+	bridgeABI, err := bridge.BridgeMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimData, err := bridgeABI.Pack("claim", [][32]byte{}, uint32(0), common.HexToHash("0x1"), common.HexToHash("0x2"),
+		uint32(0), common.Address{}, uint32(1), common.HexToAddress("0x3"), big.NewInt(100), []byte{}) //nolint:gomnd
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherMethodData := append(common.FromHex("0x122650ff"), claimData[4:]...)
 
 	testCases := []struct {
 		Name           string
@@ -34,34 +45,62 @@ func Test_IsClaimTx(t *testing.T) {
 			expectedResult: false,
 		},
 		{
-			Name: "To address as Any address other than l2GlobalExitRootManagerAddr address",
+			Name: "To address as Any address other than l2BridgeAddr address",
 			Tx: Transaction{
 				Transaction: *types.NewTx(&types.LegacyTx{Nonce: 1, To: &differentAddr, Value: big.NewInt(0), Gas: 0, GasPrice: big.NewInt(0), Data: claimData}),
 			},
 			expectedResult: false,
 		},
 		{
-			Name: "To address as l2GlobalExitRootManagerAddr address",
+			Name: "To address as l2BridgeAddr address calling another method",
 			Tx: Transaction{
-				Transaction: *types.NewTx(&types.LegacyTx{Nonce: 1, To: &l2GlobalExitRootManagerAddr, Value: big.NewInt(0), Gas: 0, GasPrice: big.NewInt(0), Data: claimData}),
+				Transaction: *types.NewTx(&types.LegacyTx{Nonce: 1, To: &l2BridgeAddr, Value: big.NewInt(0), Gas: 0, GasPrice: big.NewInt(0), Data: otherMethodData}),
 			},
 			expectedResult: false,
 		},
 		{
-			Name: "To address as l2GlobalExitRootManagerAddr address",
+			Name: "To address as l2BridgeAddr address calling claim",
 			Tx: Transaction{
-				Transaction: *types.NewTx(&types.LegacyTx{Nonce: 1, To: &l2GlobalExitRootManagerAddr, Value: big.NewInt(0), Gas: 0, GasPrice: big.NewInt(0), Data: claimData}),
+				Transaction: *types.NewTx(&types.LegacyTx{Nonce: 1, To: &l2BridgeAddr, Value: big.NewInt(0), Gas: 0, GasPrice: big.NewInt(0), Data: claimData}),
 			},
-			expectedResult: false,
+			expectedResult: true,
 		},
 	}
 
 	for _, testCase := range testCases {
 		t.Run(testCase.Name, func(t *testing.T) {
-			result := testCase.Tx.IsClaimTx(l2GlobalExitRootManagerAddr)
+			result := testCase.Tx.IsClaimTx(l2BridgeAddr)
 			if result != testCase.expectedResult {
 				t.Errorf("Invalid result, expected: %v, found: %v", testCase.expectedResult, result)
 			}
 		})
 	}
 }
+
+func Test_ClaimGlobalExitRoot(t *testing.T) {
+	bridgeABI, err := bridge.BridgeMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mainnetExitRoot := common.HexToHash("0x1")
+	rollupExitRoot := common.HexToHash("0x2")
+	claimData, err := bridgeABI.Pack("claim", [][32]byte{}, uint32(0), mainnetExitRoot, rollupExitRoot,
+		uint32(0), common.Address{}, uint32(1), common.HexToAddress("0x3"), big.NewInt(100), []byte{}) //nolint:gomnd
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := Transaction{Transaction: *types.NewTx(&types.LegacyTx{Value: big.NewInt(0), GasPrice: big.NewInt(0), Data: claimData})}
+	globalExitRoot, err := tx.ClaimGlobalExitRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := crypto.Keccak256Hash(mainnetExitRoot.Bytes(), rollupExitRoot.Bytes()); globalExitRoot != expected {
+		t.Errorf("Invalid result, expected: %v, found: %v", expected, globalExitRoot)
+	}
+
+	tx = Transaction{Transaction: *types.NewTx(&types.LegacyTx{Value: big.NewInt(0), GasPrice: big.NewInt(0), Data: claimData[:36]})} //nolint:gomnd
+	if _, err := tx.ClaimGlobalExitRoot(); !errors.Is(err, ErrInvalidClaim) {
+		t.Errorf("Invalid result, expected: %v, found: %v", ErrInvalidClaim, err)
+	}
+}
@@ -0,0 +1,101 @@
+package sequencer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ClaimsLaneConfig represents the configuration of the claims lane, that
+// selects the bridge claim txs with priority over the rest of pending txs.
+// Claims can be sent with zero gas price, so users bridging from l1 can claim
+// their funds without having any l2 ETH, and are only selected by this lane
+type ClaimsLaneConfig struct {
+	// MaxClaimsPerBatch is the max number of claim txs processed into each
+	// batch
+	MaxClaimsPerBatch uint64 `mapstructure:"MaxClaimsPerBatch"`
+
+	// MaxCandidates is the max number of pending claim txs checked on each
+	// selection
+	MaxCandidates uint64 `mapstructure:"MaxCandidates"`
+}
+
+// processClaims processes the pending claim txs whose global exit root is
+// already available in the batch in progress, up to the claims quota of the
+// batch. It returns false when processing failed with an error
+func (s *Sequencer) processClaims(ctx context.Context) bool {
+	if s.cfg.ClaimsLane.MaxCandidates == 0 || s.claimsInProgress >= s.cfg.ClaimsLane.MaxClaimsPerBatch {
+		return true
+	}
+
+	claims, err := s.pool.GetPendingTxs(ctx, true, s.cfg.ClaimsLane.MaxCandidates)
+	if err != nil {
+		log.Errorf("failed to get pending claim txs, err: %v", err)
+		return false
+	}
+	if len(claims) == 0 {
+		return true
+	}
+
+	batchExitRoot, err := s.state.GetExitRootByGlobalExitRoot(ctx, s.sequenceInProgress.GlobalExitRoot, nil)
+	if errors.Is(err, state.ErrNotFound) {
+		// no claim can be processed until the batch has a synced global exit root
+		return true
+	} else if err != nil {
+		log.Errorf("failed to get the exit root of the batch in progress, err: %v", err)
+		return false
+	}
+
+	for _, claim := range claims {
+		if s.claimsInProgress >= s.cfg.ClaimsLane.MaxClaimsPerBatch {
+			return true
+		}
+
+		claimGER, err := claim.ClaimGlobalExitRoot()
+		if err != nil {
+			log.Warnf("failed to decode claim tx %s, marking it as invalid, err: %v", claim.Hash(), err)
+			if err := s.pool.UpdateTxState(ctx, claim.Hash(), pool.TxStateInvalid); err != nil {
+				log.Errorf("failed to update tx status on the pool, err: %v", err)
+				return false
+			}
+			continue
+		}
+		available, err := s.isGlobalExitRootAvailable(ctx, claimGER, batchExitRoot)
+		if err != nil {
+			log.Errorf("failed to check the global exit root of claim tx %s, err: %v", claim.Hash(), err)
+			return false
+		}
+		// the claims whose zk counters are unknown aren't executable yet, as
+		// their nonce is ahead of the state
+		if !available || !claim.IsPreExecuted || !fitsInZkCounters(claim.ZkCounters, s.calculateZkCounters()) {
+			continue
+		}
+
+		txsInProgress := len(s.sequenceInProgress.Txs)
+		if !s.processTx(ctx, claim) {
+			return false
+		}
+		if len(s.sequenceInProgress.Txs) > txsInProgress {
+			s.claimsInProgress++
+		}
+	}
+
+	return true
+}
+
+// isGlobalExitRootAvailable checks if the given global exit root is already
+// available in the batch with the given exit root, that is, it was synced
+// before or along with the global exit root of the batch
+func (s *Sequencer) isGlobalExitRootAvailable(ctx context.Context, ger common.Hash, batchExitRoot *state.GlobalExitRoot) (bool, error) {
+	exitRoot, err := s.state.GetExitRootByGlobalExitRoot(ctx, ger, nil)
+	if errors.Is(err, state.ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return exitRoot.GlobalExitRootNum.Cmp(batchExitRoot.GlobalExitRootNum) <= 0, nil
+}
@@ -2,6 +2,7 @@ package sequencer
 
 import (
 	"github.com/0xPolygonHermez/zkevm-node/config/types"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
 	"github.com/0xPolygonHermez/zkevm-node/sequencer/profitabilitychecker"
 )
 
@@ -50,6 +51,35 @@ type Config struct {
 	// MaxSteps is max steps batch can handle
 	MaxSteps int32 `mapstructure:"MaxSteps"`
 
+	// MaxForcedBatchesPerSequence is the max number of pending forced batches
+	// included after each batch closed by the sequencer
+	MaxForcedBatchesPerSequence uint64 `mapstructure:"MaxForcedBatchesPerSequence"`
+
+	// ForcedBatchesTimeoutMargin is the time before the force batch timeout of
+	// the contract at which the forced batches are sequenced even if it is not
+	// profitable, so nobody else can sequence them
+	ForcedBatchesTimeoutMargin types.Duration `mapstructure:"ForcedBatchesTimeoutMargin"`
+
 	// ProfitabilityChecker configuration
 	ProfitabilityChecker profitabilitychecker.Config `mapstructure:"ProfitabilityChecker"`
+
+	// TxSelector configuration
+	TxSelector TxSelectorConfig `mapstructure:"TxSelector"`
+
+	// ClaimsLane configuration
+	ClaimsLane ClaimsLaneConfig `mapstructure:"ClaimsLane"`
+}
+
+// MaxZkCounters returns the zk counters a batch can handle
+func (cfg Config) MaxZkCounters() pool.ZkCounters {
+	return pool.ZkCounters{
+		CumulativeGasUsed:    cfg.MaxGasUsed,
+		UsedKeccakHashes:     cfg.MaxKeccakHashes,
+		UsedPoseidonHashes:   cfg.MaxPoseidonHashes,
+		UsedPoseidonPaddings: cfg.MaxPoseidonPaddings,
+		UsedMemAligns:        cfg.MaxMemAligns,
+		UsedArithmetics:      cfg.MaxArithmetics,
+		UsedBinaries:         cfg.MaxBinaries,
+		UsedSteps:            cfg.MaxSteps,
+	}
 }
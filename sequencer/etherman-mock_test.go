@@ -37,6 +37,27 @@ func (_m *ethermanMock) EstimateGasSequenceBatches(sequences []types.Sequence) (
 	return r0, r1
 }
 
+// GetForceBatchTimeout provides a mock function with given fields:
+func (_m *ethermanMock) GetForceBatchTimeout() (uint64, error) {
+	ret := _m.Called()
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLatestBatchNumber provides a mock function with given fields:
 func (_m *ethermanMock) GetLatestBatchNumber() (uint64, error) {
 	ret := _m.Called()
@@ -18,6 +18,7 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/0xPolygonHermez/zkevm-node/state/runtime/executor"
 	"github.com/0xPolygonHermez/zkevm-node/test/dbutils"
+	"github.com/0xPolygonHermez/zkevm-node/test/operations"
 	"github.com/0xPolygonHermez/zkevm-node/test/testutils"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -74,7 +75,7 @@ func TestQueue_AddAndPopTx(t *testing.T) {
 		panic(err)
 	}
 
-	p := pool.NewPool(s, st, common.Address{})
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
 
 	const txsCount = 10
 
@@ -159,7 +160,7 @@ func TestQueue_AddOneTx(t *testing.T) {
 		panic(err)
 	}
 
-	p := pool.NewPool(s, st, common.Address{})
+	p := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
 
 	const txsCount = 1
 
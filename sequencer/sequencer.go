@@ -11,11 +11,12 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/etherman/types"
 	"github.com/0xPolygonHermez/zkevm-node/log"
 	"github.com/0xPolygonHermez/zkevm-node/pool"
-	"github.com/0xPolygonHermez/zkevm-node/pool/pgpoolstorage"
 	"github.com/0xPolygonHermez/zkevm-node/sequencer/profitabilitychecker"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v4"
 )
 
 const (
@@ -32,6 +33,7 @@ type Sequencer struct {
 	txManager         txManager
 	etherman          etherman
 	checker           *profitabilitychecker.Checker
+	txSelector        txSelector
 	reorgBlockNumChan chan struct{}
 
 	address                          common.Address
@@ -40,6 +42,17 @@ type Sequencer struct {
 
 	closedSequences    []types.Sequence
 	sequenceInProgress types.Sequence
+	// batchZkCountersOverhead are the counters used to process a batch
+	// without txs, nil until they are measured
+	batchZkCountersOverhead *pool.ZkCounters
+	// claimsInProgress is the number of claim txs in the sequence in progress
+	claimsInProgress uint64
+
+	// forceBatchTimeout is the time after which anyone can sequence a forced
+	// batch, and forcedBatchesDeadline the time at which the closed sequences
+	// have to be sent to sequence the forced batches they include in time
+	forceBatchTimeout     time.Duration
+	forcedBatchesDeadline time.Time
 }
 
 // New init sequencer
@@ -59,15 +72,27 @@ func New(
 	}
 	// TODO: check that private key used in etherman matches addr
 
+	txSelector, err := newTxSelector(cfg.TxSelector, pool, state)
+	if err != nil {
+		return nil, err
+	}
+
+	forceBatchTimeout, err := etherman.GetForceBatchTimeout()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get force batch timeout, err: %v", err)
+	}
+
 	return &Sequencer{
 		cfg:               cfg,
 		pool:              pool,
 		state:             state,
 		etherman:          etherman,
 		checker:           checker,
+		txSelector:        txSelector,
 		txManager:         manager,
 		address:           addr,
 		reorgBlockNumChan: reorgBlockNumChan,
+		forceBatchTimeout: time.Duration(forceBatchTimeout) * time.Second,
 	}, nil
 }
 
@@ -186,25 +211,22 @@ func (s *Sequencer) tryToProcessTx(ctx context.Context, ticker *time.Ticker) {
 		return
 	}
 
-	log.Infof("checking if current sequence should be sent")
-	shouldSent, shouldCut := s.shouldSendSequences(ctx)
-	if shouldSent {
-		log.Infof("current sequence should be sent")
-		if shouldCut {
-			log.Infof("current sequence should be cut")
-			cutSequence := s.closedSequences[len(s.closedSequences)-1]
-			if err := s.txManager.SequenceBatches(s.closedSequences); err != nil {
-				log.Errorf("failed to SequenceBatches, err: %v", err)
-				return
-			}
-			s.closedSequences = []types.Sequence{cutSequence}
-		} else {
-			if err := s.txManager.SequenceBatches(s.closedSequences); err != nil {
-				log.Errorf("failed to SequenceBatches, err: %v", err)
-				return
-			}
-			s.closedSequences = []types.Sequence{}
-		}
+	if !s.trySendSequences(ctx) {
+		return
+	}
+
+	// the txs whose nonce was ahead of the state when they were added to the
+	// pool get their zk counters once the txs before them are processed, so
+	// they can be selected
+	if err := s.pool.PreExecutePendingTxs(ctx); err != nil {
+		log.Errorf("failed to pre-execute the pending txs, err: %v", err)
+		return
+	}
+
+	// the claims are processed first, so users can claim their funds as soon
+	// as possible
+	if !s.processClaims(ctx) {
+		return
 	}
 
 	log.Infof("getting pending tx from the pool")
@@ -213,85 +235,119 @@ func (s *Sequencer) tryToProcessTx(ctx context.Context, ticker *time.Ticker) {
 		s.closeSequence(ctx)
 		return
 	}
-	tx, err := s.pool.GetTopPendingTxByProfitabilityAndZkCounters(ctx, zkCounters)
-	if err == pgpoolstorage.ErrNotFound {
+	txs, err := s.txSelector.SelectTxs(ctx, zkCounters)
+	if err != nil {
+		log.Errorf("failed to select pending txs, err: %v", err)
+		return
+	}
+	if len(txs) == 0 {
 		log.Infof("there is no suitable pending tx in the pool, waiting...")
 		waitTick(ctx, ticker)
 		return
-	} else if err != nil {
-		log.Errorf("failed to get pending tx, err: %v", err)
-		return
+	}
+
+	for _, tx := range txs {
+		// the txs are selected by the counters estimated when they were added
+		// to the pool, the ones that no longer fit are left for the next batch
+		if !fitsInZkCounters(tx.ZkCounters, s.calculateZkCounters()) {
+			return
+		}
+		if !s.processTx(ctx, tx) {
+			return
+		}
+	}
+}
+
+// processTx processes the tx into the batch in progress and marks it as
+// selected in the pool, or as pending again when it couldn't be processed.
+// Txs no longer allowed by the access control lists are marked as invalid.
+// It returns false when processing failed with an error
+func (s *Sequencer) processTx(ctx context.Context, tx pool.Transaction) bool {
+	if err := s.pool.CheckTxPermissions(tx.Transaction); err != nil {
+		log.Infof("tx %s is not allowed to be sequenced, marking it as invalid, err: %v", tx.Hash(), err)
+		if err := s.pool.UpdateTxState(ctx, tx.Hash(), pool.TxStateInvalid); err != nil {
+			log.Errorf("failed to update tx status on the pool, err: %v", err)
+			return false
+		}
+		return true
 	}
 
 	log.Infof("processing tx: %s", tx.Hash())
 	dbTx, err := s.state.BeginStateTransaction(ctx)
 	if err != nil {
 		log.Errorf("failed to begin state transaction for processing tx, err: %v", err)
-		return
+		return false
 	}
 
-	s.sequenceInProgress.Txs = append(s.sequenceInProgress.Txs, tx.Transaction)
-	processBatchResp, err := s.state.ProcessSequencerBatch(ctx, s.lastBatchNum, s.sequenceInProgress.Txs, dbTx)
+	// only the new tx is executed, on top of the state root left by the txs
+	// already stored in the batch
+	processBatchResp, err := s.state.ProcessSequencerTxs(ctx, s.lastBatchNum, []ethTypes.Transaction{tx.Transaction}, dbTx)
 	if err != nil {
-		s.sequenceInProgress.Txs = s.sequenceInProgress.Txs[:len(s.sequenceInProgress.Txs)-1]
 		if rollbackErr := dbTx.Rollback(ctx); rollbackErr != nil {
 			log.Errorf(
 				"failed to rollback dbTx when processing tx that gave err: %v. Rollback err: %v",
 				rollbackErr, err,
 			)
-			return
+			return false
 		}
 		log.Debugf("failed to process tx, hash: %s, err: %v", tx.Hash(), err)
-		return
-	}
-
-	if err := dbTx.Commit(ctx); err != nil {
-		log.Errorf("failed to commit dbTx when processing tx, err: %v", err)
-		return
-	}
-
-	s.sequenceInProgress.ZkCounters = pool.ZkCounters{
-		CumulativeGasUsed:    int64(processBatchResp.CumulativeGasUsed),
-		UsedKeccakHashes:     int32(processBatchResp.CntKeccakHashes),
-		UsedPoseidonHashes:   int32(processBatchResp.CntPoseidonHashes),
-		UsedPoseidonPaddings: int32(processBatchResp.CntPoseidonPaddings),
-		UsedMemAligns:        int32(processBatchResp.CntMemAligns),
-		UsedArithmetics:      int32(processBatchResp.CntArithmetics),
-		UsedBinaries:         int32(processBatchResp.CntBinaries),
-		UsedSteps:            int32(processBatchResp.CntSteps),
-	}
-	s.lastStateRoot = processBatchResp.NewStateRoot
-	s.lastLocalExitRoot = processBatchResp.NewLocalExitRoot
-
-	dbTx, err = s.state.BeginStateTransaction(ctx)
-	if err != nil {
-		log.Errorf("failed to begin state transaction for StoreTransactions, err: %v", err)
-		return
+		return false
 	}
 
 	processedTxs, unprocessedTxs := state.DetermineProcessedTransactions(processBatchResp.Responses)
 	// only save in DB processed transactions.
-	err = s.state.StoreTransactions(ctx, s.lastBatchNum, processedTxs, dbTx)
+	err = s.state.AppendTransactions(ctx, s.lastBatchNum, processedTxs, processBatchResp.NewLocalExitRoot, dbTx)
 	if err != nil {
-		s.sequenceInProgress.Txs = s.sequenceInProgress.Txs[:len(s.sequenceInProgress.Txs)-1]
 		if rollbackErr := dbTx.Rollback(ctx); rollbackErr != nil {
 			log.Errorf(
-				"failed to rollback dbTx when StoreTransactions that gave err: %v. Rollback err: %v",
+				"failed to rollback dbTx when AppendTransactions that gave err: %v. Rollback err: %v",
 				rollbackErr, err,
 			)
-			return
+			return false
 		}
 		log.Errorf("failed to store transactions, err: %v", err)
-		if err == state.ErrOutOfOrderProcessedTx || err == state.ErrExistingTxGreaterThanProcessedTx {
-			err = s.loadSequenceFromState(ctx)
-			log.Errorf("failed to load sequence from state, err: %v", err)
+		return false
+	}
+
+	zkCounters := s.sequenceInProgress.ZkCounters
+	if len(processedTxs) > 0 {
+		zkCounters, err = s.addTxZkCounters(ctx, zkCountersFromProcessBatchResponse(processBatchResp), dbTx)
+		if err != nil {
+			if rollbackErr := dbTx.Rollback(ctx); rollbackErr != nil {
+				log.Errorf(
+					"failed to rollback dbTx when measuring the batch zk counters overhead that gave err: %v. Rollback err: %v",
+					rollbackErr, err,
+				)
+				return false
+			}
+			log.Errorf("failed to measure the batch zk counters overhead, err: %v", err)
+			return false
+		}
+		if !fitsInZkCounters(zkCounters, s.cfg.MaxZkCounters()) {
+			// the tx is left for the next batch
+			if err := dbTx.Rollback(ctx); err != nil {
+				log.Errorf("failed to rollback dbTx when tx %s doesn't fit in the batch, err: %v", tx.Hash(), err)
+				return false
+			}
+			log.Infof("tx %s doesn't fit in the batch, marking tx as pending to return the pool", tx.Hash())
+			if err := s.pool.UpdateTxState(ctx, tx.Hash(), pool.TxStatePending); err != nil {
+				log.Errorf("failed to update tx status on the pool, err: %v", err)
+			}
+			s.closeSequence(ctx)
+			return false
 		}
-		return
 	}
 
 	if err := dbTx.Commit(ctx); err != nil {
-		log.Errorf("failed to commit dbTx when StoreTransactions, err: %v", err)
-		return
+		log.Errorf("failed to commit dbTx when processing tx, err: %v", err)
+		return false
+	}
+
+	s.lastStateRoot = processBatchResp.NewStateRoot
+	s.lastLocalExitRoot = processBatchResp.NewLocalExitRoot
+	if len(processedTxs) > 0 {
+		s.sequenceInProgress.Txs = append(s.sequenceInProgress.Txs, tx.Transaction)
+		s.sequenceInProgress.ZkCounters = zkCounters
 	}
 
 	var txState pool.TxState = pool.TxStateSelected
@@ -299,24 +355,89 @@ func (s *Sequencer) tryToProcessTx(ctx context.Context, ticker *time.Ticker) {
 	if _, ok := unprocessedTxs[tx.Hash().String()]; ok {
 		txState = pool.TxStatePending
 		txUpdateMsg = fmt.Sprintf("Tx %q failed to be processed. Marking tx as pending to return the pool", tx.Hash())
+		// the txs whose nonce was taken by another tx can never be processed,
+		// the rest are retried
+		nonceTaken, err := s.isNonceTaken(ctx, tx.Transaction)
+		if err != nil {
+			log.Errorf("failed to check the nonce of tx %s, err: %v", tx.Hash(), err)
+			return false
+		}
+		if nonceTaken {
+			txState = pool.TxStateInvalid
+			txUpdateMsg = fmt.Sprintf("Tx %q failed to be processed as its nonce was taken. Marking tx as invalid", tx.Hash())
+		}
 	}
 	log.Infof(txUpdateMsg)
 	if err := s.pool.UpdateTxState(ctx, tx.Hash(), txState); err != nil {
 		log.Errorf("failed to update tx status on the pool, err: %v", err)
-		return
+		return false
 	}
+	return true
+}
+
+// isNonceTaken checks if the nonce of the tx was already used by another tx
+// of its sender in the state
+func (s *Sequencer) isNonceTaken(ctx context.Context, tx ethTypes.Transaction) (bool, error) {
+	sender, err := state.GetSender(tx)
+	if err != nil {
+		return false, err
+	}
+	lastL2BlockNumber, err := s.state.GetLastL2BlockNumber(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	nonce, err := s.state.GetNonce(ctx, sender, lastL2BlockNumber, nil)
+	if err != nil {
+		return false, err
+	}
+	return tx.Nonce() < nonce, nil
+}
+
+// trySendSequences sends the closed sequences when they should be sent. When
+// they are too big to be sent at once, the last one is kept to be sent later.
+// It returns false when sending failed
+func (s *Sequencer) trySendSequences(ctx context.Context) bool {
+	log.Infof("checking if current sequence should be sent")
+	shouldSent, shouldCut := s.shouldSendSequences(ctx)
+	if shouldSent {
+		log.Infof("current sequence should be sent")
+		if shouldCut {
+			log.Infof("current sequence should be cut")
+			cutSequence := s.closedSequences[len(s.closedSequences)-1]
+			if err := s.txManager.SequenceBatches(s.closedSequences); err != nil {
+				log.Errorf("failed to SequenceBatches, err: %v", err)
+				return false
+			}
+			s.closedSequences = []types.Sequence{cutSequence}
+			// the deadline is kept while the remaining sequence includes
+			// forced batches, since they may be the ones that set it
+			if cutSequence.ForceBatchesNum == 0 {
+				s.forcedBatchesDeadline = time.Time{}
+			}
+		} else {
+			if err := s.txManager.SequenceBatches(s.closedSequences); err != nil {
+				log.Errorf("failed to SequenceBatches, err: %v", err)
+				return false
+			}
+			s.closedSequences = []types.Sequence{}
+			s.forcedBatchesDeadline = time.Time{}
+		}
+	}
+	return true
 }
 
 func (s *Sequencer) closeSequence(ctx context.Context) bool {
 	log.Infof("current sequence should be closed")
-	s.closedSequences = append(s.closedSequences, s.sequenceInProgress)
+	// newSequence sets the forced batches included after the sequence in
+	// progress, so it is added to the closed ones afterwards
 	newSequence, err := s.newSequence(ctx)
 	if err != nil {
 		log.Errorf("failed to create new sequence, err: %v", err)
-		s.closedSequences = s.closedSequences[:len(s.closedSequences)-1]
 		return false
 	}
+	s.closedSequences = append(s.closedSequences, s.sequenceInProgress)
 	s.sequenceInProgress = newSequence
+	s.claimsInProgress = 0
 	return true
 }
 
@@ -362,7 +483,10 @@ func (s *Sequencer) shouldSendSequences(ctx context.Context) (bool, bool) {
 		return false, false
 	}
 
-	// TODO: checkAgainstForcedBatchQueueTimeout
+	if !s.forcedBatchesDeadline.IsZero() && time.Now().After(s.forcedBatchesDeadline) {
+		log.Infof("sending sequences to sequence the forced batches before the force batch timeout")
+		return true, false
+	}
 
 	lastBatchVirtualizationTime, err := s.state.GetTimeForLatestBatchVirtualization(ctx, nil)
 	if err != nil && !errors.Is(err, state.ErrNotFound) {
@@ -381,8 +505,19 @@ func (s *Sequencer) shouldSendSequences(ctx context.Context) (bool, bool) {
 }
 
 // shouldCloseSequenceInProgress checks if sequence should be closed or not
-// in case it's enough blocks since last GER update, long time since last batch and sequence is profitable
+// in case it's enough blocks since last GER update, long time since last batch and sequence is profitable.
+// It is always closed when a pending forced batch has to be included to be sequenced before the force batch timeout
 func (s *Sequencer) shouldCloseSequenceInProgress(ctx context.Context) bool {
+	forcedBatches, err := s.state.GetNextForcedBatches(ctx, 1, nil)
+	if err != nil {
+		log.Errorf("failed to get next forced batch, err: %v", err)
+		return false
+	}
+	if len(forcedBatches) > 0 && time.Now().After(s.forcedBatchDeadline(forcedBatches[0])) {
+		log.Infof("forced batch %d has to be included before the force batch timeout", forcedBatches[0].ForcedBatchNumber)
+		return true
+	}
+
 	numberOfBlocks, err := s.state.GetNumberOfBlocksSinceLastGERUpdate(ctx, nil)
 	if err != nil && err != state.ErrNotFound {
 		log.Errorf("failed to get last time GER updated, err: %v", err)
@@ -435,9 +570,36 @@ func (s *Sequencer) newSequence(ctx context.Context) (types.Sequence, error) {
 			}
 			return types.Sequence{}, fmt.Errorf("failed to close batch, err: %v", err)
 		}
+		forcedBatches, forcedBatchesTimestamps, err := s.includeForcedBatches(ctx, dbTx)
+		if err != nil {
+			if rollbackErr := dbTx.Rollback(ctx); rollbackErr != nil {
+				return types.Sequence{}, fmt.Errorf(
+					"failed to rollback dbTx when including forced batches that gave err: %v. Rollback err: %v",
+					rollbackErr, err,
+				)
+			}
+			return types.Sequence{}, fmt.Errorf("failed to include forced batches, err: %v", err)
+		}
 		if err := dbTx.Commit(ctx); err != nil {
 			return types.Sequence{}, fmt.Errorf("failed to commit dbTx when close batch, err: %v", err)
 		}
+		s.sequenceInProgress.ForceBatchesNum = uint64(len(forcedBatches))
+		s.sequenceInProgress.ForceBatchesTimestamp = forcedBatchesTimestamps
+		if len(forcedBatches) > 0 {
+			// the forced batches are included in FIFO order, so the first one
+			// is the oldest of the closed sequences when the deadline is not
+			// set yet
+			if s.forcedBatchesDeadline.IsZero() {
+				s.forcedBatchesDeadline = s.forcedBatchDeadline(forcedBatches[0])
+			}
+			// the next batch is processed on top of the last forced batch
+			lastBatch, err := s.state.GetLastBatch(ctx, nil)
+			if err != nil {
+				return types.Sequence{}, fmt.Errorf("failed to get the last forced batch, err: %v", err)
+			}
+			s.lastStateRoot = lastBatch.StateRoot
+			s.lastLocalExitRoot = lastBatch.LocalExitRoot
+		}
 	} else {
 		return types.Sequence{}, errors.New("lastStateRoot and lastLocalExitRoot are empty, impossible to close a batch")
 	}
@@ -490,16 +652,99 @@ func (s *Sequencer) newSequence(ctx context.Context) (types.Sequence, error) {
 	}, nil
 }
 
+// includeForcedBatches stores the next pending forced batches, up to
+// MaxForcedBatchesPerSequence, as closed batches following the batch in
+// progress. It returns the included forced batches and their timestamps
+func (s *Sequencer) includeForcedBatches(ctx context.Context, dbTx pgx.Tx) ([]state.ForcedBatch, []uint64, error) {
+	if s.cfg.MaxForcedBatchesPerSequence == 0 {
+		return nil, nil, nil
+	}
+	forcedBatches, err := s.state.GetNextForcedBatches(ctx, int(s.cfg.MaxForcedBatchesPerSequence), dbTx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get next forced batches, err: %v", err)
+	}
+
+	lastTimestamp := time.Unix(s.sequenceInProgress.Timestamp, 0)
+	timestamps := make([]uint64, 0, len(forcedBatches))
+	for i, forcedBatch := range forcedBatches {
+		// the timestamp of a forced batch can't be lower than the one of the
+		// previous batch nor than the time it was forced
+		timestamp := lastTimestamp
+		if forcedBatch.ForcedAt.After(timestamp) {
+			timestamp = forcedBatch.ForcedAt
+		}
+		processingCtx := state.ProcessingContext{
+			BatchNumber:    s.lastBatchNum + uint64(i) + 1,
+			Coinbase:       forcedBatch.Sequencer,
+			Timestamp:      timestamp,
+			GlobalExitRoot: forcedBatch.GlobalExitRoot,
+		}
+		log.Infof("including forced batch %d as batch %d", forcedBatch.ForcedBatchNumber, processingCtx.BatchNumber)
+		err = s.state.ProcessAndStoreClosedBatch(ctx, processingCtx, forcedBatch.RawTxsData, dbTx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to process forced batch %d, err: %v", forcedBatch.ForcedBatchNumber, err)
+		}
+		err = s.state.AddBatchNumberInForcedBatch(ctx, forcedBatch.ForcedBatchNumber, processingCtx.BatchNumber, dbTx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to add batch number to forced batch %d, err: %v", forcedBatch.ForcedBatchNumber, err)
+		}
+		timestamps = append(timestamps, uint64(timestamp.Unix()))
+		lastTimestamp = timestamp
+	}
+
+	return forcedBatches, timestamps, nil
+}
+
+// forcedBatchDeadline returns the time at which the forced batch has to be
+// sequenced, a margin before anyone can sequence it
+func (s *Sequencer) forcedBatchDeadline(forcedBatch state.ForcedBatch) time.Time {
+	return forcedBatch.ForcedAt.Add(s.forceBatchTimeout - s.cfg.ForcedBatchesTimeoutMargin.Duration)
+}
+
 func (s *Sequencer) calculateZkCounters() pool.ZkCounters {
+	zkCounters := s.cfg.MaxZkCounters()
+	zkCounters.Sub(s.sequenceInProgress.ZkCounters)
+	return zkCounters
+}
+
+// addTxZkCounters returns the counters of the sequence in progress after
+// adding the ones of the execution of a new tx on top of it. Each execution
+// accounts for the fixed overhead of processing a batch, so it is only kept
+// for the first tx of the sequence.
+func (s *Sequencer) addTxZkCounters(ctx context.Context, txZkCounters pool.ZkCounters, dbTx pgx.Tx) (pool.ZkCounters, error) {
+	if len(s.sequenceInProgress.Txs) == 0 {
+		return txZkCounters, nil
+	}
+	if s.batchZkCountersOverhead == nil {
+		// the overhead is the same for every batch, so it is measured once
+		resp, err := s.state.ProcessSequencerTxs(ctx, s.lastBatchNum, nil, dbTx)
+		if err != nil {
+			return pool.ZkCounters{}, err
+		}
+		overhead := zkCountersFromProcessBatchResponse(resp)
+		s.batchZkCountersOverhead = &overhead
+	}
+	return sumTxZkCounters(s.sequenceInProgress.ZkCounters, txZkCounters, *s.batchZkCountersOverhead), nil
+}
+
+// sumTxZkCounters adds the counters of the execution of a tx to the ones of
+// the sequence, without the batch overhead already accounted by the sequence
+func sumTxZkCounters(sequenceZkCounters, txZkCounters, batchOverhead pool.ZkCounters) pool.ZkCounters {
+	sequenceZkCounters.SumUp(txZkCounters)
+	sequenceZkCounters.Sub(batchOverhead)
+	return sequenceZkCounters
+}
+
+func zkCountersFromProcessBatchResponse(resp *state.ProcessBatchResponse) pool.ZkCounters {
 	return pool.ZkCounters{
-		CumulativeGasUsed:    s.cfg.MaxGasUsed - s.sequenceInProgress.CumulativeGasUsed,
-		UsedKeccakHashes:     s.cfg.MaxKeccakHashes - s.sequenceInProgress.UsedKeccakHashes,
-		UsedPoseidonHashes:   s.cfg.MaxPoseidonHashes - s.sequenceInProgress.UsedKeccakHashes,
-		UsedPoseidonPaddings: s.cfg.MaxPoseidonPaddings - s.sequenceInProgress.UsedPoseidonPaddings,
-		UsedMemAligns:        s.cfg.MaxMemAligns - s.sequenceInProgress.UsedMemAligns,
-		UsedArithmetics:      s.cfg.MaxArithmetics - s.sequenceInProgress.UsedArithmetics,
-		UsedBinaries:         s.cfg.MaxBinaries - s.sequenceInProgress.UsedBinaries,
-		UsedSteps:            s.cfg.MaxSteps - s.sequenceInProgress.UsedSteps,
+		CumulativeGasUsed:    int64(resp.CumulativeGasUsed),
+		UsedKeccakHashes:     int32(resp.CntKeccakHashes),
+		UsedPoseidonHashes:   int32(resp.CntPoseidonHashes),
+		UsedPoseidonPaddings: int32(resp.CntPoseidonPaddings),
+		UsedMemAligns:        int32(resp.CntMemAligns),
+		UsedArithmetics:      int32(resp.CntArithmetics),
+		UsedBinaries:         int32(resp.CntBinaries),
+		UsedSteps:            int32(resp.CntSteps),
 	}
 }
 
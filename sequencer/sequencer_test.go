@@ -0,0 +1,80 @@
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-node/config/types"
+	ethmanTypes "github.com/0xPolygonHermez/zkevm-node/etherman/types"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestForcedBatchDeadline(t *testing.T) {
+	s := &Sequencer{
+		cfg:               Config{ForcedBatchesTimeoutMargin: types.NewDuration(time.Hour)},
+		forceBatchTimeout: 24 * time.Hour, //nolint:gomnd
+	}
+	forcedAt := time.Unix(1000, 0) //nolint:gomnd
+
+	deadline := s.forcedBatchDeadline(state.ForcedBatch{ForcedAt: forcedAt})
+	assert.Equal(t, forcedAt.Add(23*time.Hour), deadline) //nolint:gomnd
+}
+
+func TestShouldSendSequencesBeforeForcedBatchTimeout(t *testing.T) {
+	ethMan := new(ethermanMock)
+	closedSequences := []ethmanTypes.Sequence{{ForceBatchesNum: 1, ForceBatchesTimestamp: []uint64{1000}}}
+	ethMan.On("EstimateGasSequenceBatches", closedSequences).Return(uint64(100000), nil)
+
+	s := &Sequencer{
+		etherman:              ethMan,
+		closedSequences:       closedSequences,
+		forcedBatchesDeadline: time.Now().Add(-time.Second),
+	}
+
+	shouldSend, shouldCut := s.shouldSendSequences(context.Background())
+	assert.True(t, shouldSend)
+	assert.False(t, shouldCut)
+	ethMan.AssertExpectations(t)
+}
+
+func TestTrySendSequencesCutResetsForcedBatchesDeadline(t *testing.T) {
+	ethMan := new(ethermanMock)
+	txManager := newTxmanagerMock(t)
+	closedSequences := []ethmanTypes.Sequence{{ForceBatchesNum: 1, ForceBatchesTimestamp: []uint64{1000}}, {GlobalExitRoot: common.HexToHash("0x1")}}
+	ethMan.On("EstimateGasSequenceBatches", closedSequences).Return(uint64(0), errors.New(errGasRequiredExceedsAllowance))
+	txManager.On("SequenceBatches", mock.Anything).Return(nil)
+
+	s := &Sequencer{
+		etherman:              ethMan,
+		txManager:             txManager,
+		closedSequences:       closedSequences,
+		forcedBatchesDeadline: time.Now().Add(time.Hour),
+	}
+
+	assert.True(t, s.trySendSequences(context.Background()))
+	assert.Equal(t, []ethmanTypes.Sequence{closedSequences[1]}, s.closedSequences)
+	assert.True(t, s.forcedBatchesDeadline.IsZero())
+	ethMan.AssertExpectations(t)
+}
+
+func TestSumTxZkCounters(t *testing.T) {
+	batchOverhead := pool.ZkCounters{UsedSteps: 100, UsedPoseidonHashes: 10, UsedKeccakHashes: 1}
+	// the sequence already accounts for the overhead of the batch
+	sequenceZkCounters := pool.ZkCounters{CumulativeGasUsed: 21000, UsedSteps: 150, UsedPoseidonHashes: 15, UsedKeccakHashes: 2}
+	// the execution of the tx accounts for the overhead again
+	txZkCounters := pool.ZkCounters{CumulativeGasUsed: 50000, UsedSteps: 300, UsedPoseidonHashes: 30, UsedKeccakHashes: 3, UsedBinaries: 5}
+
+	assert.Equal(t, pool.ZkCounters{
+		CumulativeGasUsed:  71000,
+		UsedSteps:          350,
+		UsedPoseidonHashes: 35,
+		UsedKeccakHashes:   4,
+		UsedBinaries:       5,
+	}, sumTxZkCounters(sequenceZkCounters, txZkCounters, batchOverhead))
+}
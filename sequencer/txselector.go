@@ -0,0 +1,214 @@
+package sequencer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/0xPolygonHermez/zkevm-node/pool/pgpoolstorage"
+	"github.com/0xPolygonHermez/zkevm-node/state"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TxSelectionStrategy is the strategy used to select the pending txs of the
+// pool to process into the batch in progress
+type TxSelectionStrategy string
+
+const (
+	// GreedyStrategy selects the pending tx with the highest gas price that
+	// fits in the remaining zk counters of the batch
+	GreedyStrategy TxSelectionStrategy = "greedy"
+	// KnapsackStrategy selects the set of pending txs that maximizes the fees
+	// of the batch taking into account all the zk counters
+	KnapsackStrategy TxSelectionStrategy = "knapsack"
+)
+
+// TxSelectorConfig represents the configuration of the tx selection
+type TxSelectorConfig struct {
+	// Strategy is the strategy used to select the pending txs
+	Strategy TxSelectionStrategy `mapstructure:"Strategy"`
+
+	// MaxCandidates is the max number of pending txs considered by the
+	// knapsack strategy on each selection
+	MaxCandidates uint64 `mapstructure:"MaxCandidates"`
+}
+
+// txSelector selects the pending txs to process next into the batch in
+// progress, in the order they have to be processed
+type txSelector interface {
+	SelectTxs(ctx context.Context, remainingZkCounters pool.ZkCounters) ([]pool.Transaction, error)
+}
+
+func newTxSelector(cfg TxSelectorConfig, pool txPool, state stateInterface) (txSelector, error) {
+	switch cfg.Strategy {
+	case GreedyStrategy:
+		return &greedyTxSelector{pool: pool}, nil
+	case KnapsackStrategy:
+		return &knapsackTxSelector{pool: pool, state: state, maxCandidates: cfg.MaxCandidates}, nil
+	}
+	return nil, fmt.Errorf("unknown tx selection strategy %q", cfg.Strategy)
+}
+
+type greedyTxSelector struct {
+	pool txPool
+}
+
+// SelectTxs selects the pending tx with the highest gas price that fits in
+// the remaining zk counters
+func (gs *greedyTxSelector) SelectTxs(ctx context.Context, remainingZkCounters pool.ZkCounters) ([]pool.Transaction, error) {
+	tx, err := gs.pool.GetTopPendingTxByProfitabilityAndZkCounters(ctx, remainingZkCounters)
+	if err == pgpoolstorage.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return []pool.Transaction{*tx}, nil
+}
+
+type knapsackTxSelector struct {
+	pool          txPool
+	state         stateInterface
+	maxCandidates uint64
+}
+
+// SelectTxs selects, among the pending txs with the highest gas price, the
+// set of txs that maximizes the fees while fitting in the remaining zk
+// counters
+func (ks *knapsackTxSelector) SelectTxs(ctx context.Context, remainingZkCounters pool.ZkCounters) ([]pool.Transaction, error) {
+	candidates, err := ks.pool.GetPendingTxsFittingZkCounters(ctx, remainingZkCounters, ks.maxCandidates)
+	if err != nil {
+		return nil, err
+	}
+	txsBySender := groupTxsBySender(candidates)
+
+	lastL2BlockNumber, err := ks.state.GetLastL2BlockNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	nonces := make(map[common.Address]uint64, len(txsBySender))
+	for sender := range txsBySender {
+		nonce, err := ks.state.GetNonce(ctx, sender, lastL2BlockNumber, nil)
+		if err != nil {
+			return nil, err
+		}
+		nonces[sender] = nonce
+	}
+	return packTxs(txsBySender, nonces, remainingZkCounters), nil
+}
+
+// groupTxsBySender groups the txs by sender, keeping their order
+func groupTxsBySender(txs []pool.Transaction) map[common.Address][]pool.Transaction {
+	txsBySender := make(map[common.Address][]pool.Transaction)
+	for _, tx := range txs {
+		sender, err := state.GetSender(tx.Transaction)
+		if err != nil {
+			log.Warnf("failed to get the sender of tx %s, err: %v", tx.Hash(), err)
+			continue
+		}
+		txsBySender[sender] = append(txsBySender[sender], tx)
+	}
+	return txsBySender
+}
+
+// packTxs packs the candidate txs, grouped by sender, into the given zk
+// counters. Only the txs whose nonces run on from the account nonce of their
+// sender can be processed, so the rest are discarded. Finding the optimal set
+// is a multidimensional knapsack problem, so the txs are picked greedily by
+// the fee they pay per share of the scarcest remaining counter they use. The
+// txs of each sender are picked in nonce order, so once a tx of a sender
+// doesn't fit, the following ones of the same sender are discarded
+func packTxs(txsBySender map[common.Address][]pool.Transaction, nonces map[common.Address]uint64, remainingZkCounters pool.ZkCounters) []pool.Transaction {
+	// the senders are sorted so ties are always broken the same way
+	senders := make([]common.Address, 0, len(txsBySender))
+	candidates := 0
+	for sender, txs := range txsBySender {
+		txsBySender[sender] = executableTxs(txs, nonces[sender])
+		candidates += len(txsBySender[sender])
+		senders = append(senders, sender)
+	}
+	sort.Slice(senders, func(i, j int) bool { return bytes.Compare(senders[i].Bytes(), senders[j].Bytes()) < 0 })
+
+	selected := make([]pool.Transaction, 0, candidates)
+	for {
+		bestSender := -1
+		bestScore := math.Inf(-1)
+		for i, sender := range senders {
+			txs := txsBySender[sender]
+			if len(txs) == 0 {
+				continue
+			}
+			if !fitsInZkCounters(txs[0].ZkCounters, remainingZkCounters) {
+				txsBySender[sender] = nil
+				continue
+			}
+			if score := txScore(txs[0], remainingZkCounters); score > bestScore {
+				bestSender, bestScore = i, score
+			}
+		}
+		if bestSender < 0 {
+			return selected
+		}
+
+		txs := txsBySender[senders[bestSender]]
+		selected = append(selected, txs[0])
+		remainingZkCounters.Sub(txs[0].ZkCounters)
+		txsBySender[senders[bestSender]] = txs[1:]
+	}
+}
+
+// executableTxs returns, in nonce order, the txs of a sender whose nonces run
+// on from the given account nonce. When several txs have the same nonce, the
+// first one is taken
+func executableTxs(txs []pool.Transaction, nonce uint64) []pool.Transaction {
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].Nonce() < txs[j].Nonce() })
+
+	executable := make([]pool.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if tx.Nonce() < nonce {
+			continue
+		}
+		if tx.Nonce() > nonce {
+			break
+		}
+		executable = append(executable, tx)
+		nonce++
+	}
+	return executable
+}
+
+// txScore returns the fee paid by the tx per share of the remaining zk
+// counters it uses, taking the share of the counter it uses the most
+func txScore(tx pool.Transaction, remainingZkCounters pool.ZkCounters) float64 {
+	fee, _ := new(big.Float).SetInt(new(big.Int).Mul(tx.GasPrice(), big.NewInt(tx.CumulativeGasUsed))).Float64()
+
+	share := math.Max(counterShare(int64(tx.UsedKeccakHashes), int64(remainingZkCounters.UsedKeccakHashes)),
+		counterShare(tx.CumulativeGasUsed, remainingZkCounters.CumulativeGasUsed))
+	share = math.Max(share, counterShare(int64(tx.UsedPoseidonHashes), int64(remainingZkCounters.UsedPoseidonHashes)))
+	share = math.Max(share, counterShare(int64(tx.UsedPoseidonPaddings), int64(remainingZkCounters.UsedPoseidonPaddings)))
+	share = math.Max(share, counterShare(int64(tx.UsedMemAligns), int64(remainingZkCounters.UsedMemAligns)))
+	share = math.Max(share, counterShare(int64(tx.UsedArithmetics), int64(remainingZkCounters.UsedArithmetics)))
+	share = math.Max(share, counterShare(int64(tx.UsedBinaries), int64(remainingZkCounters.UsedBinaries)))
+	share = math.Max(share, counterShare(int64(tx.UsedSteps), int64(remainingZkCounters.UsedSteps)))
+
+	if share == 0 {
+		return math.Inf(1)
+	}
+	return fee / share
+}
+
+func counterShare(used, remaining int64) float64 {
+	if remaining <= 0 {
+		return 0
+	}
+	return float64(used) / float64(remaining)
+}
+
+func fitsInZkCounters(zkCounters, remainingZkCounters pool.ZkCounters) bool {
+	remainingZkCounters.Sub(zkCounters)
+	return !remainingZkCounters.IsZkCountersBelowZero()
+}
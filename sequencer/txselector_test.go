@@ -0,0 +1,101 @@
+package sequencer
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPoolTx(t *testing.T, key *ecdsa.PrivateKey, nonce uint64, gasPrice int64, zkCounters pool.ZkCounters) pool.Transaction {
+	tx := types.NewTransaction(nonce, common.HexToAddress("0x1"), big.NewInt(0), 21000, big.NewInt(gasPrice), nil) //nolint:gomnd
+
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(big.NewInt(1000)), key) //nolint:gomnd
+	require.NoError(t, err)
+	return pool.Transaction{Transaction: *signedTx, ZkCounters: zkCounters}
+}
+
+func TestPackTxs(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 3) //nolint:gomnd
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		keys[i] = key
+	}
+	remaining := pool.ZkCounters{
+		CumulativeGasUsed: 1000, UsedKeccakHashes: 10, UsedPoseidonHashes: 10, UsedPoseidonPaddings: 10,
+		UsedMemAligns: 10, UsedArithmetics: 10, UsedBinaries: 10, UsedSteps: 10,
+	}
+	senders := make(map[common.Address]uint64, len(keys))
+	for _, key := range keys {
+		senders[crypto.PubkeyToAddress(key.PublicKey)] = 0
+	}
+	fullKeccaks := pool.ZkCounters{CumulativeGasUsed: 100, UsedKeccakHashes: 10}
+	halfKeccaks := pool.ZkCounters{CumulativeGasUsed: 100, UsedKeccakHashes: 5}
+
+	t.Run("pick the txs that pay more fees in total", func(t *testing.T) {
+		expensive := newPoolTx(t, keys[0], 0, 10, fullKeccaks)
+		cheap1 := newPoolTx(t, keys[1], 0, 6, halfKeccaks)
+		cheap2 := newPoolTx(t, keys[2], 0, 6, halfKeccaks)
+
+		selected := packTxs(groupTxsBySender([]pool.Transaction{expensive, cheap1, cheap2}), senders, remaining)
+		require.Equal(t, 2, len(selected))
+		assert.ElementsMatch(t, []common.Hash{cheap1.Hash(), cheap2.Hash()}, []common.Hash{selected[0].Hash(), selected[1].Hash()})
+	})
+
+	t.Run("keep the nonce order of each sender", func(t *testing.T) {
+		tx0 := newPoolTx(t, keys[0], 0, 1, halfKeccaks)
+		tx1 := newPoolTx(t, keys[0], 1, 100, halfKeccaks)
+
+		selected := packTxs(groupTxsBySender([]pool.Transaction{tx1, tx0}), senders, remaining)
+		require.Equal(t, 2, len(selected))
+		assert.Equal(t, tx0.Hash(), selected[0].Hash())
+		assert.Equal(t, tx1.Hash(), selected[1].Hash())
+	})
+
+	t.Run("discard the next txs of a sender when one doesn't fit", func(t *testing.T) {
+		tx0 := newPoolTx(t, keys[0], 0, 1, halfKeccaks)
+		tx1 := newPoolTx(t, keys[0], 1, 1, fullKeccaks)
+		tx2 := newPoolTx(t, keys[0], 2, 100, pool.ZkCounters{})
+
+		selected := packTxs(groupTxsBySender([]pool.Transaction{tx0, tx1, tx2}), senders, remaining)
+		require.Equal(t, 1, len(selected))
+		assert.Equal(t, tx0.Hash(), selected[0].Hash())
+	})
+
+	t.Run("only take the txs that run on from the account nonce", func(t *testing.T) {
+		sender := crypto.PubkeyToAddress(keys[0].PublicKey)
+		processed := newPoolTx(t, keys[0], 0, 100, pool.ZkCounters{})
+		tx1 := newPoolTx(t, keys[0], 1, 1, pool.ZkCounters{})
+		tx1Cheaper := newPoolTx(t, keys[0], 1, 0, pool.ZkCounters{})
+		tx2 := newPoolTx(t, keys[0], 2, 1, pool.ZkCounters{})
+		afterGap := newPoolTx(t, keys[0], 4, 100, pool.ZkCounters{})
+
+		selected := packTxs(groupTxsBySender([]pool.Transaction{processed, afterGap, tx1, tx2, tx1Cheaper}), map[common.Address]uint64{sender: 1}, remaining)
+		require.Equal(t, 2, len(selected))
+		assert.Equal(t, tx1.Hash(), selected[0].Hash())
+		assert.Equal(t, tx2.Hash(), selected[1].Hash())
+	})
+
+	t.Run("discard the txs of a sender with a nonce gap", func(t *testing.T) {
+		tx1 := newPoolTx(t, keys[0], 1, 100, pool.ZkCounters{})
+
+		selected := packTxs(groupTxsBySender([]pool.Transaction{tx1}), senders, remaining)
+		assert.Equal(t, 0, len(selected))
+	})
+}
+
+func TestNewTxSelector(t *testing.T) {
+	_, err := newTxSelector(TxSelectorConfig{Strategy: GreedyStrategy}, nil, nil)
+	require.NoError(t, err)
+	_, err = newTxSelector(TxSelectorConfig{Strategy: KnapsackStrategy}, nil, nil)
+	require.NoError(t, err)
+	_, err = newTxSelector(TxSelectorConfig{Strategy: "unknown"}, nil, nil)
+	require.Error(t, err)
+}
@@ -4,4 +4,8 @@ package state
 type Config struct {
 	// MaxCumulativeGasUsed is the max gas allowed per batch
 	MaxCumulativeGasUsed uint64
+
+	// IndexTokenTransfers enables storing the Transfer and Approval events of
+	// ERC-20 and ERC-721 tokens along with the l2 blocks emitting them
+	IndexTokenTransfers bool
 }
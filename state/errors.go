@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/0xPolygonHermez/zkevm-node/state/runtime"
 	"github.com/umbracle/ethgo/abi"
 )
 
@@ -44,8 +45,39 @@ var (
 	// ErrInsufficientFunds is returned if the total cost of executing a transaction
 	// is higher than the balance of the user's account.
 	ErrInsufficientFunds = errors.New("insufficient funds for gas * price + value")
+	// ErrGasRequiredExceedsAllowance indicates the transaction can't be executed
+	// even with the highest gas limit allowed for the estimation
+	ErrGasRequiredExceedsAllowance = errors.New("gas required exceeds allowance")
 )
 
+// executorErrors are the known errors that can be reported by the executor
+// when processing a transaction
+var executorErrors = []error{
+	ErrNotEnoughIntrinsicGas,
+	runtime.ErrOutOfGas,
+	runtime.ErrStackOverflow,
+	runtime.ErrStackUnderflow,
+	runtime.ErrNotEnoughFunds,
+	runtime.ErrInsufficientBalance,
+	runtime.ErrCodeNotFound,
+	runtime.ErrMaxCodeSizeExceeded,
+	runtime.ErrContractAddressCollision,
+	runtime.ErrDepth,
+	runtime.ErrExecutionReverted,
+	runtime.ErrCodeStoreOutOfGas,
+}
+
+// executorErr converts the error message returned by the executor into the
+// matching known error, so it can be checked using errors.Is
+func executorErr(msg string) error {
+	for _, err := range executorErrors {
+		if msg == err.Error() {
+			return err
+		}
+	}
+	return errors.New(msg)
+}
+
 func constructErrorFromRevert(err error, returnValue []byte) error {
 	revertErrMsg, unpackErr := abi.UnpackRevertError(returnValue)
 	if unpackErr != nil {
@@ -0,0 +1,14 @@
+package state
+
+import (
+	"math/big"
+)
+
+// GasPriceAvg is the running average of the gas price paid by the txs of the
+// l2 blocks up to LastL2BlockNumber
+type GasPriceAvg struct {
+	LastL2BlockNumber uint64
+	Avg               *big.Int
+	// Count is the number of txs averaged
+	Count uint64
+}
@@ -5,11 +5,32 @@ import (
 	"math/big"
 
 	"github.com/0xPolygonHermez/zkevm-node/hex"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
-const ether155V = 27
+const (
+	ether155V = 27
+	// signatureLength is the length of the r, s and v values that follow each
+	// RLP encoded tx in the batch data
+	signatureLength = 65
+	rLength         = 32
+	sLength         = 32
+)
+
+// encodedTx are the fields of a tx RLP encoded in the batch data
+type encodedTx struct {
+	Nonce    uint64
+	GasPrice *big.Int
+	Gas      uint64
+	To       *common.Address `rlp:"nil"`
+	Value    *big.Int
+	Data     []byte
+	ChainID  *big.Int
+	Zero1    uint
+	Zero2    uint
+}
 
 // EncodeTransactions RLP encodes the given transactions.
 func EncodeTransactions(txs []types.Transaction) ([]byte, error) {
@@ -50,13 +71,73 @@ func EncodeTransactions(txs []types.Transaction) ([]byte, error) {
 	return batchL2Data, nil
 }
 
-func generateReceipt(block *types.Block, processedTx *ProcessTransactionResponse) *types.Receipt {
+// DecodeTxs decodes the transactions of the batch data encoded by
+// EncodeTransactions.
+func DecodeTxs(batchL2Data []byte) ([]types.Transaction, error) {
+	txs, err := decodeTxs(batchL2Data)
+	if err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+// decodeTxs decodes the transactions of the batch data, returning the ones
+// decoded before the malformed one along with the error
+func decodeTxs(batchL2Data []byte) ([]types.Transaction, error) {
+	var txs []types.Transaction
+
+	for pos := 0; pos < len(batchL2Data); {
+		kind, _, rest, err := rlp.Split(batchL2Data[pos:])
+		if err != nil {
+			return txs, err
+		}
+		if kind != rlp.List {
+			return txs, fmt.Errorf("invalid tx encoding at position %d", pos)
+		}
+		rlpLength := len(batchL2Data[pos:]) - len(rest)
+		if len(rest) < signatureLength {
+			return txs, fmt.Errorf("missing signature of tx at position %d", pos)
+		}
+
+		var decoded encodedTx
+		if err := rlp.DecodeBytes(batchL2Data[pos:pos+rlpLength], &decoded); err != nil {
+			return txs, err
+		}
+
+		r := new(big.Int).SetBytes(rest[:rLength])
+		s := new(big.Int).SetBytes(rest[rLength : rLength+sLength])
+		sign := int64(rest[rLength+sLength]) - ether155V
+		// restore the v value of the signature, EIP-155 protected when the tx
+		// has a chain id
+		v := big.NewInt(ether155V + sign)
+		if decoded.ChainID.Sign() > 0 {
+			v = new(big.Int).Add(new(big.Int).Mul(decoded.ChainID, big.NewInt(2)), big.NewInt(35+sign)) //nolint:gomnd
+		}
+
+		txs = append(txs, *types.NewTx(&types.LegacyTx{
+			Nonce:    decoded.Nonce,
+			GasPrice: decoded.GasPrice,
+			Gas:      decoded.Gas,
+			To:       decoded.To,
+			Value:    decoded.Value,
+			Data:     decoded.Data,
+			V:        v,
+			R:        r,
+			S:        s,
+		}))
+
+		pos += rlpLength + signatureLength
+	}
+
+	return txs, nil
+}
+
+func generateReceipt(blockNumber *big.Int, processedTx *ProcessTransactionResponse) *types.Receipt {
 	receipt := &types.Receipt{
 		Type:              uint8(processedTx.Type),
 		PostState:         processedTx.StateRoot.Bytes(),
 		CumulativeGasUsed: processedTx.GasUsed,
-		BlockNumber:       block.Number(),
-		BlockHash:         block.Hash(),
+		BlockNumber:       blockNumber,
 		GasUsed:           processedTx.GasUsed,
 		TxHash:            processedTx.Tx.Hash(),
 		TransactionIndex:  0,
@@ -70,5 +151,31 @@ func generateReceipt(block *types.Block, processedTx *ProcessTransactionResponse
 		receipt.Status = types.ReceiptStatusFailed
 	}
 
+	for _, l := range receipt.Logs {
+		l.BlockNumber = blockNumber.Uint64()
+		l.TxHash = receipt.TxHash
+		l.TxIndex = receipt.TransactionIndex
+	}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
 	return receipt
 }
+
+// setReceiptBlockHash sets the hash of the block containing the receipt once
+// the block has been built, since the block hash depends on the receipts root
+func setReceiptBlockHash(receipt *types.Receipt, blockHash common.Hash) {
+	receipt.BlockHash = blockHash
+	for _, l := range receipt.Logs {
+		l.BlockHash = blockHash
+	}
+}
+
+// EffectiveGasPrice returns the price per gas paid by the transaction. Since
+// there is no base fee in L2, dynamic fee transactions pay their tip capped by
+// the fee cap
+func EffectiveGasPrice(tx *types.Transaction) *big.Int {
+	if tx.Type() == types.DynamicFeeTxType {
+		return tx.EffectiveGasTipValue(big.NewInt(0))
+	}
+	return new(big.Int).Set(tx.GasPrice())
+}
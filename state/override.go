@@ -0,0 +1,76 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrStorageOverrideNotSupported indicates the whole storage of an account
+// can't be replaced, since the keys stored in the merkletree are unknown
+var ErrStorageOverrideNotSupported = errors.New("overriding the whole storage of an account is not supported, use stateDiff instead")
+
+// StateOverride is the collection of accounts to be overridden before
+// executing an unsigned transaction, indexed by address
+type StateOverride map[common.Address]OverrideAccount
+
+// OverrideAccount contains the fields of an account to be overridden,
+// nil fields keep the values found in the state
+type OverrideAccount struct {
+	Nonce     *uint64
+	Code      *[]byte
+	Balance   *big.Int
+	State     map[common.Hash]common.Hash
+	StateDiff map[common.Hash]common.Hash
+}
+
+// applyStateOverride applies the overridden accounts on top of the given
+// state root and returns the resulting root. The changes are not persisted,
+// so the returned root must only be used to execute the unsigned transaction
+func (s *State) applyStateOverride(ctx context.Context, root common.Hash, stateOverride StateOverride) (common.Hash, error) {
+	if len(stateOverride) == 0 {
+		return root, nil
+	}
+
+	tree := s.tree.Ephemeral()
+	newRoot := root.Bytes()
+	var err error
+
+	for address, account := range stateOverride {
+		if account.State != nil {
+			return common.Hash{}, ErrStorageOverrideNotSupported
+		}
+
+		if account.Nonce != nil {
+			newRoot, _, err = tree.SetNonce(ctx, address, new(big.Int).SetUint64(*account.Nonce), newRoot)
+			if err != nil {
+				return common.Hash{}, err
+			}
+		}
+
+		if account.Code != nil {
+			newRoot, _, err = tree.SetCode(ctx, address, *account.Code, newRoot)
+			if err != nil {
+				return common.Hash{}, err
+			}
+		}
+
+		if account.Balance != nil {
+			newRoot, _, err = tree.SetBalance(ctx, address, account.Balance, newRoot)
+			if err != nil {
+				return common.Hash{}, err
+			}
+		}
+
+		for key, value := range account.StateDiff {
+			newRoot, _, err = tree.SetStorageAt(ctx, address, key.Big(), value.Big(), newRoot)
+			if err != nil {
+				return common.Hash{}, err
+			}
+		}
+	}
+
+	return common.BytesToHash(newRoot), nil
+}
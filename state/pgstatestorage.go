@@ -8,10 +8,10 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/0xPolygonHermez/zkevm-node/encoding"
 	"github.com/0xPolygonHermez/zkevm-node/hex"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/trie"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
@@ -21,6 +21,7 @@ const maxTopics = 4
 const (
 	addGlobalExitRootSQL                     = "INSERT INTO state.exit_root (block_num, global_exit_root_num, mainnet_exit_root, rollup_exit_root, global_exit_root) VALUES ($1, $2, $3, $4, $5)"
 	getLatestExitRootSQL                     = "SELECT block_num, global_exit_root_num, mainnet_exit_root, rollup_exit_root, global_exit_root FROM state.exit_root ORDER BY global_exit_root_num DESC LIMIT 1"
+	getExitRootByGlobalExitRootSQL           = "SELECT block_num, global_exit_root_num, mainnet_exit_root, rollup_exit_root, global_exit_root FROM state.exit_root WHERE global_exit_root = $1 ORDER BY global_exit_root_num LIMIT 1"
 	getLatestExitRootBlockNumSQL             = "SELECT block_num FROM state.exit_root ORDER BY global_exit_root_num DESC LIMIT 1"
 	addVirtualBatchSQL                       = "INSERT INTO state.virtual_batch (batch_num, tx_hash, coinbase, block_num) VALUES ($1, $2, $3, $4)"
 	addForcedBatchSQL                        = "INSERT INTO state.forced_batch (forced_batch_num, global_exit_root, timestamp, raw_txs_data, coinbase, batch_num, block_num) VALUES ($1, $2, $3, $4, $5, $6, $7)"
@@ -30,6 +31,7 @@ const (
 	getPreviousBlockSQL                      = "SELECT block_num, block_hash, parent_hash, received_at FROM state.block ORDER BY block_num DESC LIMIT 1 OFFSET $1"
 	resetSQL                                 = "DELETE FROM state.block WHERE block_num > $1"
 	resetTrustedStateSQL                     = "DELETE FROM state.batch WHERE batch_num > $1"
+	resetForcedBatchesSQL                    = "UPDATE state.forced_batch SET batch_num = NULL WHERE batch_num > $1"
 	addVerifiedBatchSQL                      = "INSERT INTO state.verified_batch (block_num, batch_num, tx_hash, aggregator) VALUES ($1, $2, $3, $4)"
 	getVerifiedBatchSQL                      = "SELECT block_num, batch_num, tx_hash, aggregator FROM state.verified_batch WHERE batch_num = $1"
 	getLastBatchNumberSQL                    = "SELECT batch_num FROM state.batch ORDER BY batch_num DESC LIMIT 1"
@@ -52,27 +54,30 @@ const (
 	addGenesisBatchSQL                       = `INSERT INTO state.batch (batch_num, global_exit_root, local_exit_root, state_root, timestamp, coinbase, raw_txs_data) VALUES ($1, $2, $3, $4, $5, $6, $7)`
 	openBatchSQL                             = "INSERT INTO state.batch (batch_num, global_exit_root, timestamp, coinbase) VALUES ($1, $2, $3, $4)"
 	closeBatchSQL                            = "UPDATE state.batch SET state_root = $1, local_exit_root = $2, raw_txs_data = $3 WHERE batch_num = $4"
-	getNextForcedBatchesSQL                  = "SELECT forced_batch_num, global_exit_root, timestamp, raw_txs_data, coinbase, batch_num, block_num FROM state.forced_batch WHERE batch_num IS NULL LIMIT $1"
+	updateOpenBatchLocalExitRootSQL          = "UPDATE state.batch SET local_exit_root = $1 WHERE batch_num = $2 AND state_root IS NULL"
+	getNextForcedBatchesSQL                  = "SELECT forced_batch_num, global_exit_root, timestamp, raw_txs_data, coinbase, batch_num, block_num FROM state.forced_batch WHERE batch_num IS NULL ORDER BY forced_batch_num LIMIT $1"
 	addBatchNumberInForcedBatchSQL           = "UPDATE state.forced_batch SET batch_num = $2 WHERE forced_batch_num = $1"
 	getL2BlockByNumberSQL                    = "SELECT header, uncles, received_at FROM state.l2block b WHERE b.block_num = $1"
 	getL2BlockHeaderByNumberSQL              = "SELECT header FROM state.l2block b WHERE b.block_num = $1"
+	getL2BlocksByNumberRangeSQL              = "SELECT b.block_num, b.header, t.encoded FROM state.l2block b LEFT JOIN state.transaction t ON t.l2_block_num = b.block_num WHERE b.block_num BETWEEN $1 AND $2 ORDER BY b.block_num ASC"
 	getTransactionByHashSQL                  = "SELECT transaction.encoded FROM state.transaction WHERE hash = $1"
-	getReceiptSQL                            = "SELECT r.tx_hash, r.type, r.post_state, r.status, r.cumulative_gas_used, r.gas_used, r.contract_address, t.encoded, t.l2_block_num, b.block_hash FROM state.receipt r INNER JOIN state.transaction t ON t.hash = r.tx_hash INNER JOIN state.l2block b ON b.block_num = t.l2_block_num WHERE r.tx_hash = $1"
+	getReceiptSQL                            = "SELECT r.tx_hash, r.type, r.post_state, r.status, r.cumulative_gas_used, r.gas_used, r.contract_address, r.tx_index, r.bloom, t.encoded, t.l2_block_num, b.block_hash FROM state.receipt r INNER JOIN state.transaction t ON t.hash = r.tx_hash INNER JOIN state.l2block b ON b.block_num = t.l2_block_num WHERE r.tx_hash = $1"
 	getTransactionByL2BlockHashAndIndexSQL   = "SELECT t.encoded FROM state.transaction t INNER JOIN state.l2block b ON t.l2_block_num = b.batch_num WHERE b.block_hash = $1 AND 0 = $2"
 	getTransactionByL2BlockNumberAndIndexSQL = "SELECT t.encoded FROM state.transaction t WHERE t.l2_block_num = $1 AND 0 = $2"
 	getL2BlockTransactionCountByHashSQL      = "SELECT COUNT(*) FROM state.transaction t INNER JOIN state.l2block b ON b.block_num = t.l2_block_num WHERE b.block_hash = $1"
 	getL2BlockTransactionCountByNumberSQL    = "SELECT COUNT(*) FROM state.transaction t WHERE t.l2_block_num = $1"
 	addL2BlockSQL                            = "INSERT INTO state.l2block (block_num, block_hash, header, uncles, parent_hash, state_root, received_at, batch_num) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
 	getLastConsolidatedBlockNumberSQL        = "SELECT b.block_num FROM state.l2block b INNER JOIN state.verified_batch vb ON vb.batch_num = b.batch_num ORDER BY b.block_num DESC LIMIT 1"
+	getLastVirtualBlockNumberSQL             = "SELECT b.block_num FROM state.l2block b INNER JOIN state.virtual_batch vb ON vb.batch_num = b.batch_num ORDER BY b.block_num DESC LIMIT 1"
 	getLastVirtualBlockHeaderSQL             = "SELECT b.header FROM state.l2block b INNER JOIN state.virtual_batch vb ON vb.batch_num = b.batch_num ORDER BY b.block_num DESC LIMIT 1"
 	getL2BlockByHashSQL                      = "SELECT header, uncles, received_at FROM state.l2block b WHERE b.block_hash = $1"
 	getLastL2BlockSQL                        = "SELECT header, uncles, received_at FROM state.l2block b ORDER BY b.block_num DESC LIMIT 1"
 	getL2BlockHeaderByHashSQL                = "SELECT header FROM state.l2block b WHERE b.block_hash = $1"
 	getTxsByBlockNumSQL                      = "SELECT encoded FROM state.transaction WHERE l2_block_num = $1"
 	getL2BlockHashesSinceSQL                 = "SELECT block_hash FROM state.l2block WHERE received_at >= $1"
-	getTransactionLogsSQL                    = "SELECT t.l2_block_num, b.block_hash, l.tx_hash, l.log_index, l.address, l.data, l.topic0, l.topic1, l.topic2, l.topic3 FROM state.log l INNER JOIN state.transaction t ON t.hash = l.tx_hash INNER JOIN state.l2block b ON b.block_num = t.l2_block_num WHERE t.hash = $1"
-	getLogsByBlockHashSQL                    = "SELECT t.l2_block_num, b.block_hash, l.tx_hash, l.log_index, l.address, l.data, l.topic0, l.topic1, l.topic2, l.topic3 FROM state.log l INNER JOIN state.transaction t ON t.hash = l.tx_hash INNER JOIN state.l2block b ON b.block_num = t.l2_block_num WHERE b.block_hash = $1"
-	getLogsByFilterSQL                       = "SELECT t.l2_block_num, b.block_hash, l.tx_hash, l.log_index, l.address, l.data, l.topic0, l.topic1, l.topic2, l.topic3 FROM state.log l INNER JOIN state.transaction t ON t.hash = l.tx_hash INNER JOIN state.l2block b ON b.block_num = t.l2_block_num WHERE l.batch_num BETWEEN $1 AND $2 AND (l.address = any($3) OR $3 IS NULL) AND (l.topic0 = any($4) OR $4 IS NULL) AND (l.topic1 = any($5) OR $5 IS NULL) AND (l.topic2 = any($6) OR $6 IS NULL) AND (l.topic3 = any($7) OR $7 IS NULL) AND (b.received_at >= $8 OR $8 IS NULL)"
+	getTransactionLogsSQL                    = "SELECT l.block_num, l.block_hash, l.tx_hash, l.tx_index, l.log_index, l.address, l.data, l.topic0, l.topic1, l.topic2, l.topic3 FROM state.log l WHERE l.tx_hash = $1 ORDER BY l.log_index ASC"
+	getLogsByBlockHashSQL                    = "SELECT l.block_num, l.block_hash, l.tx_hash, l.tx_index, l.log_index, l.address, l.data, l.topic0, l.topic1, l.topic2, l.topic3 FROM state.log l WHERE l.block_hash = $1 ORDER BY l.log_index ASC"
+	getLogsByFilterSQL                       = "SELECT l.block_num, l.block_hash, l.tx_hash, l.tx_index, l.log_index, l.address, l.data, l.topic0, l.topic1, l.topic2, l.topic3 FROM state.log l WHERE l.block_num BETWEEN $1 AND $2"
 	getSyncingInfoSQL                        = `
 		SELECT coalesce(MIN(initial_blocks.block_num), 0) as init_sync_block
 			 , coalesce(MAX(virtual_blocks.block_num), 0) as last_block_num_seen
@@ -88,11 +93,14 @@ const (
 		 INNER JOIN state.l2block consolidated_blocks
 			ON consolidated_blocks.batch_num = sy.last_batch_num_consolidated;
 	`
-	addTransactionSQL          = "INSERT INTO state.transaction (hash, from_address, encoded, decoded, l2_block_num) VALUES($1, $2, $3, $4, $5)"
-	addReceiptSQL              = "INSERT INTO state.receipt (tx_hash, type, post_state, status, cumulative_gas_used, gas_used, block_num, tx_index, contract_address) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
-	addLogSQL                  = "INSERT INTO state.log (transaction_hash, log_index, transaction_index, address, data, topic0, topic1, topic2, topic3) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
-	getBatchNumByBlockNum      = "SELECT batch_num FROM state.virtual_batch WHERE block_num = $1 ORDER BY batch_num ASC LIMIT 1"
-	getTxsHashesBeforeBatchNum = "SELECT hash FROM state.transaction JOIN state.l2block ON state.transaction.l2_block_num = state.l2block.block_num AND state.l2block.batch_num <= $1"
+	addTransactionSQL                  = "INSERT INTO state.transaction (hash, from_address, to_address, encoded, decoded, l2_block_num) VALUES($1, $2, $3, $4, $5, $6)"
+	addReceiptSQL                      = "INSERT INTO state.receipt (tx_hash, type, post_state, status, cumulative_gas_used, gas_used, block_num, tx_index, contract_address, bloom, effective_gas_price) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)"
+	addLogSQL                          = "INSERT INTO state.log (tx_hash, log_index, block_num, block_hash, tx_index, address, data, topic0, topic1, topic2, topic3) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)"
+	getBatchNumByBlockNum              = "SELECT batch_num FROM state.virtual_batch WHERE block_num = $1 ORDER BY batch_num ASC LIMIT 1"
+	getTxsHashesBeforeBatchNum         = "SELECT hash FROM state.transaction JOIN state.l2block ON state.transaction.l2_block_num = state.l2block.block_num AND state.l2block.batch_num <= $1"
+	getL2BlocksTxHashesFromBatchNumSQL = "SELECT b.block_num, t.hash FROM state.l2block b LEFT JOIN state.transaction t ON t.l2_block_num = b.block_num WHERE b.batch_num >= $1 ORDER BY b.block_num ASC"
+	addTrustedReorgSQL                 = "INSERT INTO state.trusted_reorg (batch_num, block_num, divergent_fields, old_state_root, new_state_root, l2_block_nums, tx_hashes, reported_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id"
+	getTrustedReorgsSQL                = "SELECT id, batch_num, block_num, divergent_fields, old_state_root, new_state_root, l2_block_nums, tx_hashes, reported_at FROM state.trusted_reorg WHERE batch_num >= $1 ORDER BY id ASC LIMIT $2"
 )
 
 // PostgresStorage implements the Storage interface
@@ -126,12 +134,16 @@ func (p *PostgresStorage) Reset(ctx context.Context, blockNumber uint64, dbTx pg
 }
 
 // ResetTrustedState removes the batches with number greater than the given one
-// from the database.
+// from the database. The forced batches included in the removed batches go
+// back to the queue of forced batches to be sequenced.
 func (p *PostgresStorage) ResetTrustedState(ctx context.Context, batchNum uint64, dbTx pgx.Tx) error {
 	e := p.getExecQuerier(dbTx)
 	if _, err := e.Exec(ctx, resetTrustedStateSQL, batchNum); err != nil {
 		return err
 	}
+	if _, err := e.Exec(ctx, resetForcedBatchesSQL, batchNum); err != nil {
+		return err
+	}
 	// TODO: Remove consolidations
 	return nil
 }
@@ -249,6 +261,25 @@ func (p *PostgresStorage) GetLatestGlobalExitRoot(ctx context.Context, dbTx pgx.
 	return &exitRoot, nil
 }
 
+// GetExitRootByGlobalExitRoot gets the first synced ExitRoot with the given
+// global exit root.
+func (p *PostgresStorage) GetExitRootByGlobalExitRoot(ctx context.Context, ger common.Hash, dbTx pgx.Tx) (*GlobalExitRoot, error) {
+	var (
+		exitRoot  GlobalExitRoot
+		globalNum uint64
+	)
+
+	e := p.getExecQuerier(dbTx)
+	err := e.QueryRow(ctx, getExitRootByGlobalExitRootSQL, ger).Scan(&exitRoot.BlockNumber, &globalNum, &exitRoot.MainnetExitRoot, &exitRoot.RollupExitRoot, &exitRoot.GlobalExitRoot)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	exitRoot.GlobalExitRootNum = new(big.Int).SetUint64(globalNum)
+	return &exitRoot, nil
+}
+
 // GetNumberOfBlocksSinceLastGERUpdate gets number of blocks since last global exit root update
 func (p *PostgresStorage) GetNumberOfBlocksSinceLastGERUpdate(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
 	var (
@@ -643,6 +674,14 @@ func (p *PostgresStorage) closeBatch(ctx context.Context, receipt ProcessingRece
 	return err
 }
 
+// updateOpenBatchLocalExitRoot sets the intermediate local exit root of an
+// open batch, which is replaced by the final one when the batch is closed
+func (p *PostgresStorage) updateOpenBatchLocalExitRoot(ctx context.Context, batchNum uint64, localExitRoot common.Hash, dbTx pgx.Tx) error {
+	e := p.getExecQuerier(dbTx)
+	_, err := e.Exec(ctx, updateOpenBatchLocalExitRootSQL, localExitRoot.String(), batchNum)
+	return err
+}
+
 // IsBatchClosed indicates if the batch referenced by batchNum is closed or not
 func (p *PostgresStorage) IsBatchClosed(ctx context.Context, batchNum uint64, dbTx pgx.Tx) (bool, error) {
 	q := p.getExecQuerier(dbTx)
@@ -711,15 +750,7 @@ func (p *PostgresStorage) GetL2BlockByNumber(ctx context.Context, blockNumber ui
 		return nil, err
 	}
 
-	transactions, err := p.GetTxsByBlockNumber(ctx, header.Number.Uint64(), dbTx)
-	if errors.Is(err, pgx.ErrNoRows) {
-		transactions = []*types.Transaction{}
-	} else if err != nil {
-		return nil, err
-	}
-
-	block := types.NewBlock(header, transactions, uncles, nil, &trie.StackTrie{})
-	return block, nil
+	return p.newL2BlockWithTxs(ctx, header, uncles, dbTx)
 }
 
 // GetTransactionByHash gets a transaction accordingly to the provided transaction hash
@@ -744,8 +775,10 @@ func (p *PostgresStorage) GetTransactionByHash(ctx context.Context, transactionH
 
 // GetTransactionReceipt gets a transaction receipt accordingly to the provided transaction hash
 func (p *PostgresStorage) GetTransactionReceipt(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*types.Receipt, error) {
-	var txHash, encodedTx, contractAddress, l2BlockHash string
+	var txHash, encodedTx, l2BlockHash string
+	var contractAddress *string
 	var l2BlockNum uint64
+	var bloom []byte
 
 	receipt := types.Receipt{}
 	q := p.getExecQuerier(dbTx)
@@ -757,6 +790,8 @@ func (p *PostgresStorage) GetTransactionReceipt(ctx context.Context, transaction
 			&receipt.CumulativeGasUsed,
 			&receipt.GasUsed,
 			&contractAddress,
+			&receipt.TransactionIndex,
+			&bloom,
 			&encodedTx,
 			&l2BlockNum,
 			&l2BlockHash,
@@ -769,7 +804,9 @@ func (p *PostgresStorage) GetTransactionReceipt(ctx context.Context, transaction
 	}
 
 	receipt.TxHash = common.HexToHash(txHash)
-	receipt.ContractAddress = common.HexToAddress(contractAddress)
+	if contractAddress != nil {
+		receipt.ContractAddress = common.HexToAddress(*contractAddress)
+	}
 
 	logs, err := p.getTransactionLogs(ctx, transactionHash, dbTx)
 	if !errors.Is(err, pgx.ErrNoRows) && err != nil {
@@ -778,10 +815,13 @@ func (p *PostgresStorage) GetTransactionReceipt(ctx context.Context, transaction
 
 	receipt.BlockNumber = big.NewInt(0).SetUint64(l2BlockNum)
 	receipt.BlockHash = common.HexToHash(l2BlockHash)
-	receipt.TransactionIndex = 0
 
 	receipt.Logs = logs
-	receipt.Bloom = types.CreateBloom(types.Receipts{&receipt})
+	if len(bloom) == types.BloomByteLength {
+		receipt.Bloom = types.BytesToBloom(bloom)
+	} else {
+		receipt.Bloom = types.CreateBloom(types.Receipts{&receipt})
+	}
 
 	return &receipt, nil
 }
@@ -857,35 +897,36 @@ func (p *PostgresStorage) getTransactionLogs(ctx context.Context, transactionHas
 	}
 	defer rows.Close()
 
+	return scanLogs(rows)
+}
+
+// scanLogs reads the logs returned by a query selecting all the columns of the log table
+func scanLogs(rows pgx.Rows) ([]*types.Log, error) {
 	logs := make([]*types.Log, 0, len(rows.RawValues()))
 
 	for rows.Next() {
 		var log types.Log
-		var txHash, logAddress, logData, topic0 string
-		var topic1, topic2, topic3 *string
+		var blockHash, txHash string
+		var logAddress, logData []byte
+		var topics [maxTopics][]byte
 
-		err := rows.Scan(&log.BlockNumber, &log.BlockHash, &txHash, &log.Index,
-			&logAddress, &logData, &topic0, &topic1, &topic2, &topic3)
+		err := rows.Scan(&log.BlockNumber, &blockHash, &txHash, &log.TxIndex, &log.Index,
+			&logAddress, &logData, &topics[0], &topics[1], &topics[2], &topics[3])
 		if err != nil {
 			return nil, err
 		}
 
+		log.BlockHash = common.HexToHash(blockHash)
 		log.TxHash = common.HexToHash(txHash)
-		log.Address = common.HexToAddress(logAddress)
-		log.TxIndex = uint(0)
-		log.Data = []byte(logData)
-
-		log.Topics = []common.Hash{common.HexToHash(topic0)}
-		if topic1 != nil {
-			log.Topics = append(log.Topics, common.HexToHash(*topic1))
-		}
-
-		if topic2 != nil {
-			log.Topics = append(log.Topics, common.HexToHash(*topic2))
-		}
+		log.Address = common.BytesToAddress(logAddress)
+		log.Data = logData
 
-		if topic3 != nil {
-			log.Topics = append(log.Topics, common.HexToHash(*topic3))
+		log.Topics = []common.Hash{}
+		for _, topic := range topics {
+			if topic == nil {
+				break
+			}
+			log.Topics = append(log.Topics, common.BytesToHash(topic))
 		}
 
 		logs = append(logs, &log)
@@ -949,19 +990,36 @@ func (p *PostgresStorage) AddL2Block(ctx context.Context, batchNumber uint64, l2
 			return err
 		}
 		decoded := string(binary)
-		_, err = e.Exec(ctx, addTransactionSQL, tx.Hash().String(), "", encoded, decoded, l2Block.Number().Uint64())
+
+		// the addresses are indexed to look up the txs of an account, txs
+		// whose sender can't be recovered are kept without it
+		var from, to *string
+		if sender, err := GetSender(*tx); err == nil {
+			fromAddress := sender.String()
+			from = &fromAddress
+		}
+		if tx.To() != nil {
+			toAddress := tx.To().String()
+			to = &toAddress
+		}
+
+		_, err = e.Exec(ctx, addTransactionSQL, tx.Hash().String(), from, to, encoded, decoded, l2Block.Number().Uint64())
 		if err != nil {
 			return err
 		}
 	}
 
-	for _, receipt := range receipts {
-		err := p.AddReceipt(ctx, receipt, dbTx)
+	for i, receipt := range receipts {
+		err := p.AddReceipt(ctx, receipt, EffectiveGasPrice(l2Block.Transactions()[i]), dbTx)
 		if err != nil {
 			return err
 		}
 
 		for _, log := range receipt.Logs {
+			log.TxHash = receipt.TxHash
+			log.TxIndex = receipt.TransactionIndex
+			log.BlockNumber = l2Block.NumberU64()
+			log.BlockHash = l2Block.Hash()
 			err := p.AddLog(ctx, log, dbTx)
 			if err != nil {
 				return err
@@ -976,7 +1034,7 @@ func (p *PostgresStorage) AddL2Block(ctx context.Context, batchNumber uint64, l2
 func (p *PostgresStorage) GetLastConsolidatedL2BlockNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
 	var lastConsolidatedBlockNumber uint64
 	q := p.getExecQuerier(dbTx)
-	err := q.QueryRow(ctx, getLastConsolidatedBlockNumberSQL, common.Hash{}).Scan(&lastConsolidatedBlockNumber)
+	err := q.QueryRow(ctx, getLastConsolidatedBlockNumberSQL).Scan(&lastConsolidatedBlockNumber)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return 0, ErrNotFound
@@ -987,6 +1045,22 @@ func (p *PostgresStorage) GetLastConsolidatedL2BlockNumber(ctx context.Context,
 	return lastConsolidatedBlockNumber, nil
 }
 
+// GetLastVirtualL2BlockNumber gets the last l2 block whose batch has been
+// sequenced on L1
+func (p *PostgresStorage) GetLastVirtualL2BlockNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
+	var lastVirtualBlockNumber uint64
+	q := p.getExecQuerier(dbTx)
+	err := q.QueryRow(ctx, getLastVirtualBlockNumberSQL).Scan(&lastVirtualBlockNumber)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrNotFound
+	} else if err != nil {
+		return 0, err
+	}
+
+	return lastVirtualBlockNumber, nil
+}
+
 // GetLastL2BlockNumber gets the last l2 block number
 func (p *PostgresStorage) GetLastL2BlockNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
 	var lastBlockNumber uint64
@@ -1045,15 +1119,7 @@ func (p *PostgresStorage) GetLastL2Block(ctx context.Context, dbTx pgx.Tx) (*typ
 		}
 	}
 
-	transactions, err := p.GetTxsByBlockNumber(ctx, header.Number.Uint64(), dbTx)
-	if errors.Is(err, pgx.ErrNoRows) {
-		transactions = []*types.Transaction{}
-	} else if err != nil {
-		return nil, err
-	}
-
-	block := types.NewBlock(header, transactions, uncles, nil, &trie.StackTrie{})
-	return block, nil
+	return p.newL2BlockWithTxs(ctx, header, uncles, dbTx)
 }
 
 // GetLastVerifiedBatchNumberSeenOnEthereum gets last verified batch number seen on ethereum
@@ -1129,6 +1195,188 @@ func (p *PostgresStorage) GetBlockNumVirtualBatchByBatchNum(ctx context.Context,
 	return blockNum, nil
 }
 
+// GetBatchNumberOfL2Block gets a batch number for l2 block by its number
+func (p *PostgresStorage) GetBatchNumberOfL2Block(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (uint64, error) {
+	const query = "SELECT batch_num FROM state.l2block WHERE block_num = $1"
+	var batchNumber uint64
+	e := p.getExecQuerier(dbTx)
+	err := e.QueryRow(ctx, query, blockNumber).Scan(&batchNumber)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrNotFound
+	} else if err != nil {
+		return 0, err
+	}
+	return batchNumber, nil
+}
+
+// GetLastL2BlockNumberUntilBatch gets the number of the last l2 block
+// contained in the batches up to the given batch number
+func (p *PostgresStorage) GetLastL2BlockNumberUntilBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (uint64, error) {
+	const query = "SELECT COALESCE(MAX(block_num), 0) FROM state.l2block WHERE batch_num <= $1"
+	var blockNumber uint64
+	e := p.getExecQuerier(dbTx)
+	err := e.QueryRow(ctx, query, batchNumber).Scan(&blockNumber)
+	if err != nil {
+		return 0, err
+	}
+	return blockNumber, nil
+}
+
+// GetVirtualBatch gets the virtual batch of the given batch number, containing
+// the L1 transaction that sequenced it
+func (p *PostgresStorage) GetVirtualBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (*VirtualBatch, error) {
+	const query = "SELECT block_num, batch_num, tx_hash, coinbase FROM state.virtual_batch WHERE batch_num = $1"
+	var (
+		virtualBatch VirtualBatch
+		txHash       string
+		coinbase     string
+	)
+	e := p.getExecQuerier(dbTx)
+	err := e.QueryRow(ctx, query, batchNumber).Scan(&virtualBatch.BlockNumber, &virtualBatch.BatchNumber, &txHash, &coinbase)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	virtualBatch.TxHash = common.HexToHash(txHash)
+	virtualBatch.Coinbase = common.HexToAddress(coinbase)
+	return &virtualBatch, nil
+}
+
+// GetTxsByAddress returns the txs in the given range of l2 blocks sent by the
+// given address, sent to it or creating it as a contract, ordered by their
+// position. Only the txs after the given position are returned, so the
+// position of the last tx of a page can be used to request the next one
+func (p *PostgresStorage) GetTxsByAddress(ctx context.Context, address common.Address, fromBlock, toBlock uint64, after *TxPosition, limit uint64, dbTx pgx.Tx) ([]TxInL2Block, error) {
+	const query = `
+		SELECT t.encoded, t.l2_block_num, l2b.block_hash, r.tx_index
+		  FROM state.transaction t
+		 INNER JOIN state.receipt r ON r.tx_hash = t.hash
+		 INNER JOIN state.l2block l2b ON l2b.block_num = t.l2_block_num
+		 WHERE (t.from_address = $1 OR t.to_address = $1 OR r.contract_address = $1)
+		   AND t.l2_block_num BETWEEN $2 AND $3
+		   AND (t.l2_block_num, r.tx_index) > ($4, $5)
+		 ORDER BY t.l2_block_num, r.tx_index
+		 LIMIT $6`
+
+	// without a position, every tx of the first block is after (fromBlock, -1)
+	afterBlock, afterIndex := int64(fromBlock), int64(-1)
+	if after != nil {
+		afterBlock, afterIndex = int64(after.BlockNumber), int64(after.Index)
+	}
+
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, query, address.String(), fromBlock, toBlock, afterBlock, afterIndex, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	txs := make([]TxInL2Block, 0, limit)
+	for rows.Next() {
+		var (
+			encoded   string
+			blockHash string
+			tx        TxInL2Block
+		)
+		if err := rows.Scan(&encoded, &tx.BlockNumber, &blockHash, &tx.Index); err != nil {
+			return nil, err
+		}
+		tx.Tx, err = decodeTx(encoded)
+		if err != nil {
+			return nil, err
+		}
+		tx.BlockHash = common.HexToHash(blockHash)
+		txs = append(txs, tx)
+	}
+
+	return txs, rows.Err()
+}
+
+// GetTxStatus returns the l2 block and the virtual and verified batches the
+// given tx belongs to
+func (p *PostgresStorage) GetTxStatus(ctx context.Context, transactionHash common.Hash, dbTx pgx.Tx) (*TxStatus, error) {
+	const query = `
+		SELECT l2b.block_num, l2b.block_hash, l2b.batch_num,
+		       vb.tx_hash, vb.coinbase, vb.block_num,
+		       vf.tx_hash, vf.aggregator, vf.block_num
+		  FROM state.transaction t
+		 INNER JOIN state.l2block l2b ON l2b.block_num = t.l2_block_num
+		  LEFT JOIN state.virtual_batch vb ON vb.batch_num = l2b.batch_num
+		  LEFT JOIN state.verified_batch vf ON vf.batch_num = l2b.batch_num
+		 WHERE t.hash = $1`
+	var (
+		status                             TxStatus
+		l2BlockHash                        string
+		virtualTxHash, virtualCoinbase     *string
+		virtualBlockNum                    *uint64
+		verifiedTxHash, verifiedAggregator *string
+		verifiedBlockNum                   *uint64
+	)
+	e := p.getExecQuerier(dbTx)
+	err := e.QueryRow(ctx, query, transactionHash.String()).Scan(
+		&status.L2BlockNumber, &l2BlockHash, &status.BatchNumber,
+		&virtualTxHash, &virtualCoinbase, &virtualBlockNum,
+		&verifiedTxHash, &verifiedAggregator, &verifiedBlockNum)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	status.L2BlockHash = common.HexToHash(l2BlockHash)
+	if virtualBlockNum != nil {
+		status.VirtualBatch = &VirtualBatch{
+			BatchNumber: status.BatchNumber,
+			BlockNumber: *virtualBlockNum,
+		}
+		if virtualTxHash != nil {
+			status.VirtualBatch.TxHash = common.HexToHash(*virtualTxHash)
+		}
+		if virtualCoinbase != nil {
+			status.VirtualBatch.Coinbase = common.HexToAddress(*virtualCoinbase)
+		}
+	}
+	if verifiedBlockNum != nil {
+		status.VerifiedBatch = &VerifiedBatch{
+			BatchNumber: status.BatchNumber,
+			BlockNumber: *verifiedBlockNum,
+		}
+		if verifiedTxHash != nil {
+			status.VerifiedBatch.TxHash = common.HexToHash(*verifiedTxHash)
+		}
+		if verifiedAggregator != nil {
+			status.VerifiedBatch.Aggregator = common.HexToAddress(*verifiedAggregator)
+		}
+	}
+	return &status, nil
+}
+
+// IsL2BlockVirtualized checks if the batch of the given l2 block has been
+// sequenced on L1
+func (p *PostgresStorage) IsL2BlockVirtualized(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (bool, error) {
+	const query = "SELECT EXISTS (SELECT 1 FROM state.l2block l2b INNER JOIN state.virtual_batch vb ON vb.batch_num = l2b.batch_num WHERE l2b.block_num = $1)"
+	var isVirtualized bool
+	e := p.getExecQuerier(dbTx)
+	err := e.QueryRow(ctx, query, blockNumber).Scan(&isVirtualized)
+	if err != nil {
+		return false, err
+	}
+	return isVirtualized, nil
+}
+
+// IsL2BlockConsolidated checks if the batch of the given l2 block has been
+// verified on L1
+func (p *PostgresStorage) IsL2BlockConsolidated(ctx context.Context, blockNumber uint64, dbTx pgx.Tx) (bool, error) {
+	const query = "SELECT EXISTS (SELECT 1 FROM state.l2block l2b INNER JOIN state.verified_batch vb ON vb.batch_num = l2b.batch_num WHERE l2b.block_num = $1)"
+	var isConsolidated bool
+	e := p.getExecQuerier(dbTx)
+	err := e.QueryRow(ctx, query, blockNumber).Scan(&isConsolidated)
+	if err != nil {
+		return false, err
+	}
+	return isConsolidated, nil
+}
+
 // GetL2BlockByHash gets a l2 block from its hash
 func (p *PostgresStorage) GetL2BlockByHash(ctx context.Context, hash common.Hash, dbTx pgx.Tx) (*types.Block, error) {
 	header := &types.Header{}
@@ -1144,6 +1392,14 @@ func (p *PostgresStorage) GetL2BlockByHash(ctx context.Context, hash common.Hash
 		return nil, err
 	}
 
+	return p.newL2BlockWithTxs(ctx, header, uncles, dbTx)
+}
+
+// newL2BlockWithTxs builds the l2 block with the given stored header and
+// uncles and its txs. The header is kept as it was stored, so the transactions
+// and receipts roots and the logs bloom computed when the block was created
+// are preserved
+func (p *PostgresStorage) newL2BlockWithTxs(ctx context.Context, header *types.Header, uncles []*types.Header, dbTx pgx.Tx) (*types.Block, error) {
 	transactions, err := p.GetTxsByBlockNumber(ctx, header.Number.Uint64(), dbTx)
 	if errors.Is(err, pgx.ErrNoRows) {
 		transactions = []*types.Transaction{}
@@ -1151,8 +1407,7 @@ func (p *PostgresStorage) GetL2BlockByHash(ctx context.Context, hash common.Hash
 		return nil, err
 	}
 
-	block := types.NewBlock(header, transactions, uncles, nil, &trie.StackTrie{})
-	return block, nil
+	return types.NewBlockWithHeader(header).WithBody(transactions, uncles), nil
 }
 
 // GetTxsByBlockNumber returns all the txs in a given block
@@ -1185,6 +1440,37 @@ func (p *PostgresStorage) GetTxsByBlockNumber(ctx context.Context, blockNumber u
 	return txs, nil
 }
 
+// GetTxsByBatchNumber returns the transactions of the given batch, in the
+// order they were processed
+func (p *PostgresStorage) GetTxsByBatchNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) ([]*types.Transaction, error) {
+	const query = "SELECT t.encoded FROM state.transaction t INNER JOIN state.l2block b ON t.l2_block_num = b.block_num WHERE b.batch_num = $1 ORDER BY b.block_num ASC"
+	q := p.getExecQuerier(dbTx)
+	rows, err := q.Query(ctx, query, batchNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	txs := make([]*types.Transaction, 0, len(rows.RawValues()))
+	var encoded string
+	for rows.Next() {
+		if err = rows.Scan(&encoded); err != nil {
+			return nil, err
+		}
+
+		tx, err := decodeTx(encoded)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
 // GetL2BlockHeaderByHash gets the block header by block number
 func (p *PostgresStorage) GetL2BlockHeaderByHash(ctx context.Context, hash common.Hash, dbTx pgx.Tx) (*types.Header, error) {
 	header := &types.Header{}
@@ -1213,6 +1499,55 @@ func (p *PostgresStorage) GetL2BlockHeaderByNumber(ctx context.Context, blockNum
 	return header, nil
 }
 
+// GetL2BlocksByNumberRange gets the l2 blocks from fromBlock to toBlock with
+// their txs at once, the uncles are not loaded
+func (p *PostgresStorage) GetL2BlocksByNumberRange(ctx context.Context, fromBlock, toBlock uint64, dbTx pgx.Tx) ([]*types.Block, error) {
+	q := p.getExecQuerier(dbTx)
+	rows, err := q.Query(ctx, getL2BlocksByNumberRangeSQL, fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		headers         []*types.Header
+		txs             [][]*types.Transaction
+		lastBlockNumber uint64
+	)
+	for rows.Next() {
+		var (
+			blockNumber uint64
+			header      = &types.Header{}
+			encoded     *string
+		)
+		if err := rows.Scan(&blockNumber, &header, &encoded); err != nil {
+			return nil, err
+		}
+		if len(headers) == 0 || lastBlockNumber != blockNumber {
+			headers = append(headers, header)
+			txs = append(txs, []*types.Transaction{})
+			lastBlockNumber = blockNumber
+		}
+		// the empty l2 blocks have no tx
+		if encoded != nil {
+			tx, err := decodeTx(*encoded)
+			if err != nil {
+				return nil, err
+			}
+			txs[len(txs)-1] = append(txs[len(txs)-1], tx)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	blocks := make([]*types.Block, 0, len(headers))
+	for i, header := range headers {
+		blocks = append(blocks, types.NewBlockWithHeader(header).WithBody(txs[i], nil))
+	}
+	return blocks, nil
+}
+
 // GetL2BlockHashesSince gets the block hashes added since the provided date
 func (p *PostgresStorage) GetL2BlockHashesSince(ctx context.Context, since time.Time, dbTx pgx.Tx) ([]common.Hash, error) {
 	q := p.getExecQuerier(dbTx)
@@ -1247,25 +1582,31 @@ func (p *PostgresStorage) GetLogs(ctx context.Context, fromBlock uint64, toBlock
 	if blockHash != nil {
 		rows, err = q.Query(ctx, getLogsByBlockHashSQL, blockHash.String())
 	} else {
+		// Only the conditions that are present in the filter are added to the
+		// query, this way the planner is able to use the address and topic indexes
+		query := getLogsByFilterSQL
 		args := []interface{}{fromBlock, toBlock}
 
 		if len(addresses) > 0 {
 			args = append(args, p.addressesToBytes(addresses))
-		} else {
-			args = append(args, nil)
+			query += fmt.Sprintf(" AND l.address = ANY($%d)", len(args))
 		}
 
 		for i := 0; i < maxTopics; i++ {
 			if len(topics) > i && len(topics[i]) > 0 {
 				args = append(args, p.hashesToBytes(topics[i]))
-			} else {
-				args = append(args, nil)
+				query += fmt.Sprintf(" AND l.topic%d = ANY($%d)", i, len(args))
 			}
 		}
 
-		args = append(args, since)
+		if since != nil {
+			args = append(args, *since)
+			query += fmt.Sprintf(" AND l.block_num IN (SELECT b.block_num FROM state.l2block b WHERE b.received_at >= $%d)", len(args))
+		}
+
+		query += " ORDER BY l.block_num ASC, l.log_index ASC"
 
-		rows, err = q.Query(ctx, getLogsByFilterSQL, args...)
+		rows, err = q.Query(ctx, query, args...)
 	}
 
 	if err != nil {
@@ -1273,41 +1614,7 @@ func (p *PostgresStorage) GetLogs(ctx context.Context, fromBlock uint64, toBlock
 	}
 	defer rows.Close()
 
-	logs := make([]*types.Log, 0, len(rows.RawValues()))
-
-	for rows.Next() {
-		var log types.Log
-		var txHash, logAddress, logData, topic0 string
-		var topic1, topic2, topic3 *string
-
-		err := rows.Scan(&log.BlockNumber, &log.BlockHash, &txHash, &log.Index,
-			&logAddress, &logData, &topic0, &topic1, &topic2, &topic3)
-		if err != nil {
-			return nil, err
-		}
-
-		log.TxHash = common.HexToHash(txHash)
-		log.Address = common.HexToAddress(logAddress)
-		log.TxIndex = uint(0)
-		log.Data = []byte(logData)
-
-		log.Topics = []common.Hash{common.HexToHash(topic0)}
-		if topic1 != nil {
-			log.Topics = append(log.Topics, common.HexToHash(*topic1))
-		}
-
-		if topic2 != nil {
-			log.Topics = append(log.Topics, common.HexToHash(*topic2))
-		}
-
-		if topic3 != nil {
-			log.Topics = append(log.Topics, common.HexToHash(*topic3))
-		}
-
-		logs = append(logs, &log)
-	}
-
-	return logs, nil
+	return scanLogs(rows)
 }
 
 // GetSyncingInfo returns information regarding the syncing status of the node
@@ -1357,23 +1664,349 @@ func (p *PostgresStorage) hashesToBytes(hashes []common.Hash) [][]byte {
 }
 
 // AddReceipt adds a new receipt to the State Store
-func (p *PostgresStorage) AddReceipt(ctx context.Context, receipt *types.Receipt, dbTx pgx.Tx) error {
+func (p *PostgresStorage) AddReceipt(ctx context.Context, receipt *types.Receipt, effectiveGasPrice *big.Int, dbTx pgx.Tx) error {
+	// the contract address is only stored for the contract creations, so the
+	// txs by address don't match the zero address of the rest
+	var contractAddress *string
+	if receipt.ContractAddress != (common.Address{}) {
+		address := receipt.ContractAddress.String()
+		contractAddress = &address
+	}
+
 	e := p.getExecQuerier(dbTx)
-	// INSERT INTO state.receipt (tx_hash, type, post_state, status, cumulative_gas_used, gas_used, block_num, tx_index, contract_address) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	_, err := e.Exec(ctx, addReceiptSQL, receipt.TxHash.String(), receipt.Type, receipt.PostState, receipt.Status, receipt.CumulativeGasUsed, receipt.GasUsed, receipt.BlockNumber.Uint64(), receipt.TransactionIndex, receipt.ContractAddress.String())
+	_, err := e.Exec(ctx, addReceiptSQL, receipt.TxHash.String(), receipt.Type, receipt.PostState, receipt.Status, receipt.CumulativeGasUsed, receipt.GasUsed,
+		receipt.BlockNumber.Uint64(), receipt.TransactionIndex, contractAddress, receipt.Bloom.Bytes(), effectiveGasPrice.String())
 	return err
 }
 
 // AddLog adds a new log to the State Store
 func (p *PostgresStorage) AddLog(ctx context.Context, l *types.Log, dbTx pgx.Tx) error {
-	var topicsAsBytes [maxTopics]*[]byte
-	for i := 0; i < len(l.Topics); i++ {
-		topicBytes := l.Topics[i].Bytes()
-		topicsAsBytes[i] = &topicBytes
+	var topicsAsBytes [maxTopics][]byte
+	for i := 0; i < len(l.Topics) && i < maxTopics; i++ {
+		topicsAsBytes[i] = l.Topics[i].Bytes()
+	}
+
+	e := p.getExecQuerier(dbTx)
+	_, err := e.Exec(ctx, addLogSQL, l.TxHash.String(), l.Index, l.BlockNumber, l.BlockHash.String(), l.TxIndex,
+		l.Address.Bytes(), l.Data, topicsAsBytes[0], topicsAsBytes[1], topicsAsBytes[2], topicsAsBytes[3])
+	return err
+}
+
+// GetL2BlockNumbersAndTxHashesFromBatch returns the numbers of the L2 blocks,
+// the empty ones included, and the hashes of the transactions contained in the
+// batches with number equal or greater than the given one
+func (p *PostgresStorage) GetL2BlockNumbersAndTxHashesFromBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) ([]uint64, []common.Hash, error) {
+	q := p.getExecQuerier(dbTx)
+	rows, err := q.Query(ctx, getL2BlocksTxHashesFromBatchNumSQL, batchNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	blockNumbers := make([]uint64, 0, len(rows.RawValues()))
+	txHashes := make([]common.Hash, 0, len(rows.RawValues()))
+	for rows.Next() {
+		var (
+			blockNumber uint64
+			txHash      *string
+		)
+		if err := rows.Scan(&blockNumber, &txHash); err != nil {
+			return nil, nil, err
+		}
+		if len(blockNumbers) == 0 || blockNumbers[len(blockNumbers)-1] != blockNumber {
+			blockNumbers = append(blockNumbers, blockNumber)
+		}
+		// the empty l2 blocks have no tx
+		if txHash != nil {
+			txHashes = append(txHashes, common.HexToHash(*txHash))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return blockNumbers, txHashes, nil
+}
+
+// AddTrustedReorg stores the report of a divergence between the trusted and the virtual state
+func (p *PostgresStorage) AddTrustedReorg(ctx context.Context, reorg *TrustedReorg, dbTx pgx.Tx) error {
+	txHashes := make([]string, 0, len(reorg.TxHashes))
+	for _, txHash := range reorg.TxHashes {
+		txHashes = append(txHashes, txHash.String())
+	}
+	e := p.getExecQuerier(dbTx)
+	return e.QueryRow(ctx, addTrustedReorgSQL, reorg.BatchNumber, reorg.BlockNumber, reorg.DivergentFields,
+		reorg.OldStateRoot.String(), reorg.NewStateRoot.String(), reorg.L2BlockNumbers, txHashes, reorg.ReportedAt).Scan(&reorg.ID)
+}
+
+// GetTrustedReorgs returns up to limit divergence reports found for batches
+// with number equal or greater than the given one
+func (p *PostgresStorage) GetTrustedReorgs(ctx context.Context, fromBatchNumber uint64, limit uint64, dbTx pgx.Tx) ([]TrustedReorg, error) {
+	q := p.getExecQuerier(dbTx)
+	rows, err := q.Query(ctx, getTrustedReorgsSQL, fromBatchNumber, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reorgs := make([]TrustedReorg, 0, len(rows.RawValues()))
+	for rows.Next() {
+		var (
+			reorg                      TrustedReorg
+			oldStateRoot, newStateRoot string
+			txHashes                   []string
+		)
+		if err := rows.Scan(&reorg.ID, &reorg.BatchNumber, &reorg.BlockNumber, &reorg.DivergentFields,
+			&oldStateRoot, &newStateRoot, &reorg.L2BlockNumbers, &txHashes, &reorg.ReportedAt); err != nil {
+			return nil, err
+		}
+		reorg.OldStateRoot = common.HexToHash(oldStateRoot)
+		reorg.NewStateRoot = common.HexToHash(newStateRoot)
+		reorg.TxHashes = make([]common.Hash, 0, len(txHashes))
+		for _, txHash := range txHashes {
+			reorg.TxHashes = append(reorg.TxHashes, common.HexToHash(txHash))
+		}
+		reorgs = append(reorgs, reorg)
+	}
+
+	return reorgs, nil
+}
+
+// AddTokenTransfer stores a token event, it is removed along with its l2 block
+func (p *PostgresStorage) AddTokenTransfer(ctx context.Context, transfer *TokenTransfer, dbTx pgx.Tx) error {
+	const query = `
+		INSERT INTO state.token_transfer (tx_hash, log_index, block_num, token_address, token_type, event_type, from_address, to_address, value)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	e := p.getExecQuerier(dbTx)
+	_, err := e.Exec(ctx, query, transfer.TxHash.String(), transfer.LogIndex, transfer.BlockNumber, transfer.Token.String(),
+		transfer.TokenType, transfer.EventType, transfer.From.String(), transfer.To.String(), transfer.Value.String())
+	return err
+}
+
+// getUnindexedTokenLogs returns the Transfer and Approval logs of the
+// successful txs of the given range of l2 blocks that are not indexed yet
+func (p *PostgresStorage) getUnindexedTokenLogs(ctx context.Context, fromBlock, toBlock uint64, dbTx pgx.Tx) ([]*types.Log, error) {
+	const query = `
+		SELECT l.block_num, l.block_hash, l.tx_hash, l.tx_index, l.log_index, l.address, l.data, l.topic0, l.topic1, l.topic2, l.topic3
+		  FROM state.log l
+		 INNER JOIN state.receipt r ON r.tx_hash = l.tx_hash
+		  LEFT JOIN state.token_transfer tt ON tt.tx_hash = l.tx_hash AND tt.log_index = l.log_index
+		 WHERE l.block_num BETWEEN $1 AND $2 AND (l.topic0 = $3 OR l.topic0 = $4) AND r.status = $5 AND tt.tx_hash IS NULL
+		 ORDER BY l.block_num ASC, l.log_index ASC`
+	q := p.getExecQuerier(dbTx)
+	rows, err := q.Query(ctx, query, fromBlock, toBlock, transferTopic.Bytes(), approvalTopic.Bytes(), types.ReceiptStatusSuccessful)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs, err := scanLogs(rows)
+	if err != nil {
+		return nil, err
+	}
+	return logs, rows.Err()
+}
+
+// GetTokenTransfers returns the token events in the given range of l2 blocks
+// sent from or to the given address, ordered by their position. Only the
+// events after the given position are returned, so the position of the last
+// event of a page can be used to request the next one
+func (p *PostgresStorage) GetTokenTransfers(ctx context.Context, address common.Address, fromBlock, toBlock uint64, after *LogPosition, limit uint64, dbTx pgx.Tx) ([]TokenTransfer, error) {
+	const query = `
+		SELECT tx_hash, log_index, block_num, token_address, token_type, event_type, from_address, to_address, value::TEXT
+		  FROM state.token_transfer
+		 WHERE (from_address = $1 OR to_address = $1)
+		   AND block_num BETWEEN $2 AND $3
+		   AND (block_num, log_index) > ($4, $5)
+		 ORDER BY block_num, log_index
+		 LIMIT $6`
+
+	afterBlock, afterIndex := int64(fromBlock), int64(-1)
+	if after != nil {
+		afterBlock, afterIndex = int64(after.BlockNumber), int64(after.LogIndex)
+	}
+
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, query, address.String(), fromBlock, toBlock, afterBlock, afterIndex, limit)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
+
+	transfers := make([]TokenTransfer, 0, limit)
+	for rows.Next() {
+		var (
+			transfer                       TokenTransfer
+			txHash, token, from, to, value string
+		)
+		if err := rows.Scan(&txHash, &transfer.LogIndex, &transfer.BlockNumber, &token, &transfer.TokenType,
+			&transfer.EventType, &from, &to, &value); err != nil {
+			return nil, err
+		}
+		transfer.TxHash = common.HexToHash(txHash)
+		transfer.Token = common.HexToAddress(token)
+		transfer.From = common.HexToAddress(from)
+		transfer.To = common.HexToAddress(to)
+		var ok bool
+		if transfer.Value, ok = new(big.Int).SetString(value, encoding.Base10); !ok {
+			return nil, fmt.Errorf("invalid token transfer value %s", value)
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, rows.Err()
+}
+
+// GetTokenBalances computes the ERC-20 balances of the given address from the
+// indexed Transfer events, along with the ERC-721 tokens it owns
+func (p *PostgresStorage) GetTokenBalances(ctx context.Context, address common.Address, dbTx pgx.Tx) ([]TokenBalance, []TokenOwnership, error) {
+	const balancesQuery = `
+		SELECT token_address,
+		       (SUM(CASE WHEN to_address = $1 THEN value ELSE 0 END) - SUM(CASE WHEN from_address = $1 THEN value ELSE 0 END))::TEXT
+		  FROM state.token_transfer
+		 WHERE (from_address = $1 OR to_address = $1) AND token_type = $2 AND event_type = $3
+		 GROUP BY token_address
+		 ORDER BY token_address`
+	// the owner of a token is the receiver of its last transfer
+	const ownershipsQuery = `
+		SELECT token_address, value::TEXT FROM (
+			SELECT DISTINCT ON (t.token_address, t.value) t.token_address, t.value, t.to_address
+			  FROM state.token_transfer t
+			 WHERE t.token_type = $2 AND t.event_type = $3
+			   AND (t.token_address, t.value) IN (SELECT token_address, value FROM state.token_transfer WHERE to_address = $1 AND token_type = $2 AND event_type = $3)
+			 ORDER BY t.token_address, t.value, t.block_num DESC, t.log_index DESC
+		) last_transfers
+		 WHERE to_address = $1
+		 ORDER BY token_address, value`
 
 	e := p.getExecQuerier(dbTx)
-	_, err := e.Exec(ctx, addLogSQL, l.TxHash.String(), l.Index, l.TxIndex,
-		l.Address.String(), l.Data, topicsAsBytes[0], topicsAsBytes[1], topicsAsBytes[2], topicsAsBytes[3])
+
+	rows, err := e.Query(ctx, balancesQuery, address.String(), TokenTypeERC20, TokenEventTransfer)
+	if err != nil {
+		return nil, nil, err
+	}
+	balances := []TokenBalance{}
+	for rows.Next() {
+		var token, balance string
+		if err := rows.Scan(&token, &balance); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		b, ok := new(big.Int).SetString(balance, encoding.Base10)
+		if !ok {
+			rows.Close()
+			return nil, nil, fmt.Errorf("invalid token balance %s", balance)
+		}
+		balances = append(balances, TokenBalance{Token: common.HexToAddress(token), Balance: b})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err = e.Query(ctx, ownershipsQuery, address.String(), TokenTypeERC721, TokenEventTransfer)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	ownerships := []TokenOwnership{}
+	for rows.Next() {
+		var token, tokenID string
+		if err := rows.Scan(&token, &tokenID); err != nil {
+			return nil, nil, err
+		}
+		id, ok := new(big.Int).SetString(tokenID, encoding.Base10)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid token id %s", tokenID)
+		}
+		ownerships = append(ownerships, TokenOwnership{Token: common.HexToAddress(token), TokenID: id})
+	}
+
+	return balances, ownerships, rows.Err()
+}
+
+// GetGasPriceAvg returns the stored running average of the gas price. The row
+// is locked until the given db tx ends, so only one instance updates it at once
+func (p *PostgresStorage) GetGasPriceAvg(ctx context.Context, dbTx pgx.Tx) (*GasPriceAvg, error) {
+	const query = "SELECT last_block_num, avg::TEXT, count FROM state.gas_price_avg FOR UPDATE"
+	var (
+		gasPriceAvg GasPriceAvg
+		avg         string
+	)
+	e := p.getExecQuerier(dbTx)
+	err := e.QueryRow(ctx, query).Scan(&gasPriceAvg.LastL2BlockNumber, &avg, &gasPriceAvg.Count)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	var ok bool
+	if gasPriceAvg.Avg, ok = new(big.Int).SetString(avg, encoding.Base10); !ok {
+		return nil, fmt.Errorf("invalid gas price average %s", avg)
+	}
+	return &gasPriceAvg, nil
+}
+
+// SetGasPriceAvg stores the running average of the gas price
+func (p *PostgresStorage) SetGasPriceAvg(ctx context.Context, gasPriceAvg *GasPriceAvg, dbTx pgx.Tx) error {
+	const query = `
+		INSERT INTO state.gas_price_avg (last_block_num, avg, count) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET last_block_num = EXCLUDED.last_block_num, avg = EXCLUDED.avg, count = EXCLUDED.count`
+	e := p.getExecQuerier(dbTx)
+	_, err := e.Exec(ctx, query, gasPriceAvg.LastL2BlockNumber, gasPriceAvg.Avg.String(), gasPriceAvg.Count)
 	return err
 }
+
+// GetEffectiveGasPrices returns the gas price paid by the txs of the given
+// range of l2 blocks, in the order they were processed
+func (p *PostgresStorage) GetEffectiveGasPrices(ctx context.Context, fromBlock, toBlock uint64, dbTx pgx.Tx) ([]*big.Int, error) {
+	const query = `
+		SELECT effective_gas_price::TEXT
+		  FROM state.receipt
+		 WHERE block_num BETWEEN $1 AND $2
+		 ORDER BY block_num, tx_index`
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, query, fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	gasPrices := []*big.Int{}
+	for rows.Next() {
+		var gasPrice string
+		if err := rows.Scan(&gasPrice); err != nil {
+			return nil, err
+		}
+		price, ok := new(big.Int).SetString(gasPrice, encoding.Base10)
+		if !ok {
+			return nil, fmt.Errorf("invalid effective gas price %s", gasPrice)
+		}
+		gasPrices = append(gasPrices, price)
+	}
+
+	return gasPrices, rows.Err()
+}
+
+// GetGasPricePercentile returns the given percentile, between 0 and 1, of the
+// gas price paid by the txs of the l2 blocks received since the given time
+func (p *PostgresStorage) GetGasPricePercentile(ctx context.Context, percentile float64, since time.Time, dbTx pgx.Tx) (*big.Int, error) {
+	const query = `
+		SELECT (percentile_disc($1) WITHIN GROUP (ORDER BY r.effective_gas_price))::TEXT
+		  FROM state.receipt r
+		 INNER JOIN state.l2block b ON b.block_num = r.block_num
+		 WHERE b.received_at >= $2`
+	var gasPrice *string
+	e := p.getExecQuerier(dbTx)
+	if err := e.QueryRow(ctx, query, percentile, since).Scan(&gasPrice); err != nil {
+		return nil, err
+	}
+	if gasPrice == nil {
+		return nil, ErrNotFound
+	}
+	price, ok := new(big.Int).SetString(*gasPrice, encoding.Base10)
+	if !ok {
+		return nil, fmt.Errorf("invalid effective gas price %s", *gasPrice)
+	}
+	return price, nil
+}
@@ -0,0 +1,91 @@
+package state
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/0xPolygonHermez/zkevm-node/merkletree"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+)
+
+// AccountProof contains the values of an account stored in the merkletree
+// for a given state root, along with the proof of each of them
+type AccountProof struct {
+	Address       common.Address
+	StateRoot     common.Hash
+	Balance       *big.Int
+	BalanceProof  *merkletree.Proof
+	Nonce         *big.Int
+	NonceProof    *merkletree.Proof
+	CodeHash      common.Hash
+	CodeHashProof *merkletree.Proof
+	StorageProof  []StorageProof
+}
+
+// StorageProof contains the value of a storage position of an account along
+// with its proof
+type StorageProof struct {
+	Key   common.Hash
+	Value *big.Int
+	Proof *merkletree.Proof
+}
+
+// GetProof returns the account values and the given storage positions of an
+// address at the given l2 block, along with their merkletree proofs
+func (s *State) GetProof(ctx context.Context, address common.Address, storageKeys []common.Hash, blockNumber uint64, dbTx pgx.Tx) (*AccountProof, error) {
+	l2Block, err := s.GetL2BlockByNumber(ctx, blockNumber, dbTx)
+	if err != nil {
+		return nil, err
+	}
+	root := l2Block.Root()
+
+	getProof := func(keyFunc func() ([]byte, error)) (*merkletree.Proof, error) {
+		key, err := keyFunc()
+		if err != nil {
+			return nil, err
+		}
+		return s.tree.GetProof(ctx, key, root.Bytes())
+	}
+
+	balanceProof, err := getProof(func() ([]byte, error) { return merkletree.KeyEthAddrBalance(address) })
+	if err != nil {
+		return nil, err
+	}
+
+	nonceProof, err := getProof(func() ([]byte, error) { return merkletree.KeyEthAddrNonce(address) })
+	if err != nil {
+		return nil, err
+	}
+
+	codeHashProof, err := getProof(func() ([]byte, error) { return merkletree.KeyContractCode(address) })
+	if err != nil {
+		return nil, err
+	}
+
+	storageProof := make([]StorageProof, 0, len(storageKeys))
+	for _, storageKey := range storageKeys {
+		storageKey := storageKey
+		proof, err := getProof(func() ([]byte, error) { return merkletree.KeyContractStorage(address, storageKey.Bytes()) })
+		if err != nil {
+			return nil, err
+		}
+		storageProof = append(storageProof, StorageProof{
+			Key:   storageKey,
+			Value: merkletree.FeaToScalar(proof.Value),
+			Proof: proof,
+		})
+	}
+
+	return &AccountProof{
+		Address:       address,
+		StateRoot:     root,
+		Balance:       merkletree.FeaToScalar(balanceProof.Value),
+		BalanceProof:  balanceProof,
+		Nonce:         merkletree.FeaToScalar(nonceProof.Value),
+		NonceProof:    nonceProof,
+		CodeHash:      common.BigToHash(merkletree.FeaToScalar(codeHashProof.Value)),
+		CodeHashProof: codeHashProof,
+		StorageProof:  storageProof,
+	}, nil
+}
@@ -115,19 +115,25 @@ func (s *State) GetStorageAt(ctx context.Context, address common.Address, positi
 	return s.tree.GetStorageAt(ctx, address, position, l2Block.Root().Bytes())
 }
 
-// EstimateGas for a transaction
-func (s *State) EstimateGas(transaction *types.Transaction, senderAddress common.Address) (uint64, error) {
+// EstimateGas for a transaction, executing it against the state of the given
+// l2 block, or against the latest l2 block when no block number is provided.
+// The state override is applied on top of the block state before executing.
+// gasCap limits the highest gas limit used during the estimation, 0 means no cap
+func (s *State) EstimateGas(transaction *types.Transaction, senderAddress common.Address, l2BlockNumber *uint64, stateOverride StateOverride, gasCap uint64, dbTx pgx.Tx) (uint64, error) {
 	var lowEnd uint64
 	var highEnd uint64
 	ctx := context.Background()
 
-	lastBatch, err := s.GetLastBatch(ctx, nil)
+	l2Block, batch, err := s.getL2BlockAndBatch(ctx, l2BlockNumber, dbTx)
 	if err != nil {
-		log.Errorf("failed to get last batch from the state, err: %v", err)
 		return 0, err
 	}
 
-	stateRoot := lastBatch.StateRoot
+	stateRoot, err := s.applyStateOverride(ctx, l2Block.Root(), stateOverride)
+	if err != nil {
+		log.Errorf("failed to apply the state override, err: %v", err)
+		return 0, err
+	}
 
 	if s.isContractCreation(transaction) {
 		lowEnd = TxSmartContractCreationGas
@@ -141,6 +147,11 @@ func (s *State) EstimateGas(transaction *types.Transaction, senderAddress common
 		highEnd = s.cfg.MaxCumulativeGasUsed
 	}
 
+	if gasCap != 0 && highEnd > gasCap {
+		log.Debugf("Gas estimation high-end capped by gas cap [%d]", gasCap)
+		highEnd = gasCap
+	}
+
 	var availableBalance *big.Int
 
 	if senderAddress != ZeroAddress {
@@ -178,7 +189,7 @@ func (s *State) EstimateGas(transaction *types.Transaction, senderAddress common
 
 	// Checks if executor level valid gas errors occurred
 	isGasApplyError := func(err error) bool {
-		return errors.As(err, &ErrNotEnoughIntrinsicGas)
+		return errors.Is(err, ErrNotEnoughIntrinsicGas)
 	}
 
 	// Checks if EVM level valid gas errors occurred
@@ -195,59 +206,74 @@ func (s *State) EstimateGas(transaction *types.Transaction, senderAddress common
 	// Run the transaction with the specified gas value.
 	// Returns a status indicating if the transaction failed and the accompanying error
 	testTransaction := func(gas uint64, shouldOmitErr bool) (bool, error) {
-		batchL2Data, err := EncodeTransactions([]types.Transaction{*transaction})
-		if err != nil {
-			return false, err
-		}
-
-		// Create a batch to be sent to the executor
-		processBatchRequest := &pb.ProcessBatchRequest{
-			BatchNum:             lastBatch.BatchNumber + 1,
-			Coinbase:             senderAddress.String(),
-			BatchL2Data:          batchL2Data,
-			OldStateRoot:         stateRoot.Bytes(),
-			UpdateMerkleTree:     0,
-			GenerateExecuteTrace: 0,
-			GenerateCallTrace:    0,
-		}
-
-		processBatchResponse, err := s.executorClient.ProcessBatch(ctx, processBatchRequest)
+		tx := types.NewTx(&types.LegacyTx{
+			Nonce:    transaction.Nonce(),
+			GasPrice: transaction.GasPrice(),
+			Gas:      gas,
+			To:       transaction.To(),
+			Value:    transaction.Value(),
+			Data:     transaction.Data(),
+		})
+
+		response, err := s.executeUnsignedTransaction(ctx, tx, senderAddress, batch, stateRoot)
 		if err != nil {
 			return false, err
 		}
 
 		// Check if an out of gas error happened during EVM execution
-		if processBatchResponse.Responses[0].Error != "" {
-			error := fmt.Errorf(processBatchResponse.Responses[0].Error)
+		if response.Error != "" {
+			err := executorErr(response.Error)
 
-			if (isGasEVMError(error) || isGasApplyError(error)) && shouldOmitErr {
+			if (isGasEVMError(err) || isGasApplyError(err)) && shouldOmitErr {
 				// Specifying the transaction failed, but not providing an error
 				// is an indication that a valid error occurred due to low gas,
 				// which will increase the lower bound for the search
 				return true, nil
 			}
 
-			if isEVMRevertError(error) {
+			if isEVMRevertError(err) {
 				// The EVM reverted during execution, attempt to extract the
 				// error message and return it
-				return true, constructErrorFromRevert(error, processBatchResponse.Responses[0].ReturnValue)
+				return true, constructErrorFromRevert(err, response.ReturnValue)
 			}
 
-			return true, error
+			return true, err
 		}
 
 		return false, nil
 	}
 
-	// Start the binary search for the lowest possible gas price
+	// Check if the transaction can be executed with the highest gas limit
+	// allowed, otherwise there is no point in searching for a lower one
+	failed, err := testTransaction(highEnd, false)
+	if failed {
+		if isEVMRevertError(err) {
+			// The transaction reverts regardless of the gas limit, so the
+			// revert reason is returned as is
+			return 0, err
+		}
+		if isGasEVMError(err) || isGasApplyError(err) {
+			return 0, fmt.Errorf("%w (%d)", ErrGasRequiredExceedsAllowance, highEnd)
+		}
+		return 0, fmt.Errorf(
+			"unable to apply transaction even for the highest gas limit %d: %w",
+			highEnd,
+			err,
+		)
+	} else if err != nil {
+		return 0, err
+	}
+
+	// Start the binary search for the lowest possible gas limit
 	for lowEnd < highEnd {
 		mid := (lowEnd + highEnd) / uint64(two)
 
 		failed, testErr := testTransaction(mid, true)
 		if testErr != nil &&
 			!isEVMRevertError(testErr) {
-			// Reverts are ignored in the binary search, but are checked later on
-			// during the execution for the optimal gas limit found
+			// Reverts are ignored in the binary search, as the transaction
+			// is known to succeed with the highest gas limit, so a revert
+			// with less gas means the gas limit is not enough
 			return 0, testErr
 		}
 
@@ -260,19 +286,111 @@ func (s *State) EstimateGas(transaction *types.Transaction, senderAddress common
 		}
 	}
 
-	// Check if the highEnd is a good value to make the transaction pass
-	failed, err := testTransaction(highEnd, false)
-	if failed {
-		// The transaction shouldn't fail, for whatever reason, at highEnd
-		return 0, fmt.Errorf(
-			"unable to apply transaction even for the highest gas limit %d: %w",
-			highEnd,
-			err,
-		)
-	}
 	return highEnd, nil
 }
 
+// getL2BlockAndBatch returns the l2 block with the given number, or the last
+// l2 block when no number is provided, along with the batch containing it
+func (s *State) getL2BlockAndBatch(ctx context.Context, l2BlockNumber *uint64, dbTx pgx.Tx) (*types.Block, *Batch, error) {
+	var l2Block *types.Block
+	var err error
+	if l2BlockNumber == nil {
+		l2Block, err = s.GetLastL2Block(ctx, dbTx)
+	} else {
+		l2Block, err = s.GetL2BlockByNumber(ctx, *l2BlockNumber, dbTx)
+	}
+	if err != nil {
+		log.Errorf("failed to get l2 block from the state, err: %v", err)
+		return nil, nil, err
+	}
+
+	batchNumber, err := s.GetBatchNumberOfL2Block(ctx, l2Block.NumberU64(), dbTx)
+	if err != nil {
+		log.Errorf("failed to get batch number of l2 block %d from the state, err: %v", l2Block.NumberU64(), err)
+		return nil, nil, err
+	}
+
+	batch, err := s.GetBatchByNumber(ctx, batchNumber, dbTx)
+	if err != nil {
+		log.Errorf("failed to get batch %d from the state, err: %v", batchNumber, err)
+		return nil, nil, err
+	}
+
+	return l2Block, batch, nil
+}
+
+// executeUnsignedTransaction executes an unsigned transaction sent by the
+// given sender on top of the given state root, using the context of the given
+// batch. The executor takes the coinbase as the sender of unsigned txs, so the
+// sender goes in the coinbase of the request. The merkletree is not updated
+// with the result of the execution
+func (s *State) executeUnsignedTransaction(ctx context.Context, tx *types.Transaction, senderAddress common.Address, batch *Batch, stateRoot common.Hash) (*pb.ProcessTransactionResponse, error) {
+	batchL2Data, err := EncodeTransactions([]types.Transaction{*tx})
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a batch to be sent to the executor
+	processBatchRequest := &pb.ProcessBatchRequest{
+		BatchNum:             batch.BatchNumber,
+		Coinbase:             senderAddress.String(),
+		BatchL2Data:          batchL2Data,
+		OldStateRoot:         stateRoot.Bytes(),
+		GlobalExitRoot:       batch.GlobalExitRoot.Bytes(),
+		EthTimestamp:         uint64(batch.Timestamp.Unix()),
+		UpdateMerkleTree:     cFalse,
+		GenerateExecuteTrace: cFalse,
+		GenerateCallTrace:    cFalse,
+	}
+
+	processBatchResponse, err := s.executorClient.ProcessBatch(ctx, processBatchRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(processBatchResponse.Responses) == 0 {
+		return nil, fmt.Errorf("executor returned no response for the transaction")
+	}
+
+	return processBatchResponse.Responses[0], nil
+}
+
+// PreProcessTransaction executes the given tx on top of the state of the last
+// l2 block, using the context of its batch, to know the zk counters it uses.
+// The merkletree is not updated with the result of the execution
+func (s *State) PreProcessTransaction(ctx context.Context, tx *types.Transaction, dbTx pgx.Tx) (*ProcessBatchResponse, error) {
+	l2Block, batch, err := s.getL2BlockAndBatch(ctx, nil, dbTx)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := []types.Transaction{*tx}
+	batchL2Data, err := EncodeTransactions(txs)
+	if err != nil {
+		return nil, err
+	}
+
+	processBatchRequest := &pb.ProcessBatchRequest{
+		BatchNum:             batch.BatchNumber,
+		Coinbase:             batch.Coinbase.String(),
+		BatchL2Data:          batchL2Data,
+		OldStateRoot:         l2Block.Root().Bytes(),
+		GlobalExitRoot:       batch.GlobalExitRoot.Bytes(),
+		OldLocalExitRoot:     batch.LocalExitRoot.Bytes(),
+		EthTimestamp:         uint64(batch.Timestamp.Unix()),
+		UpdateMerkleTree:     cFalse,
+		GenerateExecuteTrace: cFalse,
+		GenerateCallTrace:    cFalse,
+	}
+
+	processBatchResponse, err := s.executorClient.ProcessBatch(ctx, processBatchRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertToProcessBatchResponse(txs, processBatchResponse), nil
+}
+
 // OpenBatch adds a new batch into the state, with the necessary data to start processing transactions within it.
 // It's meant to be used by sequencers, since they don't necessarely know what transactions are going to be added
 // in this batch yet. In other words it's the creation of a WIP batch.
@@ -308,20 +426,27 @@ func (s *State) OpenBatch(ctx context.Context, processingContext ProcessingConte
 	return s.PostgresStorage.openBatch(ctx, processingContext, dbTx)
 }
 
-// ProcessSequencerBatch is used by the sequencers to proceess transactions into an open batch
-func (s *State) ProcessSequencerBatch(ctx context.Context, batchNumber uint64, txs []types.Transaction, dbTx pgx.Tx) (*ProcessBatchResponse, error) {
+// ProcessSequencerTxs is used by the sequencers to process new transactions into
+// an open batch. Only the given txs are executed, on top of the intermediate
+// state left by the txs already stored in the batch, so the counters of the
+// response only account for the given txs.
+func (s *State) ProcessSequencerTxs(ctx context.Context, batchNumber uint64, txs []types.Transaction, dbTx pgx.Tx) (*ProcessBatchResponse, error) {
 	batchL2Data, err := EncodeTransactions(txs)
 	if err != nil {
 		return nil, err
 	}
-	processBatchResponse, err := s.processBatch(ctx, batchNumber, batchL2Data, dbTx)
+	processBatchResponse, err := s.processBatch(ctx, batchNumber, batchL2Data, true, dbTx)
 	if err != nil {
 		return nil, err
 	}
 	return convertToProcessBatchResponse(txs, processBatchResponse), nil
 }
 
-func (s *State) processBatch(ctx context.Context, batchNumber uint64, batchL2Data []byte, dbTx pgx.Tx) (*pb.ProcessBatchResponse, error) {
+// processBatch executes the given batch data in the executor. When
+// fromIntermediateStateRoot is true, the data is executed on top of the state
+// root of the last tx stored in the batch and the local exit root left by it,
+// instead of the previous batch ones
+func (s *State) processBatch(ctx context.Context, batchNumber uint64, batchL2Data []byte, fromIntermediateStateRoot bool, dbTx pgx.Tx) (*pb.ProcessBatchResponse, error) {
 	if dbTx == nil {
 		return nil, ErrDBTxNil
 	}
@@ -346,14 +471,34 @@ func (s *State) processBatch(ctx context.Context, batchNumber uint64, batchL2Dat
 	if lastBatch.BatchNumber != batchNumber {
 		return nil, ErrInvalidBatchNumber
 	}
+
+	oldStateRoot := previousBatch.StateRoot
+	oldLocalExitRoot := previousBatch.LocalExitRoot
+	if fromIntermediateStateRoot {
+		existingTxs, err := s.GetTxsHashesByBatchNumber(ctx, batchNumber, dbTx)
+		if err != nil {
+			return nil, err
+		}
+		// The batch is the last one, so its last tx is in the last l2 block
+		if len(existingTxs) > 0 {
+			lastL2Block, err := s.GetLastL2Block(ctx, dbTx)
+			if err != nil {
+				return nil, err
+			}
+			oldStateRoot = lastL2Block.Root()
+			// Set by AppendTransactions along with the txs
+			oldLocalExitRoot = lastBatch.LocalExitRoot
+		}
+	}
+
 	// Create Batch
 	processBatchRequest := &pb.ProcessBatchRequest{
 		BatchNum:             lastBatch.BatchNumber,
 		Coinbase:             lastBatch.Coinbase.String(),
 		BatchL2Data:          batchL2Data,
-		OldStateRoot:         previousBatch.StateRoot.Bytes(),
+		OldStateRoot:         oldStateRoot.Bytes(),
 		GlobalExitRoot:       lastBatch.GlobalExitRoot.Bytes(),
-		OldLocalExitRoot:     previousBatch.LocalExitRoot.Bytes(),
+		OldLocalExitRoot:     oldLocalExitRoot.Bytes(),
 		EthTimestamp:         uint64(lastBatch.Timestamp.Unix()),
 		UpdateMerkleTree:     cTrue,
 		GenerateExecuteTrace: cFalse,
@@ -381,6 +526,27 @@ func (s *State) StoreTransactions(ctx context.Context, batchNumber uint64, proce
 		return err
 	}
 
+	return s.storeTransactions(ctx, batchNumber, processedTxs[len(existingTxs):], dbTx)
+}
+
+// AppendTransactions is used by the sequencer to add processed transactions
+// after the ones already stored in an open batch, as returned by
+// ProcessSequencerTxs. The local exit root left by the txs is kept in the open
+// batch, so the next txs are processed on top of it.
+func (s *State) AppendTransactions(ctx context.Context, batchNumber uint64, processedTxs []*ProcessTransactionResponse, localExitRoot common.Hash, dbTx pgx.Tx) error {
+	if dbTx == nil {
+		return ErrDBTxNil
+	}
+
+	if err := s.storeTransactions(ctx, batchNumber, processedTxs, dbTx); err != nil {
+		return err
+	}
+	return s.PostgresStorage.updateOpenBatchLocalExitRoot(ctx, batchNumber, localExitRoot, dbTx)
+}
+
+// storeTransactions adds each of the processed txs into its own l2 block of
+// the given open batch
+func (s *State) storeTransactions(ctx context.Context, batchNumber uint64, processedTxs []*ProcessTransactionResponse, dbTx pgx.Tx) error {
 	// Check if last batch is closed. Note that it's assumed that only the latest batch can be open
 	isBatchClosed, err := s.PostgresStorage.IsBatchClosed(ctx, batchNumber, dbTx)
 	if err != nil {
@@ -395,11 +561,7 @@ func (s *State) StoreTransactions(ctx context.Context, batchNumber uint64, proce
 		return err
 	}
 
-	firstTxToInsert := len(existingTxs)
-
-	for i := firstTxToInsert; i < len(processedTxs); i++ {
-		processedTx := processedTxs[i]
-
+	for _, processedTx := range processedTxs {
 		lastL2Block, err := s.GetLastL2Block(ctx, dbTx)
 		if err != nil {
 			return err
@@ -410,19 +572,33 @@ func (s *State) StoreTransactions(ctx context.Context, batchNumber uint64, proce
 			ParentHash: lastL2Block.Hash(),
 			Coinbase:   processingContext.Coinbase,
 			Root:       processedTx.StateRoot,
+			GasUsed:    processedTx.GasUsed,
 		}
 		transactions := []*types.Transaction{&processedTx.Tx}
 
+		// The receipts are generated before the block, so the block header
+		// contains the receipts root and the logs bloom
+		receipt := generateReceipt(header.Number, processedTx)
+		receipts := []*types.Receipt{receipt}
+
 		// Create block to be able to calculate its hash
-		block := types.NewBlock(header, transactions, []*types.Header{}, []*types.Receipt{}, &trie.StackTrie{})
+		block := types.NewBlock(header, transactions, []*types.Header{}, receipts, &trie.StackTrie{})
 		block.ReceivedAt = processingContext.Timestamp
 
-		receipts := []*types.Receipt{generateReceipt(block, processedTx)}
+		setReceiptBlockHash(receipt, block.Hash())
 
 		// Store L2 block and its transaction
 		if err := s.PostgresStorage.AddL2Block(ctx, batchNumber, block, receipts, dbTx); err != nil {
 			return err
 		}
+
+		// The token events are indexed in the same db tx, so they are stored
+		// and reorged along with the l2 block
+		if s.cfg.IndexTokenTransfers && receipt.Status == types.ReceiptStatusSuccessful {
+			if err := s.storeTokenTransfers(ctx, receipt.Logs, dbTx); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -490,43 +666,45 @@ func (s *State) ProcessAndStoreClosedBatch(ctx context.Context, processingCtx Pr
 	if err := s.OpenBatch(ctx, processingCtx, dbTx); err != nil {
 		return err
 	}
-	processed, err := s.processBatch(ctx, processingCtx.BatchNumber, encodedTxs, dbTx)
+	processed, err := s.processBatch(ctx, processingCtx.BatchNumber, encodedTxs, false, dbTx)
 	if err != nil {
 		return err
 	}
 
-	// Filter unprocessed txs and decode txs to store metadata
-	// note that if the batch is not well encoded it will result in an empty batch (with no txs)
-	for i := 0; i < len(processed.Responses); i++ {
-		if !isTransactionProcessed(processed.Responses[i].UnprocessedTransaction) {
-			// Remove unprocessed tx
-			if i == len(processed.Responses)-1 {
-				processed.Responses = processed.Responses[:i]
-			} else {
-				processed.Responses = append(processed.Responses[:i], processed.Responses[i+1:]...)
-			}
-			i--
-		}
-	}
+	// Decode txs to store metadata
+	// note that if the batch is not well encoded the executor only processes
+	// the txs before the malformed one, so those are the ones stored along
+	// with the state root they lead to, an empty batch if there are none
 	var txs []types.Transaction
 	if len(processed.Responses) > 0 {
-		// TODO: missing method to decode txs
-		log.Fatal("TODO: missing method to decode txs")
+		txs, err = decodeTxs(encodedTxs)
+		if err != nil {
+			if len(txs) < len(processed.Responses) {
+				return fmt.Errorf("the executor returned %d responses for the %d txs decoded from batch %d, err: %w", len(processed.Responses), len(txs), processingCtx.BatchNumber, err)
+			}
+			log.Warnf("failed to decode the txs of batch %d, storing the %d txs processed before the malformed one, err: %v", processingCtx.BatchNumber, len(processed.Responses), err)
+			txs = txs[:len(processed.Responses)]
+		} else if len(txs) != len(processed.Responses) {
+			return fmt.Errorf("the executor returned %d responses for %d txs", len(processed.Responses), len(txs))
+		}
 	}
 	processedBatch := convertToProcessBatchResponse(txs, processed)
 
-	// Store processed txs into the batch
-	err = s.StoreTransactions(ctx, processingCtx.BatchNumber, processedBatch.Responses, dbTx)
+	// Store processed txs into the batch, filtering the unprocessed ones
+	processedTxs, _ := DetermineProcessedTransactions(processedBatch.Responses)
+	err = s.StoreTransactions(ctx, processingCtx.BatchNumber, processedTxs, dbTx)
 	if err != nil {
 		return err
 	}
 
-	// Close batch
-	return s.CloseBatch(ctx, ProcessingReceipt{
+	// Close batch, keeping the batch data as received since it may have no
+	// valid txs
+	receipt := ProcessingReceipt{
 		BatchNumber:   processingCtx.BatchNumber,
 		StateRoot:     processedBatch.NewStateRoot,
 		LocalExitRoot: processedBatch.NewLocalExitRoot,
-	}, dbTx)
+	}
+	return s.PostgresStorage.closeBatch(ctx, receipt, encodedTxs, dbTx)
 }
 
 // GetLastBatch gets latest batch (closed or not) on the data base
@@ -683,9 +861,44 @@ func (s *State) ParseTheTraceUsingTheTracer(env *fakevm.FakeEVM, trace instrumen
 	return jsTracer.GetResult()
 }
 
-// ProcessUnsignedTransaction processes the given unsigned transaction.
-func (s *State) ProcessUnsignedTransaction(ctx context.Context, tx *types.Transaction, senderAddress common.Address, blockNumber uint64, dbTx pgx.Tx) *runtime.ExecutionResult {
-	panic("not implemented yet")
+// ProcessUnsignedTransaction processes the given unsigned transaction against
+// the state of the given l2 block, with the state override applied on top of it.
+func (s *State) ProcessUnsignedTransaction(ctx context.Context, tx *types.Transaction, senderAddress common.Address, blockNumber uint64, stateOverride StateOverride, dbTx pgx.Tx) *runtime.ExecutionResult {
+	result := new(runtime.ExecutionResult)
+
+	l2Block, batch, err := s.getL2BlockAndBatch(ctx, &blockNumber, dbTx)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	stateRoot, err := s.applyStateOverride(ctx, l2Block.Root(), stateOverride)
+	if err != nil {
+		log.Errorf("failed to apply the state override, err: %v", err)
+		result.Err = err
+		return result
+	}
+
+	response, err := s.executeUnsignedTransaction(ctx, tx, senderAddress, batch, stateRoot)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.ReturnValue = response.ReturnValue
+	result.GasLeft = response.GasLeft
+	result.GasUsed = response.GasUsed
+	result.CreateAddress = common.HexToAddress(response.CreateAddress)
+	result.StateRoot = response.StateRoot
+
+	if response.Error != "" {
+		result.Err = executorErr(response.Error)
+		if errors.Is(result.Err, runtime.ErrExecutionReverted) {
+			result.Err = constructErrorFromRevert(result.Err, response.ReturnValue)
+		}
+	}
+
+	return result
 }
 
 // AddBatchNumberInForcedBatch updates the forced_batch table with the batchNumber.
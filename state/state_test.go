@@ -23,6 +23,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -282,6 +283,10 @@ func TestAddGlobalExitRoot(t *testing.T) {
 	require.NoError(t, err)
 	exit, err := testState.GetLatestGlobalExitRoot(ctx, tx)
 	require.NoError(t, err)
+	exitByGER, err := testState.GetExitRootByGlobalExitRoot(ctx, globalExitRoot.GlobalExitRoot, tx)
+	require.NoError(t, err)
+	_, err = testState.GetExitRootByGlobalExitRoot(ctx, common.HexToHash("0x1"), tx)
+	require.Equal(t, state.ErrNotFound, err)
 	err = tx.Commit(ctx)
 	require.NoError(t, err)
 	assert.Equal(t, globalExitRoot.BlockNumber, exit.BlockNumber)
@@ -289,6 +294,7 @@ func TestAddGlobalExitRoot(t *testing.T) {
 	assert.Equal(t, globalExitRoot.MainnetExitRoot, exit.MainnetExitRoot)
 	assert.Equal(t, globalExitRoot.RollupExitRoot, exit.RollupExitRoot)
 	assert.Equal(t, globalExitRoot.GlobalExitRoot, exit.GlobalExitRoot)
+	assert.Equal(t, exit, exitByGER)
 }
 
 func TestAddForcedBatch(t *testing.T) {
@@ -389,6 +395,13 @@ func TestAddVirtualBatch(t *testing.T) {
 	}
 	err = testState.AddVirtualBatch(ctx, &virtualBatch, tx)
 	require.NoError(t, err)
+
+	actualVirtualBatch, err := testState.GetVirtualBatch(ctx, 1, tx)
+	require.NoError(t, err)
+	assert.Equal(t, virtualBatch, *actualVirtualBatch)
+
+	_, err = testState.GetVirtualBatch(ctx, 2, tx)
+	assert.ErrorIs(t, err, state.ErrNotFound)
 	require.NoError(t, tx.Commit(ctx))
 }
 
@@ -487,6 +500,15 @@ func TestVerifiedBatch(t *testing.T) {
 	}
 	err = testState.AddVirtualBatch(ctx, &virtualBatch, dbTx)
 	require.NoError(t, err)
+	l2Block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, nil, nil, nil, &trie.StackTrie{})
+	err = testState.AddL2Block(ctx, 1, l2Block, []*types.Receipt{}, dbTx)
+	require.NoError(t, err)
+	lastVirtualBlockNumber, err := testState.GetLastVirtualL2BlockNumber(ctx, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), lastVirtualBlockNumber)
+	_, err = testState.GetLastConsolidatedL2BlockNumber(ctx, dbTx)
+	require.ErrorIs(t, err, state.ErrNotFound)
+
 	expectedVerifiedBatch := state.VerifiedBatch{
 		BlockNumber: 1,
 		BatchNumber: 1,
@@ -501,10 +523,43 @@ func TestVerifiedBatch(t *testing.T) {
 	actualVerifiedBatch, err := testState.GetVerifiedBatch(ctx, 1, dbTx)
 	require.NoError(t, err)
 	require.Equal(t, expectedVerifiedBatch, *actualVerifiedBatch)
+	lastConsolidatedBlockNumber, err := testState.GetLastConsolidatedL2BlockNumber(ctx, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), lastConsolidatedBlockNumber)
 
 	require.NoError(t, dbTx.Commit(ctx))
 }
 
+// senderCapturingExecutor records the coinbase of the processed batches
+type senderCapturingExecutor struct {
+	coinbase string
+}
+
+func (e *senderCapturingExecutor) ProcessBatch(ctx context.Context, in *executorclientpb.ProcessBatchRequest, opts ...grpc.CallOption) (*executorclientpb.ProcessBatchResponse, error) {
+	e.coinbase = in.Coinbase
+	return &executorclientpb.ProcessBatchResponse{Responses: []*executorclientpb.ProcessTransactionResponse{{}}}, nil
+}
+
+func TestProcessUnsignedTransactionSender(t *testing.T) {
+	err := dbutils.InitOrReset(cfg)
+	require.NoError(t, err)
+	dbTx, err := testState.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	err = testState.SetGenesis(ctx, state.Block{}, state.Genesis{}, dbTx)
+	require.NoError(t, err)
+	require.NoError(t, dbTx.Commit(ctx))
+
+	executor := &senderCapturingExecutor{}
+	st := state.NewState(stateCfg, testState.PostgresStorage, executor, testState.GetTree())
+
+	// the executor takes the coinbase as the sender of unsigned txs
+	sender := common.HexToAddress("0x617b3a3528F9cDd6630fd3301B9c8911F7Bf063D")
+	tx := types.NewTransaction(0, common.HexToAddress("0x1"), big.NewInt(0), 21000, big.NewInt(0), nil)
+	result := st.ProcessUnsignedTransaction(ctx, tx, sender, 0, nil, nil)
+	require.NoError(t, result.Err)
+	assert.Equal(t, sender.String(), executor.coinbase)
+}
+
 func TestExecuteTransaction(t *testing.T) {
 	var chainIDSequencer = new(big.Int).SetInt64(400)
 	var sequencerAddress = common.HexToAddress("0x617b3a3528F9cDd6630fd3301B9c8911F7Bf063D")
@@ -915,3 +970,449 @@ func TestDetermineProcessedTransactions(t *testing.T) {
 		})
 	}
 }
+
+func TestAddGetLogs(t *testing.T) {
+	// Init database instance
+	err := dbutils.InitOrReset(cfg)
+	require.NoError(t, err)
+	ctx := context.Background()
+	dbTx, err := testState.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	// Set genesis batch
+	err = testState.SetGenesis(ctx, state.Block{}, state.Genesis{}, dbTx)
+	require.NoError(t, err)
+	// Open batch #1
+	processingCtx1 := state.ProcessingContext{
+		BatchNumber:    1,
+		Coinbase:       common.HexToAddress("1"),
+		Timestamp:      time.Now().UTC(),
+		GlobalExitRoot: common.HexToHash("a"),
+	}
+	err = testState.OpenBatch(ctx, processingCtx1, dbTx)
+	require.NoError(t, err)
+
+	contract1 := common.HexToAddress("0x1")
+	contract2 := common.HexToAddress("0x2")
+	topic1 := common.HexToHash("0x11")
+	topic2 := common.HexToHash("0x22")
+
+	// Add txs emitting several logs each to batch #1
+	tx1 := *types.NewTransaction(0, contract1, big.NewInt(0), 0, big.NewInt(0), []byte("aaa"))
+	tx2 := *types.NewTransaction(1, contract2, big.NewInt(1), 0, big.NewInt(1), []byte("bbb"))
+	txsBatch1 := []*state.ProcessTransactionResponse{
+		{
+			TxHash: tx1.Hash(),
+			Tx:     tx1,
+			Logs: []*types.Log{
+				{Address: contract1, Topics: []common.Hash{topic1}, Data: []byte{1}, Index: 0},
+				{Address: contract1, Topics: []common.Hash{topic2, topic1}, Data: []byte{2}, Index: 1},
+				{Address: contract2, Topics: []common.Hash{}, Data: []byte{3}, Index: 2},
+			},
+		},
+		{
+			TxHash: tx2.Hash(),
+			Tx:     tx2,
+			Logs: []*types.Log{
+				{Address: contract2, Topics: []common.Hash{topic1}, Data: []byte{4}, Index: 0},
+			},
+		},
+	}
+	err = testState.StoreTransactions(ctx, 1, txsBatch1, dbTx)
+	require.NoError(t, err)
+
+	receipt, err := testState.GetTransactionReceipt(ctx, tx1.Hash(), dbTx)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(receipt.Logs))
+	for i, l := range receipt.Logs {
+		assert.Equal(t, uint(i), l.Index)
+		assert.Equal(t, tx1.Hash(), l.TxHash)
+		assert.Equal(t, receipt.BlockNumber.Uint64(), l.BlockNumber)
+		assert.Equal(t, receipt.BlockHash, l.BlockHash)
+	}
+
+	logs, err := testState.GetLogs(ctx, 0, 10, []common.Address{contract1}, nil, nil, nil, dbTx)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(logs))
+	assert.Equal(t, []common.Hash{topic2, topic1}, logs[1].Topics)
+
+	logs, err = testState.GetLogs(ctx, 0, 10, nil, [][]common.Hash{{topic1}}, nil, nil, dbTx)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(logs))
+	assert.Equal(t, []byte{1}, logs[0].Data)
+	assert.Equal(t, []byte{4}, logs[1].Data)
+
+	logs, err = testState.GetLogs(ctx, 0, 10, []common.Address{contract2}, nil, nil, nil, dbTx)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(logs))
+	assert.Equal(t, 0, len(logs[0].Topics))
+
+	// Check the block header commits to the transactions and receipts
+	block, err := testState.GetL2BlockByNumber(ctx, receipt.BlockNumber.Uint64(), dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, receipt.BlockHash, block.Hash())
+	assert.Equal(t, types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)), block.TxHash())
+	assert.Equal(t, types.DeriveSha(types.Receipts{receipt}, trie.NewStackTrie(nil)), block.ReceiptHash())
+	assert.Equal(t, receipt.Bloom, block.Bloom())
+	assert.True(t, types.BloomLookup(block.Bloom(), topic2))
+	assert.True(t, types.BloomLookup(block.Bloom(), contract1))
+
+	require.NoError(t, dbTx.Commit(ctx))
+}
+
+func TestGetTxsByAddress(t *testing.T) {
+	// Init database instance
+	err := dbutils.InitOrReset(cfg)
+	require.NoError(t, err)
+	ctx := context.Background()
+	dbTx, err := testState.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	// Set genesis batch
+	err = testState.SetGenesis(ctx, state.Block{}, state.Genesis{}, dbTx)
+	require.NoError(t, err)
+	// Open batch #1
+	processingCtx1 := state.ProcessingContext{
+		BatchNumber:    1,
+		Coinbase:       common.HexToAddress("1"),
+		Timestamp:      time.Now().UTC(),
+		GlobalExitRoot: common.HexToHash("a"),
+	}
+	err = testState.OpenBatch(ctx, processingCtx1, dbTx)
+	require.NoError(t, err)
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(privateKey.PublicKey)
+	recipient := common.HexToAddress("0x2")
+	signer := types.NewEIP155Signer(big.NewInt(1000))
+
+	// Add a tx sent by the sender, a tx sent to the recipient and a contract creation
+	tx1, err := types.SignTx(types.NewTransaction(0, common.HexToAddress("0x1"), big.NewInt(0), 0, big.NewInt(0), nil), signer, privateKey)
+	require.NoError(t, err)
+	tx2 := types.NewTransaction(0, recipient, big.NewInt(1), 0, big.NewInt(1), nil)
+	tx3, err := types.SignTx(types.NewContractCreation(1, big.NewInt(0), 0, big.NewInt(0), []byte("aaa")), signer, privateKey)
+	require.NoError(t, err)
+	contract := crypto.CreateAddress(sender, 1)
+	txsBatch1 := []*state.ProcessTransactionResponse{
+		{TxHash: tx1.Hash(), Tx: *tx1},
+		{TxHash: tx2.Hash(), Tx: *tx2},
+		{TxHash: tx3.Hash(), Tx: *tx3, CreateAddress: contract},
+	}
+	err = testState.StoreTransactions(ctx, 1, txsBatch1, dbTx)
+	require.NoError(t, err)
+
+	txs, err := testState.GetTxsByAddress(ctx, sender, 0, 10, nil, 10, dbTx)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(txs))
+	assert.Equal(t, tx1.Hash(), txs[0].Tx.Hash())
+	assert.Equal(t, tx3.Hash(), txs[1].Tx.Hash())
+
+	// the next page starts after the position of the last tx
+	txs, err = testState.GetTxsByAddress(ctx, sender, 0, 10, &txs[0].TxPosition, 10, dbTx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(txs))
+	assert.Equal(t, tx3.Hash(), txs[0].Tx.Hash())
+
+	txs, err = testState.GetTxsByAddress(ctx, recipient, 0, 10, nil, 10, dbTx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(txs))
+	assert.Equal(t, tx2.Hash(), txs[0].Tx.Hash())
+
+	txs, err = testState.GetTxsByAddress(ctx, contract, 0, 10, nil, 10, dbTx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(txs))
+	assert.Equal(t, tx3.Hash(), txs[0].Tx.Hash())
+	require.NoError(t, dbTx.Commit(ctx))
+}
+
+func TestTokenTransfers(t *testing.T) {
+	// Init database instance
+	err := dbutils.InitOrReset(cfg)
+	require.NoError(t, err)
+	ctx := context.Background()
+	st := state.NewState(state.Config{MaxCumulativeGasUsed: 800000, IndexTokenTransfers: true}, testState.PostgresStorage, executorClient, testState.GetTree())
+	dbTx, err := st.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	// Set genesis batch
+	err = st.SetGenesis(ctx, state.Block{}, state.Genesis{}, dbTx)
+	require.NoError(t, err)
+	// Open batch #1
+	processingCtx1 := state.ProcessingContext{
+		BatchNumber:    1,
+		Coinbase:       common.HexToAddress("1"),
+		Timestamp:      time.Now().UTC(),
+		GlobalExitRoot: common.HexToHash("a"),
+	}
+	err = st.OpenBatch(ctx, processingCtx1, dbTx)
+	require.NoError(t, err)
+
+	erc20 := common.HexToAddress("0x20")
+	erc721 := common.HexToAddress("0x721")
+	alice := common.HexToAddress("0xa")
+	bob := common.HexToAddress("0xb")
+	transferTopic := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	approvalTopic := crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	amount := func(value int64) []byte {
+		return common.LeftPadBytes(big.NewInt(value).Bytes(), 32) //nolint:gomnd
+	}
+	tokenID := common.BigToHash(big.NewInt(1))
+
+	// The token events are indexed as the txs are stored, the other logs and
+	// the logs of failed txs are ignored
+	tx1 := *types.NewTransaction(0, common.HexToAddress("0"), big.NewInt(0), 0, big.NewInt(0), []byte("aaa"))
+	tx2 := *types.NewTransaction(1, common.HexToAddress("1"), big.NewInt(1), 0, big.NewInt(1), []byte("bbb"))
+	tx3 := *types.NewTransaction(2, common.HexToAddress("1"), big.NewInt(1), 0, big.NewInt(1), []byte("ccc"))
+	processedTxs := []*state.ProcessTransactionResponse{
+		{TxHash: tx1.Hash(), Tx: tx1, Logs: []*types.Log{
+			{Address: erc20, Index: 0, Topics: []common.Hash{transferTopic, common.Address{}.Hash(), alice.Hash()}, Data: amount(100)},
+			{Address: erc721, Index: 1, Topics: []common.Hash{transferTopic, common.Address{}.Hash(), alice.Hash(), tokenID}},
+			{Address: erc20, Index: 2, Topics: []common.Hash{common.HexToHash("0x123"), alice.Hash()}},
+			{Address: erc20, Index: 3},
+		}},
+		{TxHash: tx2.Hash(), Tx: tx2, Logs: []*types.Log{
+			{Address: erc20, Index: 0, Topics: []common.Hash{transferTopic, alice.Hash(), bob.Hash()}, Data: amount(30)},
+			{Address: erc721, Index: 1, Topics: []common.Hash{approvalTopic, alice.Hash(), bob.Hash(), tokenID}},
+		}},
+		{TxHash: tx3.Hash(), Tx: tx3, Error: "execution reverted", Logs: []*types.Log{
+			{Address: erc20, Index: 0, Topics: []common.Hash{transferTopic, alice.Hash(), bob.Hash()}, Data: amount(70)},
+		}},
+	}
+	err = st.StoreTransactions(ctx, 1, processedTxs, dbTx)
+	require.NoError(t, err)
+
+	aliceTransfers, err := st.GetTokenTransfers(ctx, alice, 0, 3, nil, 10, dbTx)
+	require.NoError(t, err)
+	require.Equal(t, 4, len(aliceTransfers))
+	assert.Equal(t, state.TokenTransfer{
+		LogPosition: state.LogPosition{BlockNumber: 1, LogIndex: 1},
+		TxHash:      tx1.Hash(),
+		Token:       erc721,
+		TokenType:   state.TokenTypeERC721,
+		EventType:   state.TokenEventTransfer,
+		From:        common.Address{},
+		To:          alice,
+		Value:       big.NewInt(1),
+	}, aliceTransfers[1])
+	assert.Equal(t, state.TokenEventApproval, aliceTransfers[3].EventType)
+
+	aliceTransfers, err = st.GetTokenTransfers(ctx, alice, 0, 3, &state.LogPosition{BlockNumber: 1, LogIndex: 1}, 10, dbTx)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(aliceTransfers))
+	assert.Equal(t, state.LogPosition{BlockNumber: 2, LogIndex: 0}, aliceTransfers[0].LogPosition)
+
+	balances, ownerships, err := st.GetTokenBalances(ctx, alice, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, []state.TokenBalance{{Token: erc20, Balance: big.NewInt(70)}}, balances)
+	assert.Equal(t, []state.TokenOwnership{{Token: erc721, TokenID: big.NewInt(1)}}, ownerships)
+
+	// The token events are removed along with their l2 blocks
+	err = st.ResetTrustedState(ctx, 0, dbTx)
+	require.NoError(t, err)
+	aliceTransfers, err = st.GetTokenTransfers(ctx, alice, 0, 3, nil, 10, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(aliceTransfers))
+	require.NoError(t, dbTx.Commit(ctx))
+}
+
+func TestBackfillTokenTransfers(t *testing.T) {
+	// Init database instance
+	err := dbutils.InitOrReset(cfg)
+	require.NoError(t, err)
+	ctx := context.Background()
+	dbTx, err := testState.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	// Set genesis batch
+	err = testState.SetGenesis(ctx, state.Block{}, state.Genesis{}, dbTx)
+	require.NoError(t, err)
+	// Open batch #1
+	processingCtx1 := state.ProcessingContext{
+		BatchNumber:    1,
+		Coinbase:       common.HexToAddress("1"),
+		Timestamp:      time.Now().UTC(),
+		GlobalExitRoot: common.HexToHash("a"),
+	}
+	err = testState.OpenBatch(ctx, processingCtx1, dbTx)
+	require.NoError(t, err)
+
+	erc20 := common.HexToAddress("0x20")
+	alice := common.HexToAddress("0xa")
+	bob := common.HexToAddress("0xb")
+	transferTopic := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	amount := common.LeftPadBytes(big.NewInt(100).Bytes(), 32) //nolint:gomnd
+
+	// The txs are stored without indexing their token events
+	tx1 := *types.NewTransaction(0, common.HexToAddress("0"), big.NewInt(0), 0, big.NewInt(0), []byte("aaa"))
+	tx2 := *types.NewTransaction(1, common.HexToAddress("1"), big.NewInt(1), 0, big.NewInt(1), []byte("bbb"))
+	processedTxs := []*state.ProcessTransactionResponse{
+		{TxHash: tx1.Hash(), Tx: tx1, Logs: []*types.Log{
+			{Address: erc20, Index: 0, Topics: []common.Hash{transferTopic, common.Address{}.Hash(), alice.Hash()}, Data: amount},
+			{Address: erc20, Index: 1, Topics: []common.Hash{common.HexToHash("0x123"), alice.Hash()}},
+		}},
+		{TxHash: tx2.Hash(), Tx: tx2, Error: "execution reverted", Logs: []*types.Log{
+			{Address: erc20, Index: 0, Topics: []common.Hash{transferTopic, alice.Hash(), bob.Hash()}, Data: amount},
+		}},
+	}
+	err = testState.StoreTransactions(ctx, 1, processedTxs, dbTx)
+	require.NoError(t, err)
+	require.NoError(t, dbTx.Commit(ctx))
+
+	transfers, err := testState.GetTokenTransfers(ctx, alice, 0, 2, nil, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(transfers))
+
+	// The events of the successful txs are backfilled once
+	st := state.NewState(state.Config{MaxCumulativeGasUsed: 800000, IndexTokenTransfers: true}, testState.PostgresStorage, executorClient, testState.GetTree())
+	for i := 0; i < 2; i++ {
+		require.NoError(t, st.BackfillTokenTransfers(ctx))
+
+		transfers, err = st.GetTokenTransfers(ctx, alice, 0, 2, nil, 10, nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(transfers))
+		assert.Equal(t, tx1.Hash(), transfers[0].TxHash)
+		assert.Equal(t, big.NewInt(100), transfers[0].Value)
+	}
+}
+
+func TestGasPrices(t *testing.T) {
+	// Init database instance
+	err := dbutils.InitOrReset(cfg)
+	require.NoError(t, err)
+	ctx := context.Background()
+	dbTx, err := testState.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	// Set genesis batch
+	err = testState.SetGenesis(ctx, state.Block{}, state.Genesis{}, dbTx)
+	require.NoError(t, err)
+
+	gasPriceAvg, err := testState.GetGasPriceAvg(ctx, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, &state.GasPriceAvg{Avg: big.NewInt(0)}, gasPriceAvg)
+	_, err = testState.GetGasPricePercentile(ctx, 0.5, time.Time{}, dbTx)
+	require.ErrorIs(t, err, state.ErrNotFound)
+
+	// Open batch #1 and add three txs to have three l2 blocks
+	processingCtx1 := state.ProcessingContext{
+		BatchNumber:    1,
+		Coinbase:       common.HexToAddress("1"),
+		Timestamp:      time.Now().UTC(),
+		GlobalExitRoot: common.HexToHash("a"),
+	}
+	err = testState.OpenBatch(ctx, processingCtx1, dbTx)
+	require.NoError(t, err)
+	tx1 := *types.NewTransaction(0, common.HexToAddress("0"), big.NewInt(0), 0, big.NewInt(10), []byte("aaa"))
+	tx2 := *types.NewTransaction(1, common.HexToAddress("1"), big.NewInt(1), 0, big.NewInt(30), []byte("bbb"))
+	tx3 := *types.NewTransaction(2, common.HexToAddress("2"), big.NewInt(2), 0, big.NewInt(20), []byte("ccc"))
+	err = testState.StoreTransactions(ctx, 1, []*state.ProcessTransactionResponse{{TxHash: tx1.Hash(), Tx: tx1}, {TxHash: tx2.Hash(), Tx: tx2}, {TxHash: tx3.Hash(), Tx: tx3}}, dbTx)
+	require.NoError(t, err)
+
+	gasPrices, err := testState.GetEffectiveGasPrices(ctx, 2, 3, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, []*big.Int{big.NewInt(30), big.NewInt(20)}, gasPrices)
+
+	// the genesis l2 block has no tx
+	blocks, err := testState.GetL2BlocksByNumberRange(ctx, 0, 2, dbTx)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(blocks))
+	assert.Equal(t, 0, len(blocks[0].Transactions()))
+	require.Equal(t, 1, len(blocks[2].Transactions()))
+	assert.Equal(t, tx2.Hash(), blocks[2].Transactions()[0].Hash())
+
+	gasPrice, err := testState.GetGasPricePercentile(ctx, 0.5, processingCtx1.Timestamp.Add(-time.Minute), dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(20), gasPrice)
+	_, err = testState.GetGasPricePercentile(ctx, 0.5, processingCtx1.Timestamp.Add(time.Minute), dbTx)
+	require.ErrorIs(t, err, state.ErrNotFound)
+
+	expected := &state.GasPriceAvg{LastL2BlockNumber: 3, Avg: big.NewInt(20), Count: 3}
+	require.NoError(t, testState.SetGasPriceAvg(ctx, expected, dbTx))
+	gasPriceAvg, err = testState.GetGasPriceAvg(ctx, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, expected, gasPriceAvg)
+	require.NoError(t, dbTx.Commit(ctx))
+}
+
+func TestAppendTransactions(t *testing.T) {
+	// Init database instance
+	err := dbutils.InitOrReset(cfg)
+	require.NoError(t, err)
+	ctx := context.Background()
+	dbTx, err := testState.BeginStateTransaction(ctx)
+	require.NoError(t, err)
+	// Set genesis batch
+	err = testState.SetGenesis(ctx, state.Block{}, state.Genesis{}, dbTx)
+	require.NoError(t, err)
+	// Open batch #1
+	processingCtx1 := state.ProcessingContext{
+		BatchNumber:    1,
+		Coinbase:       common.HexToAddress("1"),
+		Timestamp:      time.Now().UTC(),
+		GlobalExitRoot: common.HexToHash("a"),
+	}
+	err = testState.OpenBatch(ctx, processingCtx1, dbTx)
+	require.NoError(t, err)
+
+	tx1 := *types.NewTransaction(0, common.HexToAddress("0"), big.NewInt(0), 0, big.NewInt(0), []byte("aaa"))
+	tx2 := *types.NewTransaction(1, common.HexToAddress("1"), big.NewInt(1), 0, big.NewInt(1), []byte("bbb"))
+	// each call only contains the new txs of the batch
+	err = testState.AppendTransactions(ctx, 1, []*state.ProcessTransactionResponse{{TxHash: tx1.Hash(), Tx: tx1, StateRoot: common.HexToHash("0x1")}}, common.HexToHash("0xb"), dbTx)
+	require.NoError(t, err)
+	err = testState.AppendTransactions(ctx, 1, []*state.ProcessTransactionResponse{{TxHash: tx2.Hash(), Tx: tx2, StateRoot: common.HexToHash("0x2")}}, common.HexToHash("0xc"), dbTx)
+	require.NoError(t, err)
+
+	// the open batch keeps the local exit root left by its last tx
+	openBatch, err := testState.GetBatchByNumber(ctx, 1, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, common.HexToHash("0xc"), openBatch.LocalExitRoot)
+	isBatchClosed, err := testState.IsBatchClosed(ctx, 1, dbTx)
+	require.NoError(t, err)
+	assert.False(t, isBatchClosed)
+
+	txs, err := testState.GetTxsHashesByBatchNumber(ctx, 1, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, []common.Hash{tx1.Hash(), tx2.Hash()}, txs)
+
+	lastL2Block, err := testState.GetLastL2Block(ctx, dbTx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), lastL2Block.NumberU64())
+	assert.Equal(t, common.HexToHash("0x2"), lastL2Block.Root())
+
+	// txs can't be appended to a closed batch
+	err = testState.CloseBatch(ctx, state.ProcessingReceipt{BatchNumber: 1, StateRoot: common.HexToHash("0x2")}, dbTx)
+	require.NoError(t, err)
+	err = testState.AppendTransactions(ctx, 1, []*state.ProcessTransactionResponse{{TxHash: tx1.Hash(), Tx: tx1}}, common.Hash{}, dbTx)
+	require.ErrorIs(t, err, state.ErrBatchAlreadyClosed)
+	require.NoError(t, dbTx.Commit(ctx))
+}
+
+func TestDecodeTxs(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.NewEIP155Signer(big.NewInt(1000))
+
+	// Add a transfer, a contract creation and a tx with data
+	var txs []types.Transaction
+	for i, tx := range []*types.Transaction{
+		types.NewTransaction(0, common.HexToAddress("0x1"), big.NewInt(10), 21000, big.NewInt(1), nil),
+		types.NewContractCreation(1, big.NewInt(0), 100000, big.NewInt(2), []byte("aaa")),
+		types.NewTransaction(2, common.HexToAddress("0x2"), big.NewInt(0), 50000, big.NewInt(3), []byte{0x1, 0x2}),
+	} {
+		signedTx, err := types.SignTx(tx, signer, privateKey)
+		require.NoError(t, err, i)
+		txs = append(txs, *signedTx)
+	}
+
+	batchL2Data, err := state.EncodeTransactions(txs)
+	require.NoError(t, err)
+
+	decodedTxs, err := state.DecodeTxs(batchL2Data)
+	require.NoError(t, err)
+	require.Equal(t, len(txs), len(decodedTxs))
+	for i := range txs {
+		assert.Equal(t, txs[i].Hash(), decodedTxs[i].Hash())
+		sender, err := state.GetSender(decodedTxs[i])
+		require.NoError(t, err)
+		assert.Equal(t, crypto.PubkeyToAddress(privateKey.PublicKey), sender)
+	}
+
+	_, err = state.DecodeTxs(batchL2Data[:len(batchL2Data)-1])
+	require.Error(t, err)
+}
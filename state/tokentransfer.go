@@ -0,0 +1,174 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	// TokenTypeERC20 identifies the events of a fungible token
+	TokenTypeERC20 = "erc20"
+	// TokenTypeERC721 identifies the events of a non fungible token
+	TokenTypeERC721 = "erc721"
+
+	// TokenEventTransfer identifies a Transfer event
+	TokenEventTransfer = "transfer"
+	// TokenEventApproval identifies an Approval event
+	TokenEventApproval = "approval"
+
+	// erc20TopicsLength is the number of topics of the ERC-20 events, the
+	// amount is not indexed and goes in the data
+	erc20TopicsLength = 3
+	// erc721TopicsLength is the number of topics of the ERC-721 events, the
+	// token id is indexed too
+	erc721TopicsLength = 4
+	uint256Length      = 32
+
+	// backfillBlocks is the number of l2 blocks whose token events are
+	// backfilled on each db tx
+	backfillBlocks = 1000
+)
+
+var (
+	// transferTopic is the signature of the Transfer event, it is the same
+	// for ERC-20 and ERC-721 tokens
+	transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	// approvalTopic is the signature of the Approval event, it is the same
+	// for ERC-20 and ERC-721 tokens
+	approvalTopic = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+)
+
+// LogPosition locates a log in the l2 blocks
+type LogPosition struct {
+	BlockNumber uint64
+	LogIndex    uint64
+}
+
+// TokenTransfer is a Transfer or Approval event emitted by an ERC-20 or
+// ERC-721 token. For approvals, From is the owner and To the approved address
+type TokenTransfer struct {
+	LogPosition
+	TxHash    common.Hash
+	Token     common.Address
+	TokenType string
+	EventType string
+	From      common.Address
+	To        common.Address
+	// Value is the amount for ERC-20 tokens and the token id for ERC-721 tokens
+	Value *big.Int
+}
+
+// TokenBalance is the balance of an ERC-20 token computed from its Transfer events
+type TokenBalance struct {
+	Token   common.Address
+	Balance *big.Int
+}
+
+// TokenOwnership is an ERC-721 token whose last Transfer event sent it to the owner
+type TokenOwnership struct {
+	Token   common.Address
+	TokenID *big.Int
+}
+
+// storeTokenTransfers stores the token events found in the given logs of a
+// successful tx
+func (s *State) storeTokenTransfers(ctx context.Context, logs []*types.Log, dbTx pgx.Tx) error {
+	for _, l := range logs {
+		transfer, ok := decodeTokenEvent(l)
+		if !ok {
+			continue
+		}
+		if err := s.PostgresStorage.AddTokenTransfer(ctx, transfer, dbTx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BackfillTokenTransfers indexes the token events of the l2 blocks stored
+// while the indexing was disabled. The events already indexed are skipped, so
+// it can run while the new l2 blocks are indexed as they are stored.
+func (s *State) BackfillTokenTransfers(ctx context.Context) error {
+	lastBlock, err := s.PostgresStorage.GetLastL2BlockNumber(ctx, nil)
+	if errors.Is(err, ErrStateNotSynchronized) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for fromBlock := uint64(0); fromBlock <= lastBlock; fromBlock += backfillBlocks {
+		toBlock := fromBlock + backfillBlocks - 1
+		if toBlock > lastBlock {
+			toBlock = lastBlock
+		}
+		if err := s.backfillTokenTransfers(ctx, fromBlock, toBlock); err != nil {
+			return fmt.Errorf("failed to backfill the token events of l2 blocks %d to %d, err: %w", fromBlock, toBlock, err)
+		}
+	}
+	return nil
+}
+
+func (s *State) backfillTokenTransfers(ctx context.Context, fromBlock, toBlock uint64) error {
+	dbTx, err := s.BeginStateTransaction(ctx)
+	if err != nil {
+		return err
+	}
+
+	logs, err := s.PostgresStorage.getUnindexedTokenLogs(ctx, fromBlock, toBlock, dbTx)
+	if err == nil {
+		err = s.storeTokenTransfers(ctx, logs, dbTx)
+	}
+	if err != nil {
+		if rollbackErr := dbTx.Rollback(ctx); rollbackErr != nil {
+			return fmt.Errorf("error rolling back state: %v, err: %w", rollbackErr, err)
+		}
+		return err
+	}
+	return dbTx.Commit(ctx)
+}
+
+// decodeTokenEvent decodes a Transfer or Approval event of an ERC-20 or an
+// ERC-721 token, it returns false when the log is not one of them
+func decodeTokenEvent(l *types.Log) (*TokenTransfer, bool) {
+	if len(l.Topics) == 0 {
+		return nil, false
+	}
+
+	transfer := &TokenTransfer{
+		LogPosition: LogPosition{BlockNumber: l.BlockNumber, LogIndex: uint64(l.Index)},
+		TxHash:      l.TxHash,
+		Token:       l.Address,
+	}
+
+	switch l.Topics[0] {
+	case transferTopic:
+		transfer.EventType = TokenEventTransfer
+	case approvalTopic:
+		transfer.EventType = TokenEventApproval
+	default:
+		return nil, false
+	}
+
+	switch {
+	case len(l.Topics) == erc20TopicsLength && len(l.Data) == uint256Length:
+		transfer.TokenType = TokenTypeERC20
+		transfer.Value = new(big.Int).SetBytes(l.Data)
+	case len(l.Topics) == erc721TopicsLength && len(l.Data) == 0:
+		transfer.TokenType = TokenTypeERC721
+		transfer.Value = l.Topics[3].Big()
+	default:
+		return nil, false
+	}
+
+	transfer.From = common.BytesToAddress(l.Topics[1].Bytes())
+	transfer.To = common.BytesToAddress(l.Topics[2].Bytes())
+
+	return transfer, true
+}
@@ -14,3 +14,27 @@ func GetSender(tx types.Transaction) (common.Address, error) {
 	}
 	return sender, nil
 }
+
+// TxStatus represents how far a tx has gone on its way to finality: the
+// trusted l2 block that contains it and, when its batch has already been
+// sequenced or verified on L1, the virtual and verified batch
+type TxStatus struct {
+	L2BlockNumber uint64
+	L2BlockHash   common.Hash
+	BatchNumber   uint64
+	VirtualBatch  *VirtualBatch
+	VerifiedBatch *VerifiedBatch
+}
+
+// TxPosition locates a tx in the l2 blocks
+type TxPosition struct {
+	BlockNumber uint64
+	Index       uint64
+}
+
+// TxInL2Block is a tx along with its position in the l2 blocks
+type TxInL2Block struct {
+	TxPosition
+	Tx        *types.Transaction
+	BlockHash common.Hash
+}
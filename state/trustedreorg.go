@@ -0,0 +1,39 @@
+package state
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// TrustedReorgFieldBatchL2Data identifies a divergence in the batch transactions
+	TrustedReorgFieldBatchL2Data = "batchL2Data"
+	// TrustedReorgFieldGlobalExitRoot identifies a divergence in the batch global exit root
+	TrustedReorgFieldGlobalExitRoot = "globalExitRoot"
+	// TrustedReorgFieldTimestamp identifies a divergence in the batch timestamp
+	TrustedReorgFieldTimestamp = "timestamp"
+	// TrustedReorgFieldCoinbase identifies a divergence in the batch coinbase
+	TrustedReorgFieldCoinbase = "coinbase"
+)
+
+// TrustedReorg is the report of a divergence between the trusted state
+// and the state virtualized on L1 for a given batch
+type TrustedReorg struct {
+	ID uint64
+	// BatchNumber is the batch where the divergence was found
+	BatchNumber uint64
+	// BlockNumber is the L1 block where the batch was sequenced
+	BlockNumber uint64
+	// DivergentFields contains the batch fields that didn't match
+	DivergentFields []string
+	// OldStateRoot is the state root of the trusted batch
+	OldStateRoot common.Hash
+	// NewStateRoot is the state root after processing the virtual batch
+	NewStateRoot common.Hash
+	// L2BlockNumbers are the trusted L2 blocks discarded by the reorg
+	L2BlockNumbers []uint64
+	// TxHashes are the trusted transactions discarded by the reorg
+	TxHashes   []common.Hash
+	ReportedAt time.Time
+}
@@ -4,6 +4,15 @@ import (
 	"github.com/0xPolygonHermez/zkevm-node/config/types"
 )
 
+const (
+	// LatestL1SyncMode syncs L1 up to the latest block
+	LatestL1SyncMode = "latest"
+	// SafeL1SyncMode syncs L1 up to the block tagged as safe
+	SafeL1SyncMode = "safe"
+	// FinalizedL1SyncMode syncs L1 up to the block tagged as finalized
+	FinalizedL1SyncMode = "finalized"
+)
+
 // Config represents the configuration of the synchronizer
 type Config struct {
 	// SyncInterval is the delay interval between reading new rollup information
@@ -11,4 +20,12 @@ type Config struct {
 
 	// SyncChunkSize is the number of blocks to sync on each chunk
 	SyncChunkSize uint64 `mapstructure:"SyncChunkSize"`
+
+	// L1SyncMode defines up to which L1 block the rollup information is consolidated.
+	// Valid values are "latest", "safe" and "finalized"
+	L1SyncMode string `mapstructure:"L1SyncMode"`
+
+	// L1BlockConfirmations is the number of blocks to stay behind the L1 head
+	// when L1SyncMode is "latest"
+	L1BlockConfirmations uint64 `mapstructure:"L1BlockConfirmations"`
 }
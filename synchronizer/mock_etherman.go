@@ -119,6 +119,29 @@ func (_m *ethermanMock) HeaderByNumber(ctx context.Context, number *big.Int) (*t
 	return r0, r1
 }
 
+// HeaderByTag provides a mock function with given fields: ctx, tag
+func (_m *ethermanMock) HeaderByTag(ctx context.Context, tag string) (*types.Header, error) {
+	ret := _m.Called(ctx, tag)
+
+	var r0 *types.Header
+	if rf, ok := ret.Get(0).(func(context.Context, string) *types.Header); ok {
+		r0 = rf(ctx, tag)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Header)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tag)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTnewEthermanMock interface {
 	mock.TestingT
 	Cleanup(func())
@@ -5,6 +5,8 @@ package synchronizer
 import (
 	context "context"
 
+	common "github.com/ethereum/go-ethereum/common"
+
 	pgx "github.com/jackc/pgx/v4"
 	mock "github.com/stretchr/testify/mock"
 
@@ -72,6 +74,20 @@ func (_m *stateMock) AddGlobalExitRoot(ctx context.Context, exitRoot *state.Glob
 	return r0
 }
 
+// AddTrustedReorg provides a mock function with given fields: ctx, reorg, dbTx
+func (_m *stateMock) AddTrustedReorg(ctx context.Context, reorg *state.TrustedReorg, dbTx pgx.Tx) error {
+	ret := _m.Called(ctx, reorg, dbTx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *state.TrustedReorg, pgx.Tx) error); ok {
+		r0 = rf(ctx, reorg, dbTx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // AddVerifiedBatch provides a mock function with given fields: ctx, verifiedBatch, dbTx
 func (_m *stateMock) AddVerifiedBatch(ctx context.Context, verifiedBatch *state.VerifiedBatch, dbTx pgx.Tx) error {
 	ret := _m.Called(ctx, verifiedBatch, dbTx)
@@ -146,6 +162,61 @@ func (_m *stateMock) GetBatchByNumber(ctx context.Context, batchNumber uint64, d
 	return r0, r1
 }
 
+// GetForcedBatchByBatchNumber provides a mock function with given fields: ctx, batchNumber, dbTx
+func (_m *stateMock) GetForcedBatchByBatchNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (*state.ForcedBatch, error) {
+	ret := _m.Called(ctx, batchNumber, dbTx)
+
+	var r0 *state.ForcedBatch
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) *state.ForcedBatch); ok {
+		r0 = rf(ctx, batchNumber, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*state.ForcedBatch)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, pgx.Tx) error); ok {
+		r1 = rf(ctx, batchNumber, dbTx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetL2BlockNumbersAndTxHashesFromBatch provides a mock function with given fields: ctx, batchNumber, dbTx
+func (_m *stateMock) GetL2BlockNumbersAndTxHashesFromBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) ([]uint64, []common.Hash, error) {
+	ret := _m.Called(ctx, batchNumber, dbTx)
+
+	var r0 []uint64
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, pgx.Tx) []uint64); ok {
+		r0 = rf(ctx, batchNumber, dbTx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uint64)
+		}
+	}
+
+	var r1 []common.Hash
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, pgx.Tx) []common.Hash); ok {
+		r1 = rf(ctx, batchNumber, dbTx)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]common.Hash)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, uint64, pgx.Tx) error); ok {
+		r2 = rf(ctx, batchNumber, dbTx)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetLastBatchNumber provides a mock function with given fields: ctx, dbTx
 func (_m *stateMock) GetLastBatchNumber(ctx context.Context, dbTx pgx.Tx) (uint64, error) {
 	ret := _m.Called(ctx, dbTx)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/0xPolygonHermez/zkevm-node/etherman"
 	"github.com/0xPolygonHermez/zkevm-node/log"
+	"github.com/0xPolygonHermez/zkevm-node/metrics"
 	"github.com/0xPolygonHermez/zkevm-node/state"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/jackc/pgx/v4"
@@ -41,6 +42,11 @@ func NewSynchronizer(
 	genesis state.Genesis,
 	reorgBlockNumChan chan struct{},
 	cfg Config) (Synchronizer, error) {
+	switch cfg.L1SyncMode {
+	case "", LatestL1SyncMode, SafeL1SyncMode, FinalizedL1SyncMode:
+	default:
+		return nil, fmt.Errorf("invalid L1 sync mode: %s", cfg.L1SyncMode)
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &ClientSynchronizer{
@@ -57,6 +63,8 @@ func NewSynchronizer(
 
 var waitDuration = time.Duration(0)
 
+const trustedReorgsMetricName = "synchronizer/trusted_reorgs"
+
 // Sync function will read the last state synced and will continue from that point.
 // Sync() will read blockchain events to detect rollup updates
 func (s *ClientSynchronizer) Sync() error {
@@ -167,19 +175,29 @@ func (s *ClientSynchronizer) syncBlocks(lastEthBlockSynced *state.Block) (*state
 	}
 
 	// Call the blockchain to retrieve data
-	header, err := s.etherMan.HeaderByNumber(s.ctx, nil)
+	lastKnownBlock, err := s.getLastL1BlockToSync()
 	if err != nil {
 		return lastEthBlockSynced, err
 	}
-	lastKnownBlock := header.Number
 
 	var fromBlock uint64
 	if lastEthBlockSynced.BlockNumber > 0 {
 		fromBlock = lastEthBlockSynced.BlockNumber + 1
 	}
 
+	finalityAware := s.isFinalityAware()
+	if finalityAware && lastKnownBlock.Cmp(new(big.Int).SetUint64(fromBlock)) < 0 {
+		// Nothing new has reached the configured finality yet
+		waitDuration = s.cfg.SyncInterval.Duration
+		return lastEthBlockSynced, nil
+	}
+
 	for {
 		toBlock := fromBlock + s.cfg.SyncChunkSize
+		if finalityAware && lastKnownBlock.Cmp(new(big.Int).SetUint64(toBlock)) < 0 {
+			// Blocks above the configured finality must not be consolidated
+			toBlock = lastKnownBlock.Uint64()
+		}
 
 		log.Infof("Getting rollup info from block %d to block %d", fromBlock, toBlock)
 		// This function returns the rollup information contained in the ethereum blocks and an extra param called order.
@@ -236,6 +254,39 @@ func (s *ClientSynchronizer) syncBlocks(lastEthBlockSynced *state.Block) (*state
 	return lastEthBlockSynced, nil
 }
 
+// isFinalityAware returns true when the synchronizer must not consolidate
+// rollup information from L1 blocks above the configured finality.
+func (s *ClientSynchronizer) isFinalityAware() bool {
+	return (s.cfg.L1SyncMode != "" && s.cfg.L1SyncMode != LatestL1SyncMode) || s.cfg.L1BlockConfirmations > 0
+}
+
+// getLastL1BlockToSync returns the number of the last L1 block that can be synced
+// according to the L1 sync mode and the number of confirmations configured.
+func (s *ClientSynchronizer) getLastL1BlockToSync() (*big.Int, error) {
+	switch s.cfg.L1SyncMode {
+	case SafeL1SyncMode, FinalizedL1SyncMode:
+		header, err := s.etherMan.HeaderByTag(s.ctx, s.cfg.L1SyncMode)
+		if err != nil {
+			return nil, err
+		}
+		return header.Number, nil
+	}
+
+	header, err := s.etherMan.HeaderByNumber(s.ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	lastKnownBlock := new(big.Int).Set(header.Number)
+	if s.cfg.L1BlockConfirmations > 0 {
+		confirmations := new(big.Int).SetUint64(s.cfg.L1BlockConfirmations)
+		if lastKnownBlock.Cmp(confirmations) < 0 {
+			return big.NewInt(0), nil
+		}
+		lastKnownBlock.Sub(lastKnownBlock, confirmations)
+	}
+	return lastKnownBlock, nil
+}
+
 func (s *ClientSynchronizer) processBlockRange(blocks []etherman.Block, order map[common.Hash][]etherman.Order) {
 	// New info has to be included into the db using the state
 	for i := range blocks {
@@ -389,20 +440,63 @@ func (s *ClientSynchronizer) Stop() {
 	s.cancelCtx()
 }
 
-func (s *ClientSynchronizer) checkTrustedState(batch state.Batch, dbTx pgx.Tx) (bool, error) {
+func (s *ClientSynchronizer) checkTrustedState(batch state.Batch, dbTx pgx.Tx) (*state.Batch, []string, error) {
 	// First get trusted batch from db
 	tBatch, err := s.state.GetBatchByNumber(s.ctx, batch.BatchNumber, dbTx)
 	if err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	//Compare virtual state with trusted state
-	if hex.EncodeToString(batch.BatchL2Data) == hex.EncodeToString(tBatch.BatchL2Data) &&
-		batch.GlobalExitRoot.String() == tBatch.GlobalExitRoot.String() &&
-		batch.Timestamp.Unix() == tBatch.Timestamp.Unix() &&
-		batch.Coinbase.String() == tBatch.Coinbase.String() {
-		return true, nil
+	var divergentFields []string
+	if hex.EncodeToString(batch.BatchL2Data) != hex.EncodeToString(tBatch.BatchL2Data) {
+		divergentFields = append(divergentFields, state.TrustedReorgFieldBatchL2Data)
+	}
+	if batch.GlobalExitRoot.String() != tBatch.GlobalExitRoot.String() {
+		divergentFields = append(divergentFields, state.TrustedReorgFieldGlobalExitRoot)
+	}
+	if batch.Timestamp.Unix() != tBatch.Timestamp.Unix() {
+		divergentFields = append(divergentFields, state.TrustedReorgFieldTimestamp)
+	}
+	if batch.Coinbase.String() != tBatch.Coinbase.String() {
+		divergentFields = append(divergentFields, state.TrustedReorgFieldCoinbase)
+	}
+	return tBatch, divergentFields, nil
+}
+
+// buildTrustedReorg gathers the trusted L2 blocks and transactions that are
+// going to be discarded because of a divergence with the virtual state
+func (s *ClientSynchronizer) buildTrustedReorg(batchNumber uint64, tBatch *state.Batch, divergentFields []string, blockNumber uint64, dbTx pgx.Tx) (*state.TrustedReorg, error) {
+	l2BlockNumbers, txHashes, err := s.state.GetL2BlockNumbersAndTxHashesFromBatch(s.ctx, batchNumber, dbTx)
+	if err != nil {
+		return nil, err
 	}
-	return false, nil
+	return &state.TrustedReorg{
+		BatchNumber:     batchNumber,
+		BlockNumber:     blockNumber,
+		DivergentFields: divergentFields,
+		OldStateRoot:    tBatch.StateRoot,
+		L2BlockNumbers:  l2BlockNumbers,
+		TxHashes:        txHashes,
+	}, nil
+}
+
+// storeTrustedReorg completes the divergence report with the state root
+// resulting from processing the virtual batch and stores it
+func (s *ClientSynchronizer) storeTrustedReorg(trustedReorg *state.TrustedReorg, dbTx pgx.Tx) error {
+	vBatch, err := s.state.GetBatchByNumber(s.ctx, trustedReorg.BatchNumber, dbTx)
+	if err != nil {
+		return err
+	}
+	trustedReorg.NewStateRoot = vBatch.StateRoot
+	trustedReorg.ReportedAt = time.Now()
+	if err := s.state.AddTrustedReorg(s.ctx, trustedReorg, dbTx); err != nil {
+		return err
+	}
+	metrics.GetOrRegisterCounter(trustedReorgsMetricName).Inc(1)
+	for _, field := range trustedReorg.DivergentFields {
+		metrics.GetOrRegisterCounter(trustedReorgsMetricName + "/" + field).Inc(1)
+	}
+	return nil
 }
 
 func (s *ClientSynchronizer) processSequenceBatches(sequencedBatches []etherman.SequencedBatch, blockNumber uint64, dbTx pgx.Tx) {
@@ -424,9 +518,11 @@ func (s *ClientSynchronizer) processSequenceBatches(sequencedBatches []etherman.
 		batches := []state.Batch{b}
 		// ForcedBatches must be processed after the batch.
 		numForcedBatches := len(sbatch.ForceBatchesTimestamp)
+		var forcedBatches []state.ForcedBatch
 		if numForcedBatches > 0 {
 			// Read forcedBatches from db
-			forcedBatches, err := s.state.GetNextForcedBatches(s.ctx, numForcedBatches, dbTx)
+			var err error
+			forcedBatches, err = s.getForcedBatchesOfSequence(sbatch.BatchNumber, numForcedBatches, dbTx)
 			if err != nil {
 				log.Errorf("error getting forcedBatches. BatchNumber: %d", vb.BatchNumber)
 				rollbackErr := dbTx.Rollback(s.ctx)
@@ -444,30 +540,20 @@ func (s *ClientSynchronizer) processSequenceBatches(sequencedBatches []etherman.
 			}
 			for i, forcedBatch := range forcedBatches {
 				vb := state.VirtualBatch{
-					BatchNumber: sbatch.BatchNumber + uint64(i),
+					BatchNumber: sbatch.BatchNumber + uint64(i) + 1,
 					TxHash:      sbatch.TxHash,
 					Coinbase:    sbatch.Coinbase,
 					BlockNumber: blockNumber,
 				}
 				virtualBatches = append(virtualBatches, vb)
 				tb := state.Batch{
-					BatchNumber:    sbatch.BatchNumber + uint64(i), // First process the batch and then the forcedBatches
+					BatchNumber:    sbatch.BatchNumber + uint64(i) + 1, // First process the batch and then the forcedBatches
 					GlobalExitRoot: forcedBatch.GlobalExitRoot,
 					Timestamp:      time.Unix(int64(sbatch.ForceBatchesTimestamp[i]), 0), // ForceBatchesTimestamp instead of forcedAt because it is the timestamp selected by the sequencer, not when the forced batch was sent. This forcedAt is the min timestamp allowed.
 					Coinbase:       forcedBatch.Sequencer,
 					BatchL2Data:    forcedBatch.RawTxsData,
 				}
 				batches = append(batches, tb)
-				// Store batchNumber in forced_batch table
-				err = s.state.AddBatchNumberInForcedBatch(s.ctx, forcedBatch.ForcedBatchNumber, tb.BatchNumber, dbTx)
-				if err != nil {
-					log.Errorf("error adding the batchNumber to forcedBatch in processSequenceBatches. BlockNumber: %d", blockNumber)
-					rollbackErr := dbTx.Rollback(s.ctx)
-					if rollbackErr != nil {
-						log.Fatalf("error rolling back state. BlockNumber: %d, rollbackErr: %s, error : %s", blockNumber, rollbackErr.Error(), err.Error())
-					}
-					log.Fatalf("error adding the batchNumber to forcedBatch in processSequenceBatches. BlockNumber: %d, error: %s", blockNumber, err.Error())
-				}
 			}
 		}
 
@@ -488,7 +574,7 @@ func (s *ClientSynchronizer) processSequenceBatches(sequencedBatches []etherman.
 				GlobalExitRoot: batch.GlobalExitRoot,
 			}
 			// Call the check trusted state method to compare trusted and virtual state
-			status, err := s.checkTrustedState(batch, dbTx)
+			tBatch, divergentFields, err := s.checkTrustedState(batch, dbTx)
 			if err != nil {
 				if errors.Is(err, state.ErrNotFound) {
 					log.Debugf("BatchNumber: %d, not found in trusted state. Storing it...", batch.BatchNumber)
@@ -502,7 +588,6 @@ func (s *ClientSynchronizer) processSequenceBatches(sequencedBatches []etherman.
 						}
 						log.Fatalf("error storing batch. BatchNumber: %d, BlockNumber: %d, error: %s", batch.BatchNumber, blockNumber, err.Error())
 					}
-					status = true
 				} else {
 					rollbackErr := dbTx.Rollback(s.ctx)
 					if rollbackErr != nil {
@@ -511,10 +596,20 @@ func (s *ClientSynchronizer) processSequenceBatches(sequencedBatches []etherman.
 					log.Fatal("error checking trusted state: ", err)
 				}
 			}
-			if !status {
+			if len(divergentFields) > 0 {
+				log.Warnf("trusted state divergence detected. BatchNumber: %d, BlockNumber: %d, fields: %v", batch.BatchNumber, blockNumber, divergentFields)
+				trustedReorg, err := s.buildTrustedReorg(batch.BatchNumber, tBatch, divergentFields, blockNumber, dbTx)
+				if err != nil {
+					log.Errorf("error building trusted reorg report. BatchNumber: %d, BlockNumber: %d, error: %s", batch.BatchNumber, blockNumber, err.Error())
+					rollbackErr := dbTx.Rollback(s.ctx)
+					if rollbackErr != nil {
+						log.Fatalf("error rolling back state. BatchNumber: %d, BlockNumber: %d, rollbackErr: %s, error : %s", batch.BatchNumber, blockNumber, rollbackErr.Error(), err.Error())
+					}
+					log.Fatalf("error building trusted reorg report. BatchNumber: %d, BlockNumber: %d, error: %s", batch.BatchNumber, blockNumber, err.Error())
+				}
 				// Reset trusted state
 				log.Infof("reorg detected, discarding batches until batchNum %d", batch.BatchNumber)
-				err := s.state.ResetTrustedState(s.ctx, batch.BatchNumber, dbTx) // This method has to reset the forced batches deleting the batchNumber for higher batchNumbers
+				err = s.state.ResetTrustedState(s.ctx, batch.BatchNumber, dbTx) // This method has to reset the forced batches deleting the batchNumber for higher batchNumbers
 				if err != nil {
 					log.Errorf("error resetting trusted state. BatchNumber: %d, BlockNumber: %d, error: %s", batch.BatchNumber, blockNumber, err.Error())
 					rollbackErr := dbTx.Rollback(s.ctx)
@@ -532,6 +627,15 @@ func (s *ClientSynchronizer) processSequenceBatches(sequencedBatches []etherman.
 					}
 					log.Fatalf("error storing batch. BatchNumber: %d, BlockNumber: %d, error: %s", batch.BatchNumber, blockNumber, err.Error())
 				}
+				err = s.storeTrustedReorg(trustedReorg, dbTx)
+				if err != nil {
+					log.Errorf("error storing trusted reorg report. BatchNumber: %d, BlockNumber: %d, error: %s", batch.BatchNumber, blockNumber, err.Error())
+					rollbackErr := dbTx.Rollback(s.ctx)
+					if rollbackErr != nil {
+						log.Fatalf("error rolling back state. BatchNumber: %d, BlockNumber: %d, rollbackErr: %s, error : %s", batch.BatchNumber, blockNumber, rollbackErr.Error(), err.Error())
+					}
+					log.Fatalf("error storing trusted reorg report. BatchNumber: %d, BlockNumber: %d, error: %s", batch.BatchNumber, blockNumber, err.Error())
+				}
 			}
 			// Store virtualBatch
 			err = s.state.AddVirtualBatch(s.ctx, &virtualBatches[i], dbTx)
@@ -543,8 +647,44 @@ func (s *ClientSynchronizer) processSequenceBatches(sequencedBatches []etherman.
 				}
 				log.Fatalf("error storing virtualBatch. BatchNumber: %d, BlockNumber: %d, error: %s", virtualBatches[i].BatchNumber, blockNumber, err.Error())
 			}
+			// Store batchNumber in forced_batch table. It is done once the batch is stored because a trusted
+			// state reset releases the forced batches of the discarded batches
+			if i > 0 {
+				err = s.state.AddBatchNumberInForcedBatch(s.ctx, forcedBatches[i-1].ForcedBatchNumber, batch.BatchNumber, dbTx)
+				if err != nil {
+					log.Errorf("error adding the batchNumber to forcedBatch in processSequenceBatches. BlockNumber: %d", blockNumber)
+					rollbackErr := dbTx.Rollback(s.ctx)
+					if rollbackErr != nil {
+						log.Fatalf("error rolling back state. BlockNumber: %d, rollbackErr: %s, error : %s", blockNumber, rollbackErr.Error(), err.Error())
+					}
+					log.Fatalf("error adding the batchNumber to forcedBatch in processSequenceBatches. BlockNumber: %d, error: %s", blockNumber, err.Error())
+				}
+			}
+		}
+	}
+}
+
+// getForcedBatchesOfSequence returns the forced batches sequenced after the given batch. The forced batches
+// already included by the trusted sequencer have the batch number assigned, the rest are the next ones in the queue
+func (s *ClientSynchronizer) getForcedBatchesOfSequence(batchNumber uint64, numForcedBatches int, dbTx pgx.Tx) ([]state.ForcedBatch, error) {
+	forcedBatches := make([]state.ForcedBatch, 0, numForcedBatches)
+	for i := 0; i < numForcedBatches; i++ {
+		forcedBatch, err := s.state.GetForcedBatchByBatchNumber(s.ctx, batchNumber+uint64(i)+1, dbTx)
+		if errors.Is(err, state.ErrNotFound) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		forcedBatches = append(forcedBatches, *forcedBatch)
+	}
+	if len(forcedBatches) < numForcedBatches {
+		nextForcedBatches, err := s.state.GetNextForcedBatches(s.ctx, numForcedBatches-len(forcedBatches), dbTx)
+		if err != nil {
+			return nil, err
 		}
+		forcedBatches = append(forcedBatches, nextForcedBatches...)
 	}
+	return forcedBatches, nil
 }
 
 func (s *ClientSynchronizer) processSequenceForceBatch(sequenceForceBatch etherman.SequencedForceBatch, blockNumber uint64, dbTx pgx.Tx) {
@@ -3,6 +3,7 @@ package synchronizer
 import (
 	context "context"
 	"math/big"
+	"reflect"
 	"testing"
 	"time"
 
@@ -24,8 +25,9 @@ type mocks struct {
 
 func TestTrustedStateReorg(t *testing.T) {
 	type testCase struct {
-		Name            string
-		getTrustedBatch func(*mocks, context.Context, etherman.SequencedBatch) *state.Batch
+		Name                    string
+		getTrustedBatch         func(*mocks, context.Context, etherman.SequencedBatch) *state.Batch
+		ExpectedDivergentFields []string
 	}
 
 	setupMocks := func(m *mocks, tc *testCase) Synchronizer {
@@ -129,6 +131,13 @@ func TestTrustedStateReorg(t *testing.T) {
 					Return(trustedBatch, nil).
 					Once()
 
+				l2BlockNumbers := []uint64{1, 2}
+				txHashes := []common.Hash{common.HexToHash("0x444"), common.HexToHash("0x555")}
+				m.State.
+					On("GetL2BlockNumbersAndTxHashesFromBatch", ctx, sequencedBatch.BatchNumber, m.DbTx).
+					Return(l2BlockNumbers, txHashes, nil).
+					Once()
+
 				m.State.
 					On("ResetTrustedState", ctx, sequencedBatch.BatchNumber, m.DbTx).
 					Return(nil).
@@ -146,6 +155,25 @@ func TestTrustedStateReorg(t *testing.T) {
 					Return(nil).
 					Once()
 
+				virtualStateRoot := common.HexToHash("0x777")
+				m.State.
+					On("GetBatchByNumber", ctx, sequencedBatch.BatchNumber, m.DbTx).
+					Return(&state.Batch{BatchNumber: sequencedBatch.BatchNumber, StateRoot: virtualStateRoot}, nil).
+					Once()
+
+				m.State.
+					On("AddTrustedReorg", ctx, mock.MatchedBy(func(reorg *state.TrustedReorg) bool {
+						return reorg.BatchNumber == sequencedBatch.BatchNumber &&
+							reorg.BlockNumber == ethermanBlock.BlockNumber &&
+							reflect.DeepEqual(reorg.DivergentFields, tc.ExpectedDivergentFields) &&
+							reorg.OldStateRoot == trustedBatch.StateRoot &&
+							reorg.NewStateRoot == virtualStateRoot &&
+							reflect.DeepEqual(reorg.L2BlockNumbers, l2BlockNumbers) &&
+							reflect.DeepEqual(reorg.TxHashes, txHashes)
+					}), m.DbTx).
+					Return(nil).
+					Once()
+
 				virtualBatch := &state.VirtualBatch{
 					BatchNumber: sequencedBatch.BatchNumber,
 					TxHash:      sequencedBatch.TxHash,
@@ -172,7 +200,8 @@ func TestTrustedStateReorg(t *testing.T) {
 
 	testCases := []testCase{
 		{
-			Name: "Transactions are different",
+			Name:                    "Transactions are different",
+			ExpectedDivergentFields: []string{state.TrustedReorgFieldBatchL2Data},
 			getTrustedBatch: func(m *mocks, ctx context.Context, sequencedBatch etherman.SequencedBatch) *state.Batch {
 				return &state.Batch{
 					BatchL2Data:    []byte{1},
@@ -183,7 +212,8 @@ func TestTrustedStateReorg(t *testing.T) {
 			},
 		},
 		{
-			Name: "Global Exit Root is different",
+			Name:                    "Global Exit Root is different",
+			ExpectedDivergentFields: []string{state.TrustedReorgFieldGlobalExitRoot},
 			getTrustedBatch: func(m *mocks, ctx context.Context, sequencedBatch etherman.SequencedBatch) *state.Batch {
 				return &state.Batch{
 					BatchL2Data:    sequencedBatch.Transactions,
@@ -194,7 +224,8 @@ func TestTrustedStateReorg(t *testing.T) {
 			},
 		},
 		{
-			Name: "Timestamp is different",
+			Name:                    "Timestamp is different",
+			ExpectedDivergentFields: []string{state.TrustedReorgFieldTimestamp},
 			getTrustedBatch: func(m *mocks, ctx context.Context, sequencedBatch etherman.SequencedBatch) *state.Batch {
 				return &state.Batch{
 					BatchL2Data:    sequencedBatch.Transactions,
@@ -205,7 +236,8 @@ func TestTrustedStateReorg(t *testing.T) {
 			},
 		},
 		{
-			Name: "Coinbase is different",
+			Name:                    "Coinbase is different",
+			ExpectedDivergentFields: []string{state.TrustedReorgFieldCoinbase},
 			getTrustedBatch: func(m *mocks, ctx context.Context, sequencedBatch etherman.SequencedBatch) *state.Batch {
 				return &state.Batch{
 					BatchL2Data:    sequencedBatch.Transactions,
@@ -239,3 +271,72 @@ func TestTrustedStateReorg(t *testing.T) {
 	// send a forced batch to l1
 	// try virtualize the trusted state
 }
+
+func TestGetLastL1BlockToSync(t *testing.T) {
+	type testCase struct {
+		Name           string
+		Cfg            Config
+		Setup          func(m *mocks)
+		ExpectedNumber uint64
+	}
+
+	var n *big.Int
+	testCases := []testCase{
+		{
+			Name: "Latest",
+			Cfg:  Config{L1SyncMode: LatestL1SyncMode},
+			Setup: func(m *mocks) {
+				m.Etherman.On("HeaderByNumber", mock.Anything, n).Return(&types.Header{Number: big.NewInt(100)}, nil).Once()
+			},
+			ExpectedNumber: 100,
+		},
+		{
+			Name: "Latest with confirmations",
+			Cfg:  Config{L1SyncMode: LatestL1SyncMode, L1BlockConfirmations: 10},
+			Setup: func(m *mocks) {
+				m.Etherman.On("HeaderByNumber", mock.Anything, n).Return(&types.Header{Number: big.NewInt(100)}, nil).Once()
+			},
+			ExpectedNumber: 90,
+		},
+		{
+			Name: "Latest with more confirmations than blocks",
+			Cfg:  Config{L1SyncMode: LatestL1SyncMode, L1BlockConfirmations: 200},
+			Setup: func(m *mocks) {
+				m.Etherman.On("HeaderByNumber", mock.Anything, n).Return(&types.Header{Number: big.NewInt(100)}, nil).Once()
+			},
+			ExpectedNumber: 0,
+		},
+		{
+			Name: "Safe",
+			Cfg:  Config{L1SyncMode: SafeL1SyncMode},
+			Setup: func(m *mocks) {
+				m.Etherman.On("HeaderByTag", mock.Anything, SafeL1SyncMode).Return(&types.Header{Number: big.NewInt(80)}, nil).Once()
+			},
+			ExpectedNumber: 80,
+		},
+		{
+			Name: "Finalized",
+			Cfg:  Config{L1SyncMode: FinalizedL1SyncMode},
+			Setup: func(m *mocks) {
+				m.Etherman.On("HeaderByTag", mock.Anything, FinalizedL1SyncMode).Return(&types.Header{Number: big.NewInt(64)}, nil).Once()
+			},
+			ExpectedNumber: 64,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			m := mocks{Etherman: newEthermanMock(t)}
+			tc.Setup(&m)
+			s, err := NewSynchronizer(m.Etherman, nil, 0, state.Genesis{}, nil, tc.Cfg)
+			require.NoError(t, err)
+
+			lastBlock, err := s.(*ClientSynchronizer).getLastL1BlockToSync()
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedNumber, lastBlock.Uint64())
+		})
+	}
+
+	_, err := NewSynchronizer(nil, nil, 0, state.Genesis{}, nil, Config{L1SyncMode: "unknown"})
+	require.Error(t, err)
+}
@@ -26,7 +26,9 @@ const (
 	l1NetworkURL = "http://localhost:8545"
 	l2NetworkURL = "http://localhost:8123"
 
-	defaultInterval = 10 * time.Second
+	// defaultInterval is short so the time waiting for the pool to be emptied
+	// doesn't hide the throughput of the sequencer
+	defaultInterval = 100 * time.Millisecond
 	defaultDeadline = 6000 * time.Second
 
 	gasLimit = 21000
@@ -83,9 +85,17 @@ func BenchmarkSequencer(b *testing.B) {
 	for _, v := range table {
 		st, pl, gasPrice, l2Client := setUpEnv(b)
 		b.Run(fmt.Sprintf("amount_of_txs_%d", v.input), func(b *testing.B) {
+			nonce, err := l2Client.PendingNonceAt(ctx, auth.From)
+			require.NoError(b, err)
+			var elapsed time.Duration
+			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				runTxSender(b, l2Client, pl, gasPrice, v.input)
+				elapsed += runTxSender(b, l2Client, pl, gasPrice, nonce, v.input)
+				nonce += uint64(v.input)
 			}
+			// throughput of the sequencer, from sending the first tx until
+			// the pool has no pending txs
+			b.ReportMetric(float64(v.input*b.N)/elapsed.Seconds(), "txs/s")
 		})
 		tearDownEnv(b, st)
 	}
@@ -98,7 +108,7 @@ func setUpEnv(b *testing.B) (*state.State, *pool.Pool, *big.Int, *ethclient.Clie
 	st := opsman.State()
 	s, err := pgpoolstorage.NewPostgresPoolStorage(dbConfig)
 	require.NoError(b, err)
-	pl := pool.NewPool(s, st, common.Address{})
+	pl := pool.NewPool(s, st, common.Address{}, operations.MaxZkCounters, nil, false)
 	// store current batch number to check later when the state is updated
 	require.NoError(b, opsman.SetGenesis(genesisAccounts))
 	require.NoError(b, opsman.Setup())
@@ -123,10 +133,13 @@ func tearDownEnv(b *testing.B, st stateInterface) {
 	require.NoError(b, operations.Teardown())
 }
 
-func runTxSender(b *testing.B, l2Client *ethclient.Client, pl *pool.Pool, gasPrice *big.Int, txsAmount int) {
+// runTxSender sends txsAmount txs starting from the given nonce and waits for
+// the sequencer to select them, returning the time it took
+func runTxSender(b *testing.B, l2Client *ethclient.Client, pl *pool.Pool, gasPrice *big.Int, nonce uint64, txsAmount int) time.Duration {
 	var err error
+	start := time.Now()
 	for i := 0; i < txsAmount; i++ {
-		tx := types.NewTransaction(uint64(i), common.HexToAddress(genAccAddr2), ethAmount, gasLimit, gasPrice, nil)
+		tx := types.NewTransaction(nonce+uint64(i), common.HexToAddress(genAccAddr2), ethAmount, gasLimit, gasPrice, nil)
 		signedTx, err := auth.Signer(auth.From, tx)
 		require.NoError(b, err)
 		err = l2Client.SendTransaction(ctx, signedTx)
@@ -142,9 +155,9 @@ func runTxSender(b *testing.B, l2Client *ethclient.Client, pl *pool.Pool, gasPri
 			return false, err
 		}
 
-		fmt.Printf("amount of pending txs: %v\n", count)
 		done := count == 0
 		return done, nil
 	})
 	require.NoError(b, err)
+	return time.Since(start)
 }
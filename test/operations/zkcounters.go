@@ -0,0 +1,19 @@
+package operations
+
+import (
+	"math"
+
+	"github.com/0xPolygonHermez/zkevm-node/pool"
+)
+
+// MaxZkCounters are big enough for any tx of the tests to fit in a batch
+var MaxZkCounters = pool.ZkCounters{
+	CumulativeGasUsed:    math.MaxInt64,
+	UsedKeccakHashes:     math.MaxInt32,
+	UsedPoseidonHashes:   math.MaxInt32,
+	UsedPoseidonPaddings: math.MaxInt32,
+	UsedMemAligns:        math.MaxInt32,
+	UsedArithmetics:      math.MaxInt32,
+	UsedBinaries:         math.MaxInt32,
+	UsedSteps:            math.MaxInt32,
+}